@@ -74,12 +74,19 @@ func WithHistoryModifier(f func(context.Context, []Message) []Message) AgentRunO
 }
 
 type ToolsConfig struct {
+	// ToolsNodeConfig is the config of the ToolsNode.
+	// The tools of ToolsNodeConfig.Toolkits are listed on each run, and bound to the model along with ToolsNodeConfig.Tools.
 	compose.ToolsNodeConfig
 
 	// ReturnDirectly specifies tools that cause the agent to return immediately when called.
 	// If multiple listed tools are called simultaneously, only the first one triggers the return.
 	// The map keys are tool names indicate whether the tool should trigger immediate return.
 	ReturnDirectly map[string]bool
+
+	// ToolSelector selects the tools relevant to the conversation from its catalog on each turn, optional.
+	// The selected tools are bound to the model in addition to the tools of ToolsNodeConfig, which are always bound,
+	// and the tools of the catalog are added to the ToolsNode.
+	ToolSelector tool.ToolSelector
 }
 
 // GenModelInput transforms agent instructions and input into a format suitable for the model.
@@ -369,6 +376,10 @@ func (h *cbHandler) onToolEnd(ctx context.Context,
 	toolCallID := compose.GetToolCallID(ctx)
 	msg := schema.ToolMessage(output.Response, toolCallID, schema.WithToolName(runInfo.Name))
 	event := EventFromMessage(msg, nil, schema.Tool, runInfo.Name)
+	if output.Result != nil {
+		msg.UserInputMultiContent = output.Result.Parts
+		event.Output.MessageOutput.ToolArtifact = output.Result.Artifact
+	}
 
 	action := popToolGenAction(ctx, runInfo.Name)
 	event.Action = action
@@ -500,7 +511,7 @@ func (a *ChatModelAgent) buildRunFunc(ctx context.Context) runFunc {
 			returnDirectly[exitInfo.Name] = true
 		}
 
-		if len(toolsNodeConf.Tools) == 0 {
+		if len(toolsNodeConf.Tools) == 0 && len(toolsNodeConf.Toolkits) == 0 && a.toolsConfig.ToolSelector == nil {
 			a.run = func(ctx context.Context, input *AgentInput, generator *AsyncGenerator[*AgentEvent], store *mockStore, opts ...compose.Option) {
 				var err error
 				var msgs []Message
@@ -554,6 +565,7 @@ func (a *ChatModelAgent) buildRunFunc(ctx context.Context) runFunc {
 		conf := &reactConfig{
 			model:               a.model,
 			toolsConfig:         &toolsNodeConf,
+			toolSelector:        a.toolsConfig.ToolSelector,
 			toolsReturnDirectly: returnDirectly,
 			agentName:           a.name,
 			maxIterations:       a.maxIterations,
@@ -587,6 +599,16 @@ func (a *ChatModelAgent) buildRunFunc(ctx context.Context) runFunc {
 				return
 			}
 
+			ctx = compose.WithToolCallerInfo(ctx, toolCallerInfo(ctx, a.name))
+
+			// the toolkits are listed on each run, before the options of the caller to let them take precedence
+			toolkitOpts, err_ := toolkitOptions(ctx, &toolsNodeConf, a.toolsConfig.ToolSelector)
+			if err_ != nil {
+				generator.Send(&AgentEvent{Err: err_})
+				return
+			}
+			opts = append(toolkitOpts, opts...)
+
 			callOpt := genReactCallbacks(a.name, generator, input.EnableStreaming, store)
 
 			var msg Message
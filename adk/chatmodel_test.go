@@ -24,8 +24,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 
+	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/tool/selector"
+	mockEmbedding "github.com/cloudwego/eino/internal/mock/components/embedding"
 	mockModel "github.com/cloudwego/eino/internal/mock/components/model"
 	"github.com/cloudwego/eino/schema"
 )
@@ -264,6 +267,313 @@ func TestChatModelAgentRun(t *testing.T) {
 		_, ok = iterator.Next()
 		assert.False(t, ok)
 	})
+
+	t.Run("WithRichTool", func(t *testing.T) {
+		ctx := context.Background()
+
+		chartURL := "https://example.com/chart.png"
+		parts := []schema.MessageInputPart{
+			{Type: schema.ChatMessagePartTypeImageURL, Image: &schema.MessageInputImage{MessagePartCommon: schema.MessagePartCommon{URL: &chartURL}}},
+		}
+		chart := &richToolForTest{result: &tool.Result{Content: "chart generated", Parts: parts, Artifact: []int{1, 2, 3}}}
+
+		ctrl := gomock.NewController(t)
+		cm := mockModel.NewMockToolCallingChatModel(ctrl)
+		cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(schema.AssistantMessage("", []schema.ToolCall{
+				{ID: "tool-call-1", Function: schema.FunctionCall{Name: "chart", Arguments: `{}`}},
+			}), nil).
+			Times(1)
+		cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, input []Message, opts ...model.Option) (Message, error) {
+				toolMsg := input[len(input)-1]
+				assert.Equal(t, "chart generated", toolMsg.Content)
+				assert.Equal(t, parts, toolMsg.UserInputMultiContent)
+				return schema.AssistantMessage("done", nil), nil
+			}).
+			Times(1)
+		cm.EXPECT().WithTools(gomock.Any()).Return(cm, nil).AnyTimes()
+
+		agent, err := NewChatModelAgent(ctx, &ChatModelAgentConfig{
+			Name:        "TestAgent",
+			Description: "Test agent for unit testing",
+			Model:       cm,
+			ToolsConfig: ToolsConfig{
+				ToolsNodeConfig: compose.ToolsNodeConfig{
+					Tools: []tool.BaseTool{chart},
+				},
+			},
+		})
+		assert.NoError(t, err)
+
+		iterator := agent.Run(ctx, &AgentInput{Messages: []Message{schema.UserMessage("draw a chart")}})
+		_, ok := iterator.Next()
+		assert.True(t, ok)
+
+		event, ok := iterator.Next()
+		assert.True(t, ok)
+		assert.Nil(t, event.Err)
+		assert.Equal(t, schema.Tool, event.Output.MessageOutput.Role)
+		assert.Equal(t, []int{1, 2, 3}, event.Output.MessageOutput.ToolArtifact)
+		assert.Equal(t, "chart generated", event.Output.MessageOutput.Message.Content)
+		assert.Equal(t, parts, event.Output.MessageOutput.Message.UserInputMultiContent)
+
+		event, ok = iterator.Next()
+		assert.True(t, ok)
+		assert.Nil(t, event.Err)
+		assert.Equal(t, "done", event.Output.MessageOutput.Message.Content)
+
+		_, ok = iterator.Next()
+		assert.False(t, ok)
+	})
+}
+
+func TestChatModelAgentWithToolSelector(t *testing.T) {
+	ctx := context.Background()
+
+	fakeTool := &fakeToolForTest{tarCount: 1}
+	info, err := fakeTool.Info(ctx)
+	assert.NoError(t, err)
+	chart := &richToolForTest{result: &tool.Result{Content: "chart generated"}}
+
+	sel, err := selector.NewSelector(ctx, &selector.Config{
+		Embedder:         &mockEmbedding.KeywordEmbedder{Keywords: []string{"chart", info.Name}},
+		Tools:            []tool.BaseTool{fakeTool, chart},
+		TopK:             1,
+		MinScore:         0.5,
+		EnableSearchTool: true,
+	})
+	assert.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockToolCallingChatModel(ctrl)
+	var bound [][]string
+	cm.EXPECT().WithTools(gomock.Any()).DoAndReturn(func(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+		var names []string
+		for _, info := range tools {
+			names = append(names, info.Name)
+		}
+		bound = append(bound, names)
+		return cm, nil
+	}).AnyTimes()
+	cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(schema.AssistantMessage("", []schema.ToolCall{
+			{ID: "tool-call-1", Function: schema.FunctionCall{Name: "chart", Arguments: `{}`}},
+		}), nil).
+		Times(1)
+	cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(schema.AssistantMessage("done", nil), nil).
+		Times(1)
+
+	agent, err := NewChatModelAgent(ctx, &ChatModelAgentConfig{
+		Name:        "TestAgent",
+		Description: "Test agent for unit testing",
+		Model:       cm,
+		ToolsConfig: ToolsConfig{ToolSelector: sel},
+	})
+	assert.NoError(t, err)
+
+	iterator := agent.Run(ctx, &AgentInput{Messages: []Message{schema.UserMessage("draw a chart")}})
+	var events []*AgentEvent
+	for {
+		event, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		assert.Nil(t, event.Err)
+		events = append(events, event)
+	}
+	assert.Len(t, events, 3)
+	assert.Equal(t, "chart generated", events[1].Output.MessageOutput.Message.Content)
+	assert.Equal(t, [][]string{{"chart", selector.SearchToolName}, {"chart", selector.SearchToolName}}, bound)
+}
+
+func TestChatModelAgentWithToolkits(t *testing.T) {
+	ctx := context.Background()
+
+	chart := &richToolForTest{result: &tool.Result{Content: "chart generated"}}
+	var listed int
+	toolkit := tool.ToolkitFunc(func(ctx context.Context) ([]tool.BaseTool, error) {
+		listed++
+		return []tool.BaseTool{chart}, nil
+	})
+
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockToolCallingChatModel(ctrl)
+	cm.EXPECT().WithTools(gomock.Any()).Return(cm, nil).AnyTimes()
+	var bound [][]string
+	cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+			var names []string
+			for _, info := range model.GetCommonOptions(nil, opts...).Tools {
+				names = append(names, info.Name)
+			}
+			bound = append(bound, names)
+			if input[len(input)-1].Role == schema.User {
+				return schema.AssistantMessage("", []schema.ToolCall{
+					{ID: "tool-call-1", Function: schema.FunctionCall{Name: "lab_chart", Arguments: `{}`}},
+				}), nil
+			}
+			return schema.AssistantMessage("done", nil), nil
+		}).Times(2)
+
+	agent, err := NewChatModelAgent(ctx, &ChatModelAgentConfig{
+		Name:        "TestAgent",
+		Description: "Test agent for unit testing",
+		Model:       cm,
+		ToolsConfig: ToolsConfig{ToolsNodeConfig: compose.ToolsNodeConfig{
+			Toolkits: []tool.Toolkit{tool.PrefixToolkit(toolkit, "lab_")},
+		}},
+	})
+	assert.NoError(t, err)
+
+	iterator := agent.Run(ctx, &AgentInput{Messages: []Message{schema.UserMessage("draw a chart")}})
+	var events []*AgentEvent
+	for {
+		event, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		assert.Nil(t, event.Err)
+		events = append(events, event)
+	}
+	assert.Len(t, events, 3)
+	assert.Equal(t, "lab_chart", events[1].Output.MessageOutput.ToolName)
+	assert.Equal(t, "chart generated", events[1].Output.MessageOutput.Message.Content)
+	assert.Equal(t, [][]string{{"lab_chart"}, {"lab_chart"}}, bound)
+	assert.Equal(t, 1, listed)
+}
+
+func TestChatModelAgentWithToolSelectorAndToolkits(t *testing.T) {
+	ctx := context.Background()
+
+	fakeTool := &fakeToolForTest{tarCount: 1}
+	info, err := fakeTool.Info(ctx)
+	assert.NoError(t, err)
+	chart := &richToolForTest{result: &tool.Result{Content: "chart generated"}}
+
+	sel, err := selector.NewSelector(ctx, &selector.Config{
+		Embedder: &mockEmbedding.KeywordEmbedder{Keywords: []string{"chart", info.Name}},
+		Tools:    []tool.BaseTool{fakeTool, chart},
+		TopK:     1,
+		MinScore: 0.5,
+	})
+	assert.NoError(t, err)
+
+	toolkit := tool.ToolkitFunc(func(ctx context.Context) ([]tool.BaseTool, error) {
+		return []tool.BaseTool{&richToolForTest{result: &tool.Result{Content: "lab chart generated"}}}, nil
+	})
+
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockToolCallingChatModel(ctrl)
+	cm.EXPECT().WithTools(gomock.Any()).Return(cm, nil).AnyTimes()
+	var bound [][]string
+	cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+			var names []string
+			for _, info := range model.GetCommonOptions(nil, opts...).Tools {
+				names = append(names, info.Name)
+			}
+			bound = append(bound, names)
+			if input[len(input)-1].Role == schema.User {
+				return schema.AssistantMessage("", []schema.ToolCall{
+					{ID: "tool-call-1", Function: schema.FunctionCall{Name: "chart", Arguments: `{}`}},
+					{ID: "tool-call-2", Function: schema.FunctionCall{Name: "lab_chart", Arguments: `{}`}},
+				}), nil
+			}
+			return schema.AssistantMessage("done", nil), nil
+		}).Times(2)
+
+	agent, err := NewChatModelAgent(ctx, &ChatModelAgentConfig{
+		Name:        "TestAgent",
+		Description: "Test agent for unit testing",
+		Model:       cm,
+		ToolsConfig: ToolsConfig{
+			ToolsNodeConfig: compose.ToolsNodeConfig{
+				Toolkits: []tool.Toolkit{tool.PrefixToolkit(toolkit, "lab_")},
+			},
+			ToolSelector: sel,
+		},
+	})
+	assert.NoError(t, err)
+
+	iterator := agent.Run(ctx, &AgentInput{Messages: []Message{schema.UserMessage("draw a chart")}})
+	results := map[string]string{}
+	for {
+		event, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		assert.Nil(t, event.Err)
+		if out := event.Output; out != nil && out.MessageOutput.Role == schema.Tool {
+			results[out.MessageOutput.ToolName] = out.MessageOutput.Message.Content
+		}
+	}
+	assert.Equal(t, map[string]string{"chart": "chart generated", "lab_chart": "lab chart generated"}, results)
+	assert.Equal(t, [][]string{{"lab_chart", "chart"}, {"lab_chart", "chart"}}, bound)
+}
+
+func TestChatModelAgentWithToolPermissions(t *testing.T) {
+	ctx := context.Background()
+
+	chart := &richToolForTest{result: &tool.Result{Content: "chart generated"}}
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockToolCallingChatModel(ctrl)
+	cm.EXPECT().WithTools(gomock.Any()).Return(cm, nil).AnyTimes()
+	var toolResult string
+	cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+			if input[len(input)-1].Role == schema.User {
+				return schema.AssistantMessage("", []schema.ToolCall{
+					{ID: "tool-call-1", Function: schema.FunctionCall{Name: "chart", Arguments: `{}`}},
+				}), nil
+			}
+			toolResult = input[len(input)-1].Content
+			return schema.AssistantMessage("done", nil), nil
+		}).Times(2)
+
+	agent, err := NewChatModelAgent(ctx, &ChatModelAgentConfig{
+		Name:        "TestAgent",
+		Description: "Test agent for unit testing",
+		Model:       cm,
+		ToolsConfig: ToolsConfig{ToolsNodeConfig: compose.ToolsNodeConfig{
+			Tools: []tool.BaseTool{chart},
+			ToolPermissions: &compose.ToolPermissionPolicy{Rules: []*compose.ToolPermissionRule{{
+				Callers: []string{"TestAgent"},
+				Session: []*compose.ToolPermissionCondition{{Path: "plan", Op: compose.ToolPermissionOpNe, Value: "pro"}},
+				Action:  compose.ToolPermissionDeny,
+				Message: "charts require the pro plan",
+			}}},
+		}},
+	})
+	assert.NoError(t, err)
+
+	iterator := NewRunner(ctx, RunnerConfig{Agent: agent}).Run(ctx, []Message{schema.UserMessage("draw a chart")},
+		WithSessionValues(map[string]any{"plan": "free"}))
+	var events []*AgentEvent
+	for {
+		event, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		assert.Nil(t, event.Err)
+		events = append(events, event)
+	}
+	// the denied call isn't executed, and the message is returned to the model
+	assert.Len(t, events, 2)
+	assert.Equal(t, "charts require the pro plan", toolResult)
+}
+
+type richToolForTest struct {
+	result *tool.Result
+}
+
+func (r *richToolForTest) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: "chart", Desc: "draw a chart"}, nil
+}
+
+func (r *richToolForTest) RichInvokableRun(_ context.Context, _ string, _ ...tool.Option) (*tool.Result, error) {
+	return r.result, nil
 }
 
 // TestExitTool tests the Exit tool functionality
@@ -0,0 +1,279 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adk
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// EncodeAgentEventsSSE writes the events to w as Server-Sent Events, until the iterator ends or ctx is done.
+// Streaming message outputs are written chunk by chunk as they arrive, so the client receives the tokens in time.
+// See schema.EncodeSSE for the frame format and options.
+// e.g.
+//
+//	iter := runner.Run(ctx, messages)
+//	err := adk.EncodeAgentEventsSSE(ctx, w, iter, schema.WithStreamHeartbeat(15*time.Second))
+func EncodeAgentEventsSSE(ctx context.Context, w io.Writer, iter *AsyncIterator[*AgentEvent], opts ...schema.StreamEncodeOption) error {
+	return schema.EncodeSSE(ctx, w, agentEventsToFrames(iter), opts...)
+}
+
+// EncodeAgentEventsNDJSON writes the events to w as newline-delimited json, the same as EncodeAgentEventsSSE otherwise.
+func EncodeAgentEventsNDJSON(ctx context.Context, w io.Writer, iter *AsyncIterator[*AgentEvent], opts ...schema.StreamEncodeOption) error {
+	return schema.EncodeNDJSON(ctx, w, agentEventsToFrames(iter), opts...)
+}
+
+// DecodeAgentEventsSSE reads the events written by EncodeAgentEventsSSE back, e.g. from an http response body.
+// Streaming message outputs are restored as MessageStream, which receives the chunks as they arrive,
+// so the MessageStream of an event should be consumed or closed before calling Next for the next event.
+// Errors are restored as plain errors with the same messages, and the any typed fields,
+// e.g. InterruptInfo.Data and CustomizedOutput, are restored as the json decoded values.
+// Transport errors, e.g. the connection is broken, are sent as an event with Err.
+func DecodeAgentEventsSSE(r io.Reader) *AsyncIterator[*AgentEvent] {
+	return framesToAgentEvents(schema.DecodeSSE[*agentEventFrame](r))
+}
+
+// DecodeAgentEventsNDJSON reads the events written by EncodeAgentEventsNDJSON back, the same as DecodeAgentEventsSSE otherwise.
+func DecodeAgentEventsNDJSON(r io.Reader) *AsyncIterator[*AgentEvent] {
+	return framesToAgentEvents(schema.DecodeNDJSON[*agentEventFrame](r))
+}
+
+const (
+	agentFrameEvent      = "event"
+	agentFrameChunk      = "chunk"
+	agentFrameChunkError = "chunk_error"
+	agentFrameStreamEnd  = "stream_end"
+)
+
+// agentEventFrame is the wire format of an AgentEvent, a streaming event is split into an event frame,
+// chunk frames and a stream_end frame.
+type agentEventFrame struct {
+	Kind string `json:"kind"`
+
+	AgentName string            `json:"agent_name,omitempty"`
+	RunPath   []string          `json:"run_path,omitempty"`
+	Output    *agentOutputFrame `json:"output,omitempty"`
+	Action    *agentActionFrame `json:"action,omitempty"`
+	Err       string            `json:"err,omitempty"`
+
+	Chunk *schema.Message `json:"chunk,omitempty"`
+}
+
+type agentOutputFrame struct {
+	IsStreaming      bool            `json:"is_streaming,omitempty"`
+	Message          *schema.Message `json:"message,omitempty"`
+	Role             schema.RoleType `json:"role,omitempty"`
+	ToolName         string          `json:"tool_name,omitempty"`
+	ToolArtifact     any             `json:"tool_artifact,omitempty"`
+	CustomizedOutput any             `json:"customized_output,omitempty"`
+}
+
+type agentActionFrame struct {
+	Exit             bool                   `json:"exit,omitempty"`
+	Interrupted      *InterruptInfo         `json:"interrupted,omitempty"`
+	TransferToAgent  *TransferToAgentAction `json:"transfer_to_agent,omitempty"`
+	BreakLoop        *BreakLoopAction       `json:"break_loop,omitempty"`
+	CustomizedAction any                    `json:"customized_action,omitempty"`
+}
+
+func agentEventsToFrames(iter *AsyncIterator[*AgentEvent]) *schema.StreamReader[*agentEventFrame] {
+	sr, sw := schema.Pipe[*agentEventFrame](0)
+
+	go func() {
+		defer sw.Close()
+
+		for {
+			event, ok := iter.Next()
+			if !ok {
+				return
+			}
+
+			frame, stream := toAgentEventFrame(event)
+			if sw.Send(frame, nil) {
+				if stream != nil {
+					stream.Close()
+				}
+				return
+			}
+			if stream != nil && !sendMessageStreamFrames(sw, stream) {
+				return
+			}
+		}
+	}()
+
+	return sr
+}
+
+// sendMessageStreamFrames returns false if the frame stream has been closed.
+func sendMessageStreamFrames(sw *schema.StreamWriter[*agentEventFrame], stream MessageStream) bool {
+	defer stream.Close()
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return !sw.Send(&agentEventFrame{Kind: agentFrameStreamEnd}, nil)
+		}
+
+		frame := &agentEventFrame{Kind: agentFrameChunk, Chunk: chunk}
+		if err != nil {
+			frame = &agentEventFrame{Kind: agentFrameChunkError, Err: err.Error()}
+		}
+		if sw.Send(frame, nil) {
+			return false
+		}
+	}
+}
+
+func toAgentEventFrame(event *AgentEvent) (*agentEventFrame, MessageStream) {
+	frame := &agentEventFrame{
+		Kind:      agentFrameEvent,
+		AgentName: event.AgentName,
+	}
+	for _, step := range event.RunPath {
+		frame.RunPath = append(frame.RunPath, step.agentName)
+	}
+	if event.Err != nil {
+		frame.Err = event.Err.Error()
+	}
+
+	var stream MessageStream
+	if o := event.Output; o != nil {
+		frame.Output = &agentOutputFrame{CustomizedOutput: o.CustomizedOutput}
+		if mv := o.MessageOutput; mv != nil {
+			frame.Output.IsStreaming = mv.IsStreaming
+			frame.Output.Message = mv.Message
+			frame.Output.Role = mv.Role
+			frame.Output.ToolName = mv.ToolName
+			frame.Output.ToolArtifact = mv.ToolArtifact
+			if mv.IsStreaming {
+				stream = mv.MessageStream
+			}
+		}
+	}
+
+	if a := event.Action; a != nil {
+		frame.Action = &agentActionFrame{
+			Exit:             a.Exit,
+			Interrupted:      a.Interrupted,
+			TransferToAgent:  a.TransferToAgent,
+			BreakLoop:        a.BreakLoop,
+			CustomizedAction: a.CustomizedAction,
+		}
+	}
+
+	return frame, stream
+}
+
+func framesToAgentEvents(frames *schema.StreamReader[*agentEventFrame]) *AsyncIterator[*AgentEvent] {
+	iter, gen := NewAsyncIteratorPair[*AgentEvent]()
+
+	go func() {
+		defer frames.Close()
+		defer gen.Close()
+
+		var streamWriter *schema.StreamWriter[Message]
+		closeStream := func() {
+			if streamWriter != nil {
+				streamWriter.Close()
+				streamWriter = nil
+			}
+		}
+		defer closeStream()
+
+		for {
+			frame, err := frames.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				// transport error
+				if streamWriter != nil {
+					streamWriter.Send(nil, err)
+					closeStream()
+				}
+				gen.Send(&AgentEvent{Err: err})
+				continue
+			}
+
+			switch frame.Kind {
+			case agentFrameEvent:
+				closeStream()
+				var event *AgentEvent
+				event, streamWriter = fromAgentEventFrame(frame)
+				gen.Send(event)
+			case agentFrameChunk:
+				if streamWriter != nil {
+					streamWriter.Send(frame.Chunk, nil)
+				}
+			case agentFrameChunkError:
+				if streamWriter != nil {
+					streamWriter.Send(nil, errors.New(frame.Err))
+				}
+			case agentFrameStreamEnd:
+				closeStream()
+			default:
+			}
+		}
+	}()
+
+	return iter
+}
+
+func fromAgentEventFrame(frame *agentEventFrame) (*AgentEvent, *schema.StreamWriter[Message]) {
+	event := &AgentEvent{
+		AgentName: frame.AgentName,
+	}
+	for _, name := range frame.RunPath {
+		event.RunPath = append(event.RunPath, RunStep{agentName: name})
+	}
+	if frame.Err != "" {
+		event.Err = errors.New(frame.Err)
+	}
+
+	var sw *schema.StreamWriter[Message]
+	if o := frame.Output; o != nil {
+		event.Output = &AgentOutput{CustomizedOutput: o.CustomizedOutput}
+		if o.IsStreaming || o.Message != nil {
+			mv := &MessageVariant{
+				IsStreaming:  o.IsStreaming,
+				Message:      o.Message,
+				Role:         o.Role,
+				ToolName:     o.ToolName,
+				ToolArtifact: o.ToolArtifact,
+			}
+			if o.IsStreaming {
+				mv.MessageStream, sw = schema.Pipe[Message](0)
+			}
+			event.Output.MessageOutput = mv
+		}
+	}
+
+	if a := frame.Action; a != nil {
+		event.Action = &AgentAction{
+			Exit:             a.Exit,
+			Interrupted:      a.Interrupted,
+			TransferToAgent:  a.TransferToAgent,
+			BreakLoop:        a.BreakLoop,
+			CustomizedAction: a.CustomizedAction,
+		}
+	}
+
+	return event, sw
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestAgentEventCodec(t *testing.T) {
+	ctx := context.Background()
+	idx := 0
+
+	newEvents := func() *AsyncIterator[*AgentEvent] {
+		iter, gen := NewAsyncIteratorPair[*AgentEvent]()
+		sr, sw := schema.Pipe[Message](3)
+		sw.Send(&schema.Message{Role: schema.Assistant, ReasoningContent: "think"}, nil)
+		sw.Send(&schema.Message{Role: schema.Assistant, ToolCalls: []schema.ToolCall{{Index: &idx, ID: "1", Function: schema.FunctionCall{Name: "f"}}}}, nil)
+		sw.Send(nil, errors.New("chunk error"))
+		sw.Close()
+
+		streaming := EventFromMessage(nil, sr, schema.Assistant, "")
+		streaming.AgentName = "a"
+		streaming.RunPath = []RunStep{{agentName: "root"}, {agentName: "a"}}
+		gen.Send(streaming)
+
+		tool := EventFromMessage(schema.ToolMessage("result", "1"), nil, schema.Tool, "f")
+		tool.AgentName = "a"
+		gen.Send(tool)
+
+		gen.Send(&AgentEvent{AgentName: "a", Action: NewTransferToAgentAction("b")})
+		gen.Send(&AgentEvent{AgentName: "b", Err: errors.New("failed")})
+		gen.Close()
+		return iter
+	}
+
+	codecs := map[string]struct {
+		encode func(ctx context.Context, w io.Writer, iter *AsyncIterator[*AgentEvent], opts ...schema.StreamEncodeOption) error
+		decode func(r io.Reader) *AsyncIterator[*AgentEvent]
+	}{
+		"sse":    {encode: EncodeAgentEventsSSE, decode: DecodeAgentEventsSSE},
+		"ndjson": {encode: EncodeAgentEventsNDJSON, decode: DecodeAgentEventsNDJSON},
+	}
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			assert.NoError(t, codec.encode(ctx, buf, newEvents()))
+
+			iter := codec.decode(buf)
+
+			event, ok := iter.Next()
+			assert.True(t, ok)
+			assert.Equal(t, "a", event.AgentName)
+			assert.Equal(t, []RunStep{{agentName: "root"}, {agentName: "a"}}, event.RunPath)
+			assert.True(t, event.Output.MessageOutput.IsStreaming)
+			var chunks []Message
+			var errs []error
+			for {
+				chunk, err := event.Output.MessageOutput.MessageStream.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				chunks = append(chunks, chunk)
+			}
+			assert.Len(t, chunks, 2)
+			assert.Equal(t, "think", chunks[0].ReasoningContent)
+			assert.Equal(t, 0, *chunks[1].ToolCalls[0].Index)
+			assert.Equal(t, []error{errors.New("chunk error")}, errs)
+
+			event, ok = iter.Next()
+			assert.True(t, ok)
+			assert.False(t, event.Output.MessageOutput.IsStreaming)
+			assert.Equal(t, "result", event.Output.MessageOutput.Message.Content)
+			assert.Equal(t, "f", event.Output.MessageOutput.ToolName)
+
+			event, ok = iter.Next()
+			assert.True(t, ok)
+			assert.Equal(t, "b", event.Action.TransferToAgent.DestAgentName)
+
+			event, ok = iter.Next()
+			assert.True(t, ok)
+			assert.EqualError(t, event.Err, "failed")
+
+			_, ok = iter.Next()
+			assert.False(t, ok)
+		})
+	}
+
+	t.Run("broken connection", func(t *testing.T) {
+		iter := DecodeAgentEventsNDJSON(strings.NewReader(`{"type":"data","data":{"kind":"event","agent_name":"a"}}` + "\n"))
+		event, ok := iter.Next()
+		assert.True(t, ok)
+		assert.Equal(t, "a", event.AgentName)
+		event, ok = iter.Next()
+		assert.True(t, ok)
+		assert.True(t, errors.Is(event.Err, io.ErrUnexpectedEOF))
+		_, ok = iter.Next()
+		assert.False(t, ok)
+	})
+}
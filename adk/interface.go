@@ -40,6 +40,8 @@ type MessageVariant struct {
 	Role schema.RoleType
 	// only used when Role is Tool
 	ToolName string
+	// only used when Role is Tool, the Artifact of the tool.Result returned by a tool.RichInvokableTool, not gob encoded
+	ToolArtifact any
 }
 
 func EventFromMessage(msg Message, msgStream MessageStream,
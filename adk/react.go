@@ -21,9 +21,11 @@ package adk
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 
 	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 )
@@ -79,6 +81,8 @@ type reactConfig struct {
 	model model.ToolCallingChatModel
 
 	toolsConfig *compose.ToolsNodeConfig
+	// toolSelector binds the tools selected on each turn in addition to the tools of toolsConfig, optional.
+	toolSelector tool.ToolSelector
 
 	toolsReturnDirectly map[string]bool
 
@@ -97,10 +101,56 @@ func genToolInfos(ctx context.Context, config *compose.ToolsNodeConfig) ([]*sche
 
 		toolInfos = append(toolInfos, tl)
 	}
+	if info := compose.ReadMoreToolInfo(config); info != nil {
+		toolInfos = append(toolInfos, info)
+	}
 
 	return toolInfos, nil
 }
 
+// toolkitOptions lists the tools of the toolkits for the run, and binds them to the model and the ToolsNode
+// along with the tools of the config, returning nil if there are no toolkits.
+func toolkitOptions(ctx context.Context, config *compose.ToolsNodeConfig, toolSelector tool.ToolSelector) ([]compose.Option, error) {
+	if len(config.Toolkits) == 0 {
+		return nil, nil
+	}
+
+	tools, err := tool.MergeToolkits(append([]tool.Toolkit{tool.NewToolkit(config.Tools...)}, config.Toolkits...)...).Tools(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools from toolkits: %w", err)
+	}
+	conf := *config
+	conf.Tools = tools
+	toolInfos, err := genToolInfos(ctx, &conf)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeTools := tools
+	if toolSelector != nil {
+		nodeTools = append(tools[:len(tools):len(tools)], toolSelector.Tools()...)
+	}
+	return []compose.Option{
+		compose.WithChatModelOption(model.WithTools(toolInfos)),
+		compose.WithToolsNodeOption(compose.WithToolList(nodeTools...)),
+	}, nil
+}
+
+// toolCallerInfo describes the agent calling the tools for the permission rules of the ToolsNode.
+func toolCallerInfo(ctx context.Context, agentName string) *compose.ToolCallerInfo {
+	info := &compose.ToolCallerInfo{
+		SessionValues: func() map[string]any { return GetSessionValues(ctx) },
+	}
+	if runCtx := getRunCtx(ctx); runCtx != nil && len(runCtx.RunPath) > 0 {
+		for _, step := range runCtx.RunPath {
+			info.RunPath = append(info.RunPath, step.agentName)
+		}
+	} else {
+		info.RunPath = []string{agentName}
+	}
+	return info
+}
+
 type reactGraph = *compose.Graph[[]Message, Message]
 type sToolNodeOutput = *schema.StreamReader[[]Message]
 type sGraphOutput = MessageStream
@@ -144,12 +194,21 @@ func newReact(ctx context.Context, config *reactConfig) (reactGraph, error) {
 		return nil, err
 	}
 
-	chatModel, err := config.model.WithTools(toolsInfo)
+	toolCallingModel := config.model
+	toolsNodeConf := config.toolsConfig
+	if config.toolSelector != nil {
+		toolCallingModel = config.toolSelector.Model(toolCallingModel)
+		conf := *config.toolsConfig
+		conf.Tools = append(conf.Tools[:len(conf.Tools):len(conf.Tools)], config.toolSelector.Tools()...)
+		toolsNodeConf = &conf
+	}
+
+	chatModel, err := toolCallingModel.WithTools(toolsInfo)
 	if err != nil {
 		return nil, err
 	}
 
-	toolsNode, err := compose.NewToolNode(ctx, config.toolsConfig)
+	toolsNode, err := compose.NewToolNode(ctx, toolsNodeConf)
 	if err != nil {
 		return nil, err
 	}
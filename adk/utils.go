@@ -179,9 +179,10 @@ func copyAgentEvent(ae *AgentEvent) *AgentEvent {
 	}
 
 	copied.Output.MessageOutput = &MessageVariant{
-		IsStreaming: mv.IsStreaming,
-		Role:        mv.Role,
-		ToolName:    mv.ToolName,
+		IsStreaming:  mv.IsStreaming,
+		Role:         mv.Role,
+		ToolName:     mv.ToolName,
+		ToolArtifact: mv.ToolArtifact,
 	}
 	if mv.IsStreaming {
 		sts := ae.Output.MessageOutput.MessageStream.Copy(2)
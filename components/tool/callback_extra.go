@@ -32,6 +32,8 @@ type CallbackInput struct {
 type CallbackOutput struct {
 	// Response is the response for the tool.
 	Response string
+	// Result is the rich result for the tool implementing RichInvokableTool, nil for other tools.
+	Result *Result
 	// Extra is the extra information for the tool.
 	Extra map[string]any
 }
@@ -0,0 +1,37 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryableError(t *testing.T) {
+	assert.Nil(t, NewRetryableError(nil))
+
+	base := errors.New("connection reset")
+	err := fmt.Errorf("call api: %w", NewRetryableError(base))
+	assert.True(t, IsRetryableError(err))
+	assert.True(t, errors.Is(err, base))
+	assert.Equal(t, "call api: connection reset", err.Error())
+
+	assert.False(t, IsRetryableError(base))
+}
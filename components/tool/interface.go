@@ -19,6 +19,7 @@ package tool
 import (
 	"context"
 
+	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
 )
 
@@ -41,3 +42,34 @@ type StreamableTool interface {
 
 	StreamableRun(ctx context.Context, argumentsInJSON string, opts ...Option) (*schema.StreamReader[string], error)
 }
+
+// RichInvokableTool the tool returning a rich result, e.g. images or files, for ChatModel intent recognition and ToolsNode execution.
+// ToolsNode prefers RichInvokableRun to InvokableRun if a tool implements both.
+type RichInvokableTool interface {
+	BaseTool
+
+	// RichInvokableRun call function with arguments in JSON format, and returns the rich result.
+	RichInvokableRun(ctx context.Context, argumentsInJSON string, opts ...Option) (*Result, error)
+}
+
+// Result is the rich result of a tool call.
+type Result struct {
+	// Content is the text for the model, used as the content of the tool message.
+	Content string
+	// Parts are the multimodal parts for the model, e.g. a generated chart,
+	// used as the UserInputMultiContent of the tool message.
+	Parts []schema.MessageInputPart
+	// Artifact is the payload kept by the application but not seen by the model, e.g. the raw data of the chart,
+	// which is delivered to the callbacks by CallbackOutput.Result and to the agent events.
+	Artifact any
+}
+
+// ToolSelector selects the tools bound to the ChatModel on each turn from a large tool catalog,
+// e.g. the Selector of flow/tool/selector selecting by embedding similarity.
+type ToolSelector interface {
+	// Model wraps the ChatModel to bind the tools selected for the input messages on each call,
+	// in addition to the tools given by WithTools of the returned model, or by the model.WithTools option of the call.
+	Model(cm model.ToolCallingChatModel) model.ToolCallingChatModel
+	// Tools returns all the tools that may be selected, to be executed by ToolsNode.
+	Tools() []BaseTool
+}
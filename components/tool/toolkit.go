@@ -0,0 +1,254 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"reflect"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/internal/generic"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Toolkit is a set of tools listed at runtime, e.g. the tools of an MCP server,
+// or the tools the current user is permitted to use.
+// Tools is called on each run, so the set may change between runs.
+type Toolkit interface {
+	Tools(ctx context.Context) ([]BaseTool, error)
+}
+
+// ToolkitFunc is the function type implementing Toolkit.
+type ToolkitFunc func(ctx context.Context) ([]BaseTool, error)
+
+// Tools calls f(ctx).
+func (f ToolkitFunc) Tools(ctx context.Context) ([]BaseTool, error) {
+	return f(ctx)
+}
+
+// NewToolkit creates a Toolkit of the fixed tools.
+func NewToolkit(tools ...BaseTool) Toolkit {
+	return ToolkitFunc(func(_ context.Context) ([]BaseTool, error) {
+		return tools, nil
+	})
+}
+
+// PrefixToolkit namespaces the tools of the toolkit by adding the prefix to their names, e.g. "github_".
+// The renamed tools keep the invokable, streamable and rich invokable abilities of the original tools.
+func PrefixToolkit(tk Toolkit, prefix string) Toolkit {
+	return ToolkitFunc(func(ctx context.Context) ([]BaseTool, error) {
+		tools, err := tk.Tools(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ret := make([]BaseTool, len(tools))
+		for i, t := range tools {
+			ret[i] = renameTool(t, func(name string) string { return prefix + name })
+		}
+		return ret, nil
+	})
+}
+
+// FilterToolkit keeps the tools of the toolkit whose names match any of the allow patterns and none of the deny patterns.
+// The patterns are in the syntax of path.Match, e.g. "github_*". Empty allow patterns allow all the tools.
+func FilterToolkit(tk Toolkit, allow, deny []string) Toolkit {
+	return ToolkitFunc(func(ctx context.Context) ([]BaseTool, error) {
+		tools, err := tk.Tools(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ret := make([]BaseTool, 0, len(tools))
+		for _, t := range tools {
+			info, err := t.Info(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get tool info: %w", err)
+			}
+			allowed, err := matchAny(allow, info.Name)
+			if err != nil {
+				return nil, err
+			}
+			if len(allow) > 0 && !allowed {
+				continue
+			}
+			denied, err := matchAny(deny, info.Name)
+			if err != nil {
+				return nil, err
+			}
+			if !denied {
+				ret = append(ret, t)
+			}
+		}
+		return ret, nil
+	})
+}
+
+func matchAny(patterns []string, name string) (bool, error) {
+	for _, p := range patterns {
+		ok, err := path.Match(p, name)
+		if err != nil {
+			return false, fmt.Errorf("invalid tool name pattern %q: %w", p, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// MergeToolkits merges the toolkits into one, failing when the tools of different toolkits have the same name.
+// Use PrefixToolkit to resolve the collisions.
+func MergeToolkits(tks ...Toolkit) Toolkit {
+	return ToolkitFunc(func(ctx context.Context) ([]BaseTool, error) {
+		var ret []BaseTool
+		names := make(map[string]struct{})
+		for _, tk := range tks {
+			if tk == nil {
+				continue
+			}
+			tools, err := tk.Tools(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, t := range tools {
+				info, err := t.Info(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get tool info: %w", err)
+				}
+				if _, ok := names[info.Name]; ok {
+					return nil, fmt.Errorf("duplicate tool name in toolkits: %s", info.Name)
+				}
+				names[info.Name] = struct{}{}
+				ret = append(ret, t)
+			}
+		}
+		return ret, nil
+	})
+}
+
+// renameTool wraps the tool with the name converted by rename, keeping the abilities of the tool.
+func renameTool(t BaseTool, rename func(string) string) BaseTool {
+	r := &renamedTool{tool: t, rename: rename}
+	it, isInvokable := t.(InvokableTool)
+	st, isStreamable := t.(StreamableTool)
+	rt, isRich := t.(RichInvokableTool)
+
+	switch {
+	case isRich && isStreamable:
+		return &renamedRichStreamableTool{renamedRichTool: &renamedRichTool{renamedTool: r, rt: rt, it: it}, st: st}
+	case isRich:
+		return &renamedRichTool{renamedTool: r, rt: rt, it: it}
+	case isInvokable && isStreamable:
+		return &renamedInvokableStreamableTool{renamedTool: r, it: it, st: st}
+	case isStreamable:
+		return &renamedStreamableTool{renamedTool: r, st: st}
+	case isInvokable:
+		return &renamedInvokableTool{renamedTool: r, it: it}
+	default:
+		return r
+	}
+}
+
+type renamedTool struct {
+	tool   BaseTool
+	rename func(string) string
+}
+
+func (r *renamedTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	info, err := r.tool.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	renamed := *info
+	renamed.Name = r.rename(info.Name)
+	return &renamed, nil
+}
+
+func (r *renamedTool) GetType() string {
+	if typ, ok := components.GetType(r.tool); ok {
+		return typ
+	}
+	return generic.ParseTypeName(reflect.ValueOf(r.tool))
+}
+
+func (r *renamedTool) IsCallbacksEnabled() bool {
+	return components.IsCallbacksEnabled(r.tool)
+}
+
+type renamedInvokableTool struct {
+	*renamedTool
+	it InvokableTool
+}
+
+func (r *renamedInvokableTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...Option) (string, error) {
+	return r.it.InvokableRun(ctx, argumentsInJSON, opts...)
+}
+
+type renamedStreamableTool struct {
+	*renamedTool
+	st StreamableTool
+}
+
+func (r *renamedStreamableTool) StreamableRun(ctx context.Context, argumentsInJSON string, opts ...Option) (*schema.StreamReader[string], error) {
+	return r.st.StreamableRun(ctx, argumentsInJSON, opts...)
+}
+
+type renamedInvokableStreamableTool struct {
+	*renamedTool
+	it InvokableTool
+	st StreamableTool
+}
+
+func (r *renamedInvokableStreamableTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...Option) (string, error) {
+	return r.it.InvokableRun(ctx, argumentsInJSON, opts...)
+}
+
+func (r *renamedInvokableStreamableTool) StreamableRun(ctx context.Context, argumentsInJSON string, opts ...Option) (*schema.StreamReader[string], error) {
+	return r.st.StreamableRun(ctx, argumentsInJSON, opts...)
+}
+
+// renamedRichTool is invokable as well, returning the content of the rich result if the original tool isn't invokable.
+type renamedRichTool struct {
+	*renamedTool
+	rt RichInvokableTool
+	it InvokableTool
+}
+
+func (r *renamedRichTool) RichInvokableRun(ctx context.Context, argumentsInJSON string, opts ...Option) (*Result, error) {
+	return r.rt.RichInvokableRun(ctx, argumentsInJSON, opts...)
+}
+
+func (r *renamedRichTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...Option) (string, error) {
+	if r.it != nil {
+		return r.it.InvokableRun(ctx, argumentsInJSON, opts...)
+	}
+	result, err := r.rt.RichInvokableRun(ctx, argumentsInJSON, opts...)
+	if err != nil {
+		return "", err
+	}
+	return result.Content, nil
+}
+
+type renamedRichStreamableTool struct {
+	*renamedRichTool
+	st StreamableTool
+}
+
+func (r *renamedRichStreamableTool) StreamableRun(ctx context.Context, argumentsInJSON string, opts ...Option) (*schema.StreamReader[string], error) {
+	return r.st.StreamableRun(ctx, argumentsInJSON, opts...)
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/schema"
+)
+
+type namedTool struct {
+	name string
+}
+
+func (t *namedTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: t.name, Desc: "tool " + t.name}, nil
+}
+
+func (t *namedTool) InvokableRun(_ context.Context, _ string, _ ...Option) (string, error) {
+	return t.name, nil
+}
+
+type namedStreamTool struct {
+	namedTool
+}
+
+func (t *namedStreamTool) StreamableRun(_ context.Context, _ string, _ ...Option) (*schema.StreamReader[string], error) {
+	return schema.StreamReaderFromArray([]string{t.name}), nil
+}
+
+func (t *namedStreamTool) GetType() string {
+	return "Stream"
+}
+
+func (t *namedStreamTool) IsCallbacksEnabled() bool {
+	return true
+}
+
+type namedRichTool struct {
+	name string
+}
+
+func (t *namedRichTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: t.name}, nil
+}
+
+func (t *namedRichTool) RichInvokableRun(_ context.Context, _ string, _ ...Option) (*Result, error) {
+	return &Result{Content: t.name, Artifact: t.name}, nil
+}
+
+func toolNames(t *testing.T, tools []BaseTool) []string {
+	names := make([]string, 0, len(tools))
+	for _, tl := range tools {
+		info, err := tl.Info(context.Background())
+		assert.NoError(t, err)
+		names = append(names, info.Name)
+	}
+	return names
+}
+
+func TestPrefixToolkit(t *testing.T) {
+	ctx := context.Background()
+	tk := PrefixToolkit(NewToolkit(&namedTool{name: "a"}, &namedStreamTool{namedTool{name: "b"}}, &namedRichTool{name: "c"}), "ns_")
+
+	tools, err := tk.Tools(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ns_a", "ns_b", "ns_c"}, toolNames(t, tools))
+
+	info, err := tools[0].Info(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "tool a", info.Desc)
+	out, err := tools[0].(InvokableTool).InvokableRun(ctx, "{}")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", out)
+	_, ok := tools[0].(StreamableTool)
+	assert.False(t, ok)
+	assert.False(t, components.IsCallbacksEnabled(tools[0]))
+
+	sr, err := tools[1].(StreamableTool).StreamableRun(ctx, "{}")
+	assert.NoError(t, err)
+	chunk, err := sr.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "b", chunk)
+	_, ok = tools[1].(InvokableTool)
+	assert.True(t, ok)
+	assert.True(t, components.IsCallbacksEnabled(tools[1]))
+	typ, _ := components.GetType(tools[1])
+	assert.Equal(t, "Stream", typ)
+
+	result, err := tools[2].(RichInvokableTool).RichInvokableRun(ctx, "{}")
+	assert.NoError(t, err)
+	assert.Equal(t, "c", result.Artifact)
+	out, err = tools[2].(InvokableTool).InvokableRun(ctx, "{}")
+	assert.NoError(t, err)
+	assert.Equal(t, "c", out)
+}
+
+func TestFilterToolkit(t *testing.T) {
+	ctx := context.Background()
+	base := NewToolkit(&namedTool{name: "github_list"}, &namedTool{name: "github_delete"}, &namedTool{name: "jira_list"})
+
+	tools, err := FilterToolkit(base, []string{"github_*"}, nil).Tools(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"github_list", "github_delete"}, toolNames(t, tools))
+
+	tools, err = FilterToolkit(base, nil, []string{"*_delete"}).Tools(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"github_list", "jira_list"}, toolNames(t, tools))
+
+	tools, err = FilterToolkit(base, []string{"github_*"}, []string{"*_delete"}).Tools(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"github_list"}, toolNames(t, tools))
+
+	_, err = FilterToolkit(base, []string{"["}, nil).Tools(ctx)
+	assert.ErrorContains(t, err, "invalid tool name pattern")
+}
+
+func TestMergeToolkits(t *testing.T) {
+	ctx := context.Background()
+	a := NewToolkit(&namedTool{name: "search"})
+	b := NewToolkit(&namedTool{name: "search"}, &namedTool{name: "fetch"})
+
+	_, err := MergeToolkits(a, b).Tools(ctx)
+	assert.ErrorContains(t, err, "duplicate tool name in toolkits: search")
+
+	tools, err := MergeToolkits(a, nil, PrefixToolkit(b, "web_")).Tools(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"search", "web_search", "web_fetch"}, toolNames(t, tools))
+
+	// the toolkits are listed on each call
+	var userTools []BaseTool
+	dynamic := ToolkitFunc(func(ctx context.Context) ([]BaseTool, error) {
+		if userTools == nil {
+			return nil, errors.New("no permission")
+		}
+		return userTools, nil
+	})
+	_, err = MergeToolkits(a, dynamic).Tools(ctx)
+	assert.ErrorContains(t, err, "no permission")
+	userTools = []BaseTool{&namedTool{name: "admin"}}
+	tools, err = MergeToolkits(a, dynamic).Tools(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"search", "admin"}, toolNames(t, tools))
+}
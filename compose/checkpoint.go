@@ -123,7 +123,11 @@ type checkpoint struct {
 	SkipPreHandler map[string]bool
 	RerunNodes     []string
 
-	ToolsNodeExecutedTools map[string] /*tool node key*/ map[string] /*tool call id*/ string
+	ToolsNodeExecutedTools     map[string] /*tool node key*/ map[string] /*tool call id*/ string
+	ToolsNodeExecutedToolParts map[string] /*tool node key*/ map[string] /*tool call id*/ []schema.MessageInputPart
+
+	// ThreadStateVersion is the version of the thread state loaded by the interrupted run, expected when the resumed run saves the state.
+	ThreadStateVersion *int64
 
 	SubGraphs map[string]*checkpoint
 }
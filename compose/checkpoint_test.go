@@ -1193,3 +1193,129 @@ func TestCancelInterrupt(t *testing.T) {
 		"3": "3",
 	}, result2)
 }
+
+func TestConditionalInterrupt(t *testing.T) {
+	ctx := context.Background()
+
+	newGraph := func() *Graph[string, string] {
+		g := NewGraph[string, string](WithGenLocalState(func(ctx context.Context) (state *testStruct) {
+			return &testStruct{A: ""}
+		}))
+		assert.NoError(t, g.AddLambdaNode("1", InvokableLambda(func(ctx context.Context, input string) (output string, err error) {
+			return input + "1", nil
+		})))
+		assert.NoError(t, g.AddLambdaNode("2", InvokableLambda(func(ctx context.Context, input string) (output string, err error) {
+			return input + "2", nil
+		}), WithStatePreHandler(func(ctx context.Context, in string, state *testStruct) (string, error) {
+			return in + state.A, nil
+		})))
+		assert.NoError(t, g.AddEdge(START, "1"))
+		assert.NoError(t, g.AddEdge("1", "2"))
+		assert.NoError(t, g.AddEdge("2", END))
+		return g
+	}
+
+	t.Run("interrupt before", func(t *testing.T) {
+		var calledNodes []string
+		r, err := newGraph().Compile(ctx, WithCheckPointStore(newInMemoryStore()), WithInterruptBeforeNodesIf(
+			func(ctx context.Context, in *InterruptConditionInput) (bool, any, error) {
+				calledNodes = append(calledNodes, in.NodeKey)
+				assert.Equal(t, []string{"2"}, in.Path.GetPath())
+				assert.Equal(t, &testStruct{A: ""}, in.State)
+				if in.Value.(string) == "review1" {
+					return true, "review needed", nil
+				}
+				return false, nil, nil
+			}, "2"))
+		assert.NoError(t, err)
+
+		result, err := r.Invoke(ctx, "start", WithCheckPointID("1"))
+		assert.NoError(t, err)
+		assert.Equal(t, "start12", result)
+
+		_, err = r.Invoke(ctx, "review", WithCheckPointID("2"))
+		info, ok := ExtractInterruptInfo(err)
+		assert.True(t, ok)
+		assert.Equal(t, &InterruptInfo{
+			State:            &testStruct{A: ""},
+			BeforeNodes:      []string{"2"},
+			RerunNodesExtra:  map[string]any{},
+			SubGraphs:        map[string]*InterruptInfo{},
+			BeforeNodesExtra: map[string]any{"2": "review needed"},
+		}, info)
+
+		result, err = r.Invoke(ctx, "review", WithCheckPointID("2"), WithStateModifier(func(ctx context.Context, path NodePath, state any) error {
+			state.(*testStruct).A = "approved"
+			return nil
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, "review1approved2", result)
+		assert.Equal(t, []string{"2", "2"}, calledNodes)
+
+		_, err = r.Stream(ctx, "review", WithCheckPointID("3"))
+		info, ok = ExtractInterruptInfo(err)
+		assert.True(t, ok)
+		assert.Equal(t, map[string]any{"2": "review needed"}, info.BeforeNodesExtra)
+
+		sr, err := r.Stream(ctx, "review", WithCheckPointID("3"))
+		assert.NoError(t, err)
+		streamResult, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, "review12", streamResult)
+	})
+
+	t.Run("interrupt after", func(t *testing.T) {
+		r, err := newGraph().Compile(ctx, WithCheckPointStore(newInMemoryStore()), WithInterruptAfterNodesIf(
+			func(ctx context.Context, in *InterruptConditionInput) (bool, any, error) {
+				return in.NodeKey == "1" && in.State.(*testStruct).A == "", in.Value, nil
+			}))
+		assert.NoError(t, err)
+
+		_, err = r.Stream(ctx, "start", WithCheckPointID("1"))
+		info, ok := ExtractInterruptInfo(err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"1"}, info.AfterNodes)
+		assert.Equal(t, map[string]any{"1": "start1"}, info.AfterNodesExtra)
+
+		sr, err := r.Stream(ctx, "start", WithCheckPointID("1"), WithStateModifier(func(ctx context.Context, path NodePath, state any) error {
+			state.(*testStruct).A = "approved"
+			return nil
+		}))
+		assert.NoError(t, err)
+		streamResult, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, "start1approved2", streamResult)
+	})
+
+	t.Run("condition error", func(t *testing.T) {
+		r, err := newGraph().Compile(ctx, WithInterruptAfterNodesIf(
+			func(ctx context.Context, in *InterruptConditionInput) (bool, any, error) {
+				return false, nil, errors.New("condition error")
+			}, "1"))
+		assert.NoError(t, err)
+		_, err = r.Invoke(ctx, "start")
+		assert.ErrorContains(t, err, "interrupt after node[1] condition fail: condition error")
+	})
+
+	t.Run("subgraph", func(t *testing.T) {
+		g := NewGraph[string, string]()
+		assert.NoError(t, g.AddGraphNode("sub", newGraph(), WithGraphCompileOptions(WithInterruptBeforeNodesIf(
+			func(ctx context.Context, in *InterruptConditionInput) (bool, any, error) {
+				return true, in.Path.GetPath(), nil
+			}, "2"))))
+		assert.NoError(t, g.AddEdge(START, "sub"))
+		assert.NoError(t, g.AddEdge("sub", END))
+		r, err := g.Compile(ctx, WithCheckPointStore(newInMemoryStore()))
+		assert.NoError(t, err)
+
+		_, err = r.Invoke(ctx, "start", WithCheckPointID("1"))
+		info, ok := ExtractInterruptInfo(err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"2"}, info.SubGraphs["sub"].BeforeNodes)
+		assert.Equal(t, map[string]any{"2": []string{"sub", "2"}}, info.SubGraphs["sub"].BeforeNodesExtra)
+
+		result, err := r.Invoke(ctx, "start", WithCheckPointID("1"))
+		assert.NoError(t, err)
+		assert.Equal(t, "start12", result)
+	})
+}
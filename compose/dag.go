@@ -103,6 +103,21 @@ func (ch *dagChannel) reportDependencies(dependencies []string) {
 	return
 }
 
+func (ch *dagChannel) isWaiting() bool {
+	if ch.Skipped {
+		return false
+	}
+	if len(ch.Values) > 0 {
+		return true
+	}
+	for _, state := range ch.ControlPredecessors {
+		if state == dependencyStateReady {
+			return true
+		}
+	}
+	return false
+}
+
 func (ch *dagChannel) reportSkip(keys []string) bool {
 	for _, k := range keys {
 		if _, ok := ch.ControlPredecessors[k]; ok {
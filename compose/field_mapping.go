@@ -19,8 +19,10 @@ package compose
 import (
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"runtime/debug"
+	"strconv"
 	"strings"
 
 	"github.com/cloudwego/eino/internal/generic"
@@ -34,6 +36,7 @@ type FieldMapping struct {
 	to          string
 
 	customExtractor func(input any) (any, error)
+	coerce          bool
 }
 
 // String returns the string representation of the FieldMapping.
@@ -62,10 +65,14 @@ func (m *FieldMapping) String() string {
 // This is an exclusive mapping - once set, no other field mappings can be added since the successor input
 // has already been fully mapped.
 // Field: either the field of a struct, or the key of a map.
-func FromField(from string) *FieldMapping {
-	return &FieldMapping{
+func FromField(from string, opts ...FieldMappingOption) *FieldMapping {
+	fm := &FieldMapping{
 		from: from,
 	}
+	for _, opt := range opts {
+		opt(fm)
+	}
+	return fm
 }
 
 // ToField creates a FieldMapping that maps the entire predecessor output to a single successor field.
@@ -82,11 +89,15 @@ func ToField(to string, opts ...FieldMappingOption) *FieldMapping {
 
 // MapFields creates a FieldMapping that maps a single predecessor field to a single successor field.
 // Field: either the field of a struct, or the key of a map.
-func MapFields(from, to string) *FieldMapping {
-	return &FieldMapping{
+func MapFields(from, to string, opts ...FieldMappingOption) *FieldMapping {
+	fm := &FieldMapping{
 		from: from,
 		to:   to,
 	}
+	for _, opt := range opts {
+		opt(fm)
+	}
+	return fm
 }
 
 func (m *FieldMapping) FromNodeKey() string {
@@ -110,20 +121,52 @@ func (m *FieldMapping) Equals(o *FieldMapping) bool {
 		return false
 	}
 
-	return m.from == o.from && m.to == o.to && m.fromNodeKey == o.fromNodeKey
+	return m.from == o.from && m.to == o.to && m.fromNodeKey == o.fromNodeKey && m.coerce == o.coerce
 }
 
 // FieldPath represents a path to a nested field in a struct or map.
 // Each element in the path is either:
 // - a struct field name
 // - a map key
+// - a slice or array index, built with FieldPathIndex, only valid in the source path
+// - FieldPathWildcard, projecting the rest of the path over every element of a slice or array, only valid in the source path
 //
 // Example paths:
-//   - []string{"user"}            // top-level field
-//   - []string{"user", "name"}    // nested struct field
-//   - []string{"users", "admin"}  // map key access
+//   - []string{"user"}                          // top-level field
+//   - []string{"user", "name"}                  // nested struct field
+//   - []string{"users", "admin"}                // map key access
+//   - []string{"docs", "[0]", "Content"}        // first element of a slice
+//   - []string{"docs", "[-1]", "Content"}       // last element of a slice
+//   - []string{"results", "[*]", "Score"}       // Score of every element, collected into a slice
+//
+// Index and wildcard elements only take effect when the value at that point is a slice or an array,
+// for a map they are treated as plain keys.
 type FieldPath []string
 
+// FieldPathWildcard is the FieldPath element that projects the remaining path over all elements of a slice or array.
+// The projected values are collected into a slice, e.g. FieldPath{"results", FieldPathWildcard, "Score"} yields []float64
+// if Score is a float64.
+const FieldPathWildcard = "[*]"
+
+// FieldPathIndex returns the FieldPath element that selects the i-th element of a slice or array.
+// Negative indexes count from the end, e.g. FieldPathIndex(-1) selects the last element.
+func FieldPathIndex(i int) string {
+	return "[" + strconv.Itoa(i) + "]"
+}
+
+func parseFieldPathIndex(elem string) (int, bool) {
+	if len(elem) < 3 || elem[0] != '[' || elem[len(elem)-1] != ']' {
+		return 0, false
+	}
+
+	idx, err := strconv.Atoi(elem[1 : len(elem)-1])
+	if err != nil {
+		return 0, false
+	}
+
+	return idx, true
+}
+
 func (fp *FieldPath) join() string {
 	return strings.Join(*fp, pathSeparator)
 }
@@ -151,10 +194,14 @@ const pathSeparator = "\x1F"
 //	FromFieldPath(FieldPath{"user", "profile", "name"})
 //
 // Note: The field path elements must not contain the internal path separator character ('\x1F').
-func FromFieldPath(fromFieldPath FieldPath) *FieldMapping {
-	return &FieldMapping{
+func FromFieldPath(fromFieldPath FieldPath, opts ...FieldMappingOption) *FieldMapping {
+	fm := &FieldMapping{
 		from: fromFieldPath.join(),
 	}
+	for _, opt := range opts {
+		opt(fm)
+	}
+	return fm
 }
 
 // ToFieldPath creates a FieldMapping that maps the entire predecessor output to a single successor field path.
@@ -185,12 +232,22 @@ func ToFieldPath(toFieldPath FieldPath, opts ...FieldMappingOption) *FieldMappin
 //	    FieldPath{"response", "userName"},
 //	)
 //
+//	// Maps the content of the first document to the 'context' field
+//	MapFieldPaths(
+//	    FieldPath{"docs", FieldPathIndex(0), "Content"},
+//	    FieldPath{"context"},
+//	)
+//
 // Note: The field path elements must not contain the internal path separator character ('\x1F').
-func MapFieldPaths(fromFieldPath, toFieldPath FieldPath) *FieldMapping {
-	return &FieldMapping{
+func MapFieldPaths(fromFieldPath, toFieldPath FieldPath, opts ...FieldMappingOption) *FieldMapping {
+	fm := &FieldMapping{
 		from: fromFieldPath.join(),
 		to:   toFieldPath.join(),
 	}
+	for _, opt := range opts {
+		opt(fm)
+	}
+	return fm
 }
 
 // FieldMappingOption is a functional option for configuring a FieldMapping.
@@ -205,6 +262,21 @@ func WithCustomExtractor(extractor func(input any) (any, error)) FieldMappingOpt
 	}
 }
 
+// WithTypeCoercion allows the mapped value to be converted to the successor field type when it is not directly assignable.
+// Supported conversions:
+//   - between numeric types, except from floating point to integer types, e.g. int to float64,
+//     values that don't fit into the target type, e.g. int64(300) to int8 or -1 to uint, fail at request time
+//   - between named types sharing the same basic kind, e.g. string to a string alias
+//   - from a value to a single-element slice, e.g. string to []string
+//   - from a slice or array to a slice, converting each element, e.g. []any to []float64
+//
+// Conversions involving interface types can only be decided at request time.
+func WithTypeCoercion() FieldMappingOption {
+	return func(m *FieldMapping) {
+		m.coerce = true
+	}
+}
+
 func (m *FieldMapping) targetPath() FieldPath {
 	return splitFieldPath(m.to)
 }
@@ -439,7 +511,37 @@ func checkAndExtractFromMapKey(fromMapKey string, input reflect.Value) (reflect.
 	return v, nil
 }
 
-func checkAndExtractFieldType(paths []string, typ reflect.Type) (extracted reflect.Type, remainingPaths FieldPath, err error) {
+type errIndexOutOfRange struct {
+	index  int
+	length int
+}
+
+func (e *errIndexOutOfRange) Error() string {
+	return fmt.Sprintf("index=%d, length=%d", e.index, e.length)
+}
+
+func checkAndExtractFromIndex(elem string, input reflect.Value) (reflect.Value, error) {
+	idx, ok := parseFieldPathIndex(elem)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("field mapping from a slice or array, but path element is not an index. element=%s, inputType=%v", elem, input.Type())
+	}
+
+	realIdx := idx
+	if realIdx < 0 {
+		realIdx += input.Len()
+	}
+
+	if realIdx < 0 || realIdx >= input.Len() {
+		return reflect.Value{}, fmt.Errorf("field mapping from a slice or array, but index out of range. %w", &errIndexOutOfRange{index: idx, length: input.Len()})
+	}
+
+	return input.Index(realIdx), nil
+}
+
+// checkAndExtractFieldType walks the given paths on typ and returns the type found at the end of the paths.
+// If an interface type is met halfway, the type of the interface and the remaining paths are returned, and the check is deferred to request time.
+// When allowIndex is true, which is the case for the source of a field mapping, index and wildcard elements are accepted on slices and arrays.
+func checkAndExtractFieldType(paths []string, typ reflect.Type, allowIndex bool) (extracted reflect.Type, remainingPaths FieldPath, err error) {
 	extracted = typ
 	for i, field := range paths {
 		for extracted.Kind() == reflect.Ptr {
@@ -469,6 +571,34 @@ func checkAndExtractFieldType(paths []string, typ reflect.Type) (extracted refle
 			continue
 		}
 
+		if allowIndex && (extracted.Kind() == reflect.Slice || extracted.Kind() == reflect.Array) {
+			if field == FieldPathWildcard {
+				elemType, elemRemaining, err := checkAndExtractFieldType(paths[i+1:], extracted.Elem(), allowIndex)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				if len(elemRemaining) > 0 {
+					// the projected element type can only be decided at request time
+					return elemType, paths[i:], nil
+				}
+
+				return reflect.SliceOf(elemType), nil, nil
+			}
+
+			idx, ok := parseFieldPathIndex(field)
+			if !ok {
+				return nil, nil, fmt.Errorf("type[%v] is a slice or array, but path element[%s] is not an index", extracted, field)
+			}
+
+			if extracted.Kind() == reflect.Array && (idx >= extracted.Len() || idx < -extracted.Len()) {
+				return nil, nil, fmt.Errorf("type[%v] has no index[%d]", extracted, idx)
+			}
+
+			extracted = extracted.Elem()
+			continue
+		}
+
 		if extracted.Kind() == reflect.Interface {
 			return extracted, paths[i:], nil
 		}
@@ -479,13 +609,21 @@ func checkAndExtractFieldType(paths []string, typ reflect.Type) (extracted refle
 	return extracted, nil, nil
 }
 
+// projectedElemType returns the element type of the slice built by projecting paths over the elements of a slice or array of sliceType.
+func projectedElemType(paths FieldPath, sliceType reflect.Type) reflect.Type {
+	elemType, remaining, err := checkAndExtractFieldType(paths, sliceType.Elem(), true)
+	if err != nil || len(remaining) > 0 {
+		return reflect.TypeOf((*any)(nil)).Elem()
+	}
+	return elemType
+}
+
 var strType = reflect.TypeOf("")
 
 func fieldMap(mappings []*FieldMapping, allowMapKeyNotFound bool, uncheckedSourcePaths map[string]FieldPath) func(any) (map[string]any, error) {
 	return func(input any) (result map[string]any, err error) {
 		result = make(map[string]any, len(mappings))
 		var inputValue reflect.Value
-	loop:
 		for _, mapping := range mappings {
 			if mapping.customExtractor != nil {
 				result[mapping.to], err = mapping.customExtractor(input)
@@ -500,69 +638,129 @@ func fieldMap(mappings []*FieldMapping, allowMapKeyNotFound bool, uncheckedSourc
 				continue
 			}
 
-			fromPath := splitFieldPath(mapping.from)
-
 			if !inputValue.IsValid() {
 				inputValue = reflect.ValueOf(input)
 			}
 
-			var (
-				pathInputValue = inputValue
-				pathInputType  = inputValue.Type()
-				taken          = input
-			)
+			e := &pathExtractor{
+				mapping:             mapping,
+				fromPath:            splitFieldPath(mapping.from),
+				allowMapKeyNotFound: allowMapKeyNotFound,
+			}
+			if uncheckedSourcePaths != nil {
+				e.uncheckedPath = uncheckedSourcePaths[mapping.from]
+			}
 
-			for i, path := range fromPath {
-				for pathInputValue.Kind() == reflect.Ptr {
-					pathInputValue = pathInputValue.Elem()
-				}
+			taken, _, found, err := e.extract(inputValue, inputValue.Type(), 0)
+			if err != nil {
+				return nil, err
+			}
 
-				if !pathInputValue.IsValid() {
-					return nil, fmt.Errorf("intermediate source value on path=%v is nil for type [%v]", fromPath[:i+1], pathInputType)
-				}
+			if !found {
+				continue
+			}
 
-				if pathInputValue.Kind() == reflect.Map && pathInputValue.IsNil() {
-					return nil, fmt.Errorf("intermediate source value on path=%v is nil for map type [%v]", fromPath[:i+1], pathInputType)
-				}
+			result[mapping.to] = taken
+		}
 
-				taken, pathInputType, err = takeOne(pathInputValue, pathInputType, path)
-				if err != nil {
-					// we deferred check from Compile time to request time for interface types, so we won't panic here
-					var interfaceNotValidErr *errInterfaceNotValidForFieldMapping
-					if errors.As(err, &interfaceNotValidErr) {
-						return nil, err
-					}
+		return result, nil
+	}
+}
 
-					// map key not found can only be a request time error, so we won't panic here
-					var mapKeyNotFoundErr *errMapKeyNotFound
-					if errors.As(err, &mapKeyNotFoundErr) {
-						if allowMapKeyNotFound {
-							continue loop
-						}
-						return nil, err
-					}
+// pathExtractor takes the value of a field mapping's source path from the predecessor's output.
+type pathExtractor struct {
+	mapping             *FieldMapping
+	fromPath            FieldPath
+	allowMapKeyNotFound bool
+	// the source path suffix which is not checked at compile time
+	uncheckedPath FieldPath
+}
 
-					if uncheckedSourcePaths != nil {
-						uncheckedPath, ok := uncheckedSourcePaths[mapping.from]
-						if ok && len(uncheckedPath) >= len(fromPath)-i {
-							// the err happens on the mapping source path which is unchecked at request time, so we won't panic here
-							return nil, err
-						}
-					}
+// extract walks e.fromPath from the start-th element on pathInputValue.
+// found is false if a map key or slice index is not found and this is allowed.
+func (e *pathExtractor) extract(pathInputValue reflect.Value, pathInputType reflect.Type, start int) (taken any, takenType reflect.Type, found bool, err error) {
+	for i := start; i < len(e.fromPath); i++ {
+		path := e.fromPath[i]
+		for pathInputValue.Kind() == reflect.Ptr {
+			pathInputValue = pathInputValue.Elem()
+		}
 
-					panic(safe.NewPanicErr(err, debug.Stack()))
-				}
+		if !pathInputValue.IsValid() {
+			return nil, nil, false, fmt.Errorf("intermediate source value on path=%v is nil for type [%v]", e.fromPath[:i+1], pathInputType)
+		}
 
-				if i < len(fromPath)-1 {
-					pathInputValue = reflect.ValueOf(taken)
-				}
+		if pathInputValue.Kind() == reflect.Map && pathInputValue.IsNil() {
+			return nil, nil, false, fmt.Errorf("intermediate source value on path=%v is nil for map type [%v]", e.fromPath[:i+1], pathInputType)
+		}
+
+		if path == FieldPathWildcard && (pathInputValue.Kind() == reflect.Slice || pathInputValue.Kind() == reflect.Array) {
+			return e.project(pathInputValue, i)
+		}
+
+		taken, pathInputType, err = takeOne(pathInputValue, pathInputType, path)
+		if err != nil {
+			found, err = e.handleTakeErr(i, err)
+			return nil, nil, found, err
+		}
+
+		if i < len(e.fromPath)-1 {
+			pathInputValue = reflect.ValueOf(taken)
+		}
+	}
+
+	return taken, pathInputType, true, nil
+}
+
+// project applies the source path after the wildcard at index i to every element of sliceValue, and collects the results into a slice.
+func (e *pathExtractor) project(sliceValue reflect.Value, i int) (taken any, takenType reflect.Type, found bool, err error) {
+	elemType := projectedElemType(e.fromPath[i+1:], sliceValue.Type())
+	projected := reflect.MakeSlice(reflect.SliceOf(elemType), 0, sliceValue.Len())
+	for j := 0; j < sliceValue.Len(); j++ {
+		elem := sliceValue.Index(j)
+
+		var elemTaken any
+		if i == len(e.fromPath)-1 {
+			elemTaken = elem.Interface()
+		} else {
+			elemTaken, _, found, err = e.extract(reflect.ValueOf(elem.Interface()), elem.Type(), i+1)
+			if err != nil || !found {
+				return nil, nil, found, err
 			}
+		}
 
-			result[mapping.to] = taken
+		if elemTaken == nil {
+			projected = reflect.Append(projected, reflect.Zero(elemType))
+		} else {
+			projected = reflect.Append(projected, reflect.ValueOf(elemTaken))
 		}
+	}
 
-		return result, nil
+	return projected.Interface(), projected.Type(), true, nil
+}
+
+func (e *pathExtractor) handleTakeErr(i int, err error) (found bool, _ error) {
+	// we deferred check from Compile time to request time for interface types, so we won't panic here
+	var interfaceNotValidErr *errInterfaceNotValidForFieldMapping
+	if errors.As(err, &interfaceNotValidErr) {
+		return false, err
+	}
+
+	// map key not found and index out of range can only be request time errors, so we won't panic here
+	var mapKeyNotFoundErr *errMapKeyNotFound
+	var indexOutOfRangeErr *errIndexOutOfRange
+	if errors.As(err, &mapKeyNotFoundErr) || errors.As(err, &indexOutOfRangeErr) {
+		if e.allowMapKeyNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if len(e.uncheckedPath) > 0 && len(e.uncheckedPath) >= len(e.fromPath)-i {
+		// the err happens on the mapping source path which is unchecked at request time, so we won't panic here
+		return false, err
 	}
+
+	panic(safe.NewPanicErr(err, debug.Stack()))
 }
 
 func streamFieldMap(mappings []*FieldMapping, uncheckedSourcePaths map[string]FieldPath) func(streamReader) streamReader {
@@ -587,6 +785,13 @@ func takeOne(inputValue reflect.Value, inputType reflect.Type, from string) (tak
 			return nil, nil, err
 		}
 
+		return f.Interface(), f.Type(), nil
+	case reflect.Slice, reflect.Array:
+		f, err = checkAndExtractFromIndex(from, inputValue)
+		if err != nil {
+			return nil, nil, err
+		}
+
 		return f.Interface(), f.Type(), nil
 	default:
 		if inputType.Kind() == reflect.Interface {
@@ -596,7 +801,7 @@ func takeOne(inputValue reflect.Value, inputType reflect.Type, from string) (tak
 			}
 		}
 
-		panic("when take one value from source, value not map or struct, and type not interface")
+		panic("when take one value from source, value not map, struct, slice or array, and type not interface")
 	}
 }
 
@@ -642,6 +847,14 @@ func validateStructOrMap(t reflect.Type) bool {
 	}
 }
 
+func validateSourceContainer(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return validateStructOrMap(t) || t.Kind() == reflect.Slice || t.Kind() == reflect.Array
+}
+
 func validateFieldMapping(predecessorType reflect.Type, successorType reflect.Type, mappings []*FieldMapping) (
 	// type checkers that are deferred to request-time
 	typeHandler *handlerPair,
@@ -655,8 +868,8 @@ func validateFieldMapping(predecessorType reflect.Type, successorType reflect.Ty
 	} else if !isToAll(mappings) && (!validateStructOrMap(successorType) && successorType != reflect.TypeOf((*any)(nil)).Elem()) {
 		// if user has not provided a specific struct type, graph cannot construct any struct in the runtime
 		return nil, nil, fmt.Errorf("static check fail: successor input type should be struct or map, actual: %v", successorType)
-	} else if fromFields(mappings) && !validateStructOrMap(predecessorType) {
-		return nil, nil, fmt.Errorf("static check fail: predecessor output type should be struct or map, actual: %v", predecessorType)
+	} else if fromFields(mappings) && !validateSourceContainer(predecessorType) {
+		return nil, nil, fmt.Errorf("static check fail: predecessor output type should be struct, map, slice or array, actual: %v", predecessorType)
 	}
 
 	var fieldCheckers map[string]handlerPair
@@ -664,7 +877,7 @@ func validateFieldMapping(predecessorType reflect.Type, successorType reflect.Ty
 	for i := range mappings {
 		mapping := mappings[i]
 
-		successorFieldType, successorRemaining, err := checkAndExtractFieldType(splitFieldPath(mapping.to), successorType)
+		successorFieldType, successorRemaining, err := checkAndExtractFieldType(splitFieldPath(mapping.to), successorType, false)
 		if err != nil {
 			return nil, nil, fmt.Errorf("static check failed for mapping %s: %w", mapping, err)
 		}
@@ -680,7 +893,7 @@ func validateFieldMapping(predecessorType reflect.Type, successorType reflect.Ty
 			continue
 		}
 
-		predecessorFieldType, predecessorRemaining, err := checkAndExtractFieldType(splitFieldPath(mapping.from), predecessorType)
+		predecessorFieldType, predecessorRemaining, err := checkAndExtractFieldType(splitFieldPath(mapping.from), predecessorType, true)
 		if err != nil {
 			return nil, nil, fmt.Errorf("static check failed for mapping %s: %w", mapping, err)
 		}
@@ -694,6 +907,14 @@ func validateFieldMapping(predecessorType reflect.Type, successorType reflect.Ty
 
 		checker := func(a any) (any, error) {
 			trueInType := reflect.TypeOf(a)
+			if mapping.coerce && trueInType != nil && !trueInType.AssignableTo(successorFieldType) {
+				coerced, err := coerceValue(reflect.ValueOf(a), successorFieldType)
+				if err != nil {
+					return nil, fmt.Errorf("runtime check failed for mapping %s, %w", mapping, err)
+				}
+				return coerced.Interface(), nil
+			}
+
 			if trueInType == nil {
 				switch successorFieldType.Kind() {
 				case reflect.Map, reflect.Slice, reflect.Ptr, reflect.Interface:
@@ -722,6 +943,14 @@ func validateFieldMapping(predecessorType reflect.Type, successorType reflect.Ty
 			}
 		} else {
 			at := checkAssignable(predecessorFieldType, successorFieldType)
+			if mapping.coerce && at != assignableTypeMust {
+				at = checkCoercible(predecessorFieldType, successorFieldType)
+				if at == assignableTypeMust {
+					// coercion is needed at request time, let the checker do it
+					at = assignableTypeMay
+				}
+			}
+
 			if at == assignableTypeMustNot {
 				return nil, nil, fmt.Errorf("static check failed for mapping %s, field[%v]-[%v] is absolutely not assignable", mapping, predecessorFieldType, successorFieldType)
 			} else if at == assignableTypeMay {
@@ -772,3 +1001,143 @@ func validateFieldMapping(predecessorType reflect.Type, successorType reflect.Ty
 		},
 	}, uncheckedSourcePath, nil
 }
+
+// checkCoercible checks whether a value of type from can be converted to type to by a field mapping with WithTypeCoercion.
+func checkCoercible(from, to reflect.Type) assignableType {
+	if at := checkAssignable(from, to); at != assignableTypeMustNot {
+		return at
+	}
+
+	if from.Kind() == reflect.Interface {
+		// the actual type can only be known at request time
+		return assignableTypeMay
+	}
+
+	if isCoercibleBasic(from, to) {
+		return assignableTypeMust
+	}
+
+	if to.Kind() == reflect.Slice {
+		if from.Kind() == reflect.Slice || from.Kind() == reflect.Array {
+			return checkCoercible(from.Elem(), to.Elem())
+		}
+		return checkCoercible(from, to.Elem())
+	}
+
+	return assignableTypeMustNot
+}
+
+// coerceValue converts v to type to following the rules of checkCoercible.
+func coerceValue(v reflect.Value, to reflect.Type) (reflect.Value, error) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		switch to.Kind() {
+		case reflect.Map, reflect.Slice, reflect.Ptr, reflect.Interface:
+			return reflect.Zero(to), nil
+		default:
+			return reflect.Value{}, fmt.Errorf("field[nil]-[%v] is not coercible", to)
+		}
+	}
+
+	from := v.Type()
+	if from.AssignableTo(to) {
+		if from == to {
+			return v, nil
+		}
+		converted := reflect.New(to).Elem()
+		converted.Set(v)
+		return converted, nil
+	}
+
+	if isCoercibleBasic(from, to) {
+		if isNumericKind(from.Kind()) && !numericFits(v, to) {
+			return reflect.Value{}, fmt.Errorf("field[%v]-[%v] is not coercible, value %v out of range", from, to, v)
+		}
+		return v.Convert(to), nil
+	}
+
+	if to.Kind() == reflect.Slice {
+		if from.Kind() == reflect.Slice || from.Kind() == reflect.Array {
+			converted := reflect.MakeSlice(to, v.Len(), v.Len())
+			for i := 0; i < v.Len(); i++ {
+				elem, err := coerceValue(v.Index(i), to.Elem())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				converted.Index(i).Set(elem)
+			}
+			return converted, nil
+		}
+
+		elem, err := coerceValue(v, to.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		converted := reflect.MakeSlice(to, 1, 1)
+		converted.Index(0).Set(elem)
+		return converted, nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("field[%v]-[%v] is not coercible", from, to)
+}
+
+func isCoercibleBasic(from, to reflect.Type) bool {
+	if isNumericKind(from.Kind()) && isNumericKind(to.Kind()) {
+		// converting floating point numbers to integers silently loses precision
+		return !isFloatKind(from.Kind()) || isFloatKind(to.Kind())
+	}
+
+	switch from.Kind() {
+	case reflect.String, reflect.Bool:
+		return from.Kind() == to.Kind()
+	default:
+		return false
+	}
+}
+
+// numericFits reports whether the numeric value v can be converted to the numeric type to without wrapping around or overflowing.
+func numericFits(v reflect.Value, to reflect.Type) bool {
+	target := reflect.Zero(to)
+	switch {
+	case isIntKind(v.Kind()):
+		i := v.Int()
+		switch {
+		case isIntKind(to.Kind()):
+			return !target.OverflowInt(i)
+		case isUintKind(to.Kind()):
+			return i >= 0 && !target.OverflowUint(uint64(i))
+		}
+	case isUintKind(v.Kind()):
+		u := v.Uint()
+		switch {
+		case isIntKind(to.Kind()):
+			return u <= math.MaxInt64 && !target.OverflowInt(int64(u))
+		case isUintKind(to.Kind()):
+			return !target.OverflowUint(u)
+		}
+	case isFloatKind(v.Kind()) && isFloatKind(to.Kind()):
+		f := v.Float()
+		return math.IsNaN(f) || math.IsInf(f, 0) || !target.OverflowFloat(f)
+	}
+
+	return true
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	return (k >= reflect.Int && k <= reflect.Uint64) || isFloatKind(k)
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
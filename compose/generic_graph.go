@@ -150,5 +150,7 @@ func compileAnyGraph[I, O any](ctx context.Context, g AnyGraph, opts ...GraphCom
 		return nil, err
 	}
 
+	interceptedRunnable(rp, option.graphName, option.interceptors)
+
 	return rp, nil
 }
@@ -698,7 +698,7 @@ func (g *graph) compile(ctx context.Context, opt *graphCompileOptions) (*composa
 	for name, node := range g.nodes {
 		node.beforeChildGraphCompile(name, key2SubGraphs)
 
-		r, err := node.compileIfNeeded(ctx)
+		r, err := node.compileIfNeeded(ctx, name)
 		if err != nil {
 			return nil, err
 		}
@@ -835,7 +835,26 @@ func (g *graph) compile(ctx context.Context, opt *graphCompileOptions) (*composa
 
 		r.interruptBeforeNodes = opt.interruptBeforeNodes
 		r.interruptAfterNodes = opt.interruptAfterNodes
+		r.interruptBeforeConditions = opt.interruptBeforeConditions
+		r.interruptAfterConditions = opt.interruptAfterConditions
 		r.options = *opt
+
+		if opt.threadStateStore != nil {
+			if g.stateGenerator == nil {
+				return nil, errors.New("thread state store is set but graph has no state, please use WithGenLocalState")
+			}
+			if m := opt.threadStateMerger; m != nil {
+				if m.inputType != g.inputType() {
+					return nil, fmt.Errorf("thread state merger's input type[%v] is different from graph[%v]", m.inputType, g.inputType())
+				}
+				if m.stateType != g.stateType {
+					return nil, fmt.Errorf("thread state merger's state type[%v] is different from graph[%v]", m.stateType, g.stateType)
+				}
+			}
+			r.threadStater = newThreadStater(opt.threadStateStore, r.checkPointer.serializer, opt.threadStateMerger)
+		} else if opt.threadStateMerger != nil {
+			return nil, errors.New("thread state merger is set but thread state store is not set")
+		}
 	}
 
 	// default options
@@ -44,6 +44,8 @@ type nodeOptions struct {
 	outputKey string
 
 	graphCompileOption []GraphCompileOption // when this node is itself an AnyGraph, this option will be used to compile the node as a nested graph
+
+	interceptors []Interceptor
 }
 
 // WithNodeName sets the name of the node.
@@ -88,6 +88,11 @@ type Option struct {
 	writeToCheckPointID *string
 	forceNewRun         bool
 	stateModifier       StateModifier
+
+	threadID *string
+	runID    *string
+
+	interceptors []Interceptor
 }
 
 func (o Option) deepCopy() Option {
@@ -101,10 +106,11 @@ func (o Option) deepCopy() Option {
 		nPaths[i] = &nPath
 	}
 	return Option{
-		options:     nOptions,
-		handler:     nHandler,
-		paths:       nPaths,
-		maxRunSteps: o.maxRunSteps,
+		options:      nOptions,
+		handler:      nHandler,
+		paths:        nPaths,
+		maxRunSteps:  o.maxRunSteps,
+		interceptors: o.interceptors,
 	}
 }
 
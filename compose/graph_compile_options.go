@@ -30,6 +30,16 @@ type graphCompileOptions struct {
 	interruptBeforeNodes []string
 	interruptAfterNodes  []string
 
+	interruptBeforeConditions []*interruptCondition
+	interruptAfterConditions  []*interruptCondition
+
+	interceptors []Interceptor
+
+	threadStateStore  ThreadStateStore
+	threadStateMerger *threadStateMerger
+
+	runRegistry *RunRegistry
+
 	eagerDisabled bool
 
 	mergeConfigs map[string]FanInMergeConfig
@@ -33,6 +33,8 @@ type channel interface {
 	get(bool, string, *edgeHandlerManager) (any, bool, error)
 	convertValues(fn func(map[string]any) error) error
 	load(channel) error
+	// isWaiting reports whether the channel has received part of the inputs and is waiting for the others.
+	isWaiting() bool
 
 	setMergeConfig(FanInMergeConfig)
 }
@@ -53,6 +53,8 @@ type nodeInfo struct {
 
 	preProcessor, postProcessor *composableRunnable
 
+	interceptors []Interceptor
+
 	compileOption *graphCompileOptions // if the node is an AnyGraph, it will need compile options of its own
 }
 
@@ -118,7 +120,7 @@ func (gn *graphNode) outputType() reflect.Type {
 	return nil
 }
 
-func (gn *graphNode) compileIfNeeded(ctx context.Context) (*composableRunnable, error) {
+func (gn *graphNode) compileIfNeeded(ctx context.Context, key string) (*composableRunnable, error) {
 	var r *composableRunnable
 	if gn.g != nil {
 		cr, err := gn.g.compile(ctx, gn.nodeInfo.compileOption)
@@ -137,6 +139,9 @@ func (gn *graphNode) compileIfNeeded(ctx context.Context) (*composableRunnable,
 	r.meta = gn.executorMeta
 	r.nodeInfo = gn.nodeInfo
 
+	// always wrapped, because interceptors can also be designated to the node at call time
+	r = interceptedComposableRunnable(key, r, gn.nodeInfo.interceptors)
+
 	if gn.nodeInfo.outputKey != "" {
 		r = outputKeyedComposableRunnable(gn.nodeInfo.outputKey, r)
 	}
@@ -172,6 +177,7 @@ func getNodeInfo(opts ...GraphAddNodeOpt) (*nodeInfo, *graphAddNodeOpts) {
 		outputKey:     opt.nodeOptions.outputKey,
 		preProcessor:  opt.processor.statePreHandler,
 		postProcessor: opt.processor.statePostHandler,
+		interceptors:  opt.nodeOptions.interceptors,
 		compileOption: newGraphCompileOptions(opt.nodeOptions.graphCompileOption...),
 	}, opt
 }
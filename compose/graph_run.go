@@ -24,6 +24,7 @@ import (
 	"strings"
 
 	"github.com/cloudwego/eino/internal"
+	"github.com/cloudwego/eino/schema"
 )
 
 type chanCall struct {
@@ -78,6 +79,11 @@ type runner struct {
 	interruptBeforeNodes []string
 	interruptAfterNodes  []string
 
+	interruptBeforeConditions []*interruptCondition
+	interruptAfterConditions  []*interruptCondition
+
+	threadStater *threadStater
+
 	mergeConfigs map[string]FanInMergeConfig
 }
 
@@ -120,6 +126,19 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 		runWrapper = runnableTransform
 	}
 
+	// Extract subgraph
+	path, isSubGraph := getNodeKey(ctx)
+
+	// Track the run, only the top level graph is tracked
+	var tracker *runTracker
+	if r.options.runRegistry != nil && !isSubGraph {
+		ctx, tracker, err = r.options.runRegistry.register(ctx, getRunID(opts...), r.options.graphName)
+		if err != nil {
+			return nil, newGraphRunError(fmt.Errorf("register run fail: %w", err))
+		}
+		defer r.options.runRegistry.unregister(tracker)
+	}
+
 	// Initialize channel and task managers.
 	cm := r.initChannelManager(isStream)
 	tm := r.initTaskManager(runWrapper, getGraphCancel(ctx), opts...)
@@ -155,8 +174,26 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 		return nil, newGraphRunError(fmt.Errorf("receive checkpoint id but have not set checkpoint store"))
 	}
 
-	// Extract subgraph
-	path, isSubGraph := getNodeKey(ctx)
+	// Extract thread id, only the top level graph loads and saves thread state
+	threadID := getThreadID(opts...)
+	if isSubGraph {
+		threadID = nil
+	}
+	if threadID != nil && r.threadStater == nil {
+		return nil, newGraphRunError(fmt.Errorf("receive thread id but have not set thread state store"))
+	}
+	var threadStateVersion int64
+	var cpThreadStateVersion *int64 // the version loaded by the interrupted run, nil if the checkpoint has no thread state
+	if threadID != nil {
+		defer func() {
+			if err != nil {
+				return
+			}
+			if sErr := r.threadStater.save(ctx, *threadID, threadStateVersion); sErr != nil {
+				result, err = nil, newGraphRunError(fmt.Errorf("save thread state fail: %w", sErr))
+			}
+		}()
+	}
 
 	// load checkpoint from ctx/store or init graph
 	initialized := false
@@ -178,14 +215,43 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 			ctx = setCheckPointToCtx(ctx, cp)
 
 			ctx, nextTasks, err = r.restoreFromCheckPoint(ctx, *NewNodePath(), stateModifier, cp, isStream, cm, optMap)
+			cpThreadStateVersion = cp.ThreadStateVersion
 		}
 	}
+	if initialized && threadID != nil {
+		// state has been restored from checkpoint, only record the version to save,
+		// which is the one loaded by the interrupted run, so that the writes in the meantime are detected
+		if cpThreadStateVersion != nil {
+			threadStateVersion = *cpThreadStateVersion
+		} else {
+			var loadErr error
+			threadStateVersion, loadErr = r.threadStater.load(ctx, *threadID, false)
+			if loadErr != nil {
+				return nil, newGraphRunError(fmt.Errorf("load thread state fail: %w", loadErr))
+			}
+		}
+	}
+	var interruptThreadStateVersion *int64
+	if threadID != nil {
+		interruptThreadStateVersion = &threadStateVersion
+	}
 	if !initialized {
 		// have not inited from checkpoint
 		if r.runCtx != nil {
 			ctx = r.runCtx(ctx)
 		}
 
+		if threadID != nil {
+			threadStateVersion, err = r.threadStater.load(ctx, *threadID, true)
+			if err != nil {
+				return nil, newGraphRunError(fmt.Errorf("load thread state fail: %w", err))
+			}
+			input, err = r.threadStater.mergeInput(ctx, input, isStream, r.inputStreamConvertPair)
+			if err != nil {
+				return nil, newGraphRunError(fmt.Errorf("merge input into thread state fail: %w", err))
+			}
+		}
+
 		var isEnd bool
 		nextTasks, result, isEnd, err = r.calculateNextTasks(ctx, []*task{{
 			nodeKey: START,
@@ -202,9 +268,11 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 			return nil, newGraphRunError(fmt.Errorf("no tasks to execute after graph start"))
 		}
 
-		if keys := getHitKey(nextTasks, r.interruptBeforeNodes); len(keys) > 0 {
-			tempInfo := newInterruptTempInfo()
-			tempInfo.interruptBeforeNodes = append(tempInfo.interruptBeforeNodes, keys...)
+		tempInfo := newInterruptTempInfo()
+		if err = r.resolveInterruptBeforeTasks(ctx, tempInfo, nextTasks, isStream); err != nil {
+			return nil, err // err has been wrapped
+		}
+		if len(tempInfo.interruptBeforeNodes) > 0 {
 			return nil, r.handleInterrupt(ctx,
 				tempInfo,
 				nextTasks,
@@ -212,10 +280,13 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 				isStream,
 				isSubGraph,
 				writeToCheckPointID,
+				interruptThreadStateVersion,
 			)
 		}
 	}
 
+	tracker.setState(ctx)
+
 	// used to reporting NoTask error
 	var lastCompletedTask []*task
 
@@ -236,6 +307,9 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 		// 2. get completed tasks
 		// 3. calculate next tasks
 
+		if tracker != nil {
+			tracker.onSubmit(step, nextTasks, waitingNodes(cm))
+		}
 		err = tm.submit(nextTasks)
 		if err != nil {
 			return nil, newGraphRunError(fmt.Errorf("failed to submit tasks: %w", err))
@@ -244,6 +318,7 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 		var totalCanceledTasks []*task
 
 		completedTasks, canceled, canceledTasks := tm.wait()
+		tracker.onComplete(completedTasks)
 		totalCanceledTasks = append(totalCanceledTasks, canceledTasks...)
 		tempInfo := newInterruptTempInfo()
 		if canceled {
@@ -260,7 +335,7 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 			}
 		}
 
-		err = r.resolveInterruptCompletedTasks(tempInfo, completedTasks)
+		err = r.resolveInterruptCompletedTasks(ctx, tempInfo, completedTasks, isStream)
 		if err != nil {
 			return nil, err // err has been wrapped
 		}
@@ -274,7 +349,7 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 				tempInfo.interruptRerunNodes = append(tempInfo.interruptRerunNodes, ct.nodeKey)
 			}
 
-			err = r.resolveInterruptCompletedTasks(tempInfo, newCompletedTasks)
+			err = r.resolveInterruptCompletedTasks(ctx, tempInfo, newCompletedTasks, isStream)
 			if err != nil {
 				return nil, err // err has been wrapped
 			}
@@ -291,6 +366,7 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 				isSubGraph,
 				cm,
 				isStream,
+				interruptThreadStateVersion,
 			)
 		}
 
@@ -308,7 +384,10 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 			return result, nil
 		}
 
-		tempInfo.interruptBeforeNodes = getHitKey(nextTasks, r.interruptBeforeNodes)
+		err = r.resolveInterruptBeforeTasks(ctx, tempInfo, nextTasks, isStream)
+		if err != nil {
+			return nil, err // err has been wrapped
+		}
 
 		if len(tempInfo.interruptBeforeNodes) > 0 || len(tempInfo.interruptAfterNodes) > 0 {
 			var newCompletedTasks []*task
@@ -318,7 +397,7 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 				tempInfo.interruptRerunNodes = append(tempInfo.interruptRerunNodes, ct.nodeKey)
 			}
 
-			err = r.resolveInterruptCompletedTasks(tempInfo, newCompletedTasks)
+			err = r.resolveInterruptCompletedTasks(ctx, tempInfo, newCompletedTasks, isStream)
 			if err != nil {
 				return nil, err // err has been wrapped
 			}
@@ -332,6 +411,7 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 					isSubGraph,
 					cm,
 					isStream,
+					interruptThreadStateVersion,
 				)
 			}
 
@@ -345,10 +425,14 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 				return result, nil
 			}
 
-			tempInfo.interruptBeforeNodes = append(tempInfo.interruptBeforeNodes, getHitKey(newNextTasks, r.interruptBeforeNodes)...)
+			err = r.resolveInterruptBeforeTasks(ctx, tempInfo, newNextTasks, isStream)
+			if err != nil {
+				return nil, err // err has been wrapped
+			}
 
 			// simple interrupt
-			return nil, r.handleInterrupt(ctx, tempInfo, append(nextTasks, newNextTasks...), cm.channels, isStream, isSubGraph, writeToCheckPointID)
+			return nil, r.handleInterrupt(ctx, tempInfo, append(nextTasks, newNextTasks...), cm.channels, isStream, isSubGraph, writeToCheckPointID,
+				interruptThreadStateVersion)
 		}
 	}
 }
@@ -381,7 +465,7 @@ func (r *runner) restoreFromCheckPoint(
 		ctx = context.WithValue(ctx, stateKey{}, &internalState{state: cp.State})
 	}
 
-	nextTasks, err := r.restoreTasks(ctx, cp.Inputs, cp.SkipPreHandler, cp.ToolsNodeExecutedTools, cp.RerunNodes, isStream, optMap) // should restore after set state to context
+	nextTasks, err := r.restoreTasks(ctx, cp.Inputs, cp.SkipPreHandler, cp.ToolsNodeExecutedTools, cp.ToolsNodeExecutedToolParts, cp.RerunNodes, isStream, optMap) // should restore after set state to context
 	if err != nil {
 		return ctx, nil, newGraphRunError(fmt.Errorf("restore tasks fail: %w", err))
 	}
@@ -393,6 +477,7 @@ func newInterruptTempInfo() *interruptTempInfo {
 		subGraphInterrupts:     map[string]*subGraphInterruptError{},
 		interruptRerunExtra:    map[string]any{},
 		interruptExecutedTools: make(map[string]map[string]string),
+		interruptExecutedParts: make(map[string]map[string][]schema.MessageInputPart),
 	}
 }
 
@@ -403,9 +488,12 @@ type interruptTempInfo struct {
 	interruptAfterNodes    []string
 	interruptRerunExtra    map[string]any
 	interruptExecutedTools map[string]map[string]string
+	interruptExecutedParts map[string]map[string][]schema.MessageInputPart
+	interruptBeforeExtra   map[string]any
+	interruptAfterExtra    map[string]any
 }
 
-func (r *runner) resolveInterruptCompletedTasks(tempInfo *interruptTempInfo, completedTasks []*task) (err error) {
+func (r *runner) resolveInterruptCompletedTasks(ctx context.Context, tempInfo *interruptTempInfo, completedTasks []*task, isStream bool) (err error) {
 	for _, completedTask := range completedTasks {
 		if completedTask.err != nil {
 			if info := isSubGraphInterrupt(completedTask.err); info != nil {
@@ -422,6 +510,9 @@ func (r *runner) resolveInterruptCompletedTasks(tempInfo *interruptTempInfo, com
 					if completedTask.call.action.meta.component == ComponentOfToolsNode {
 						if e, ok := extra.(*ToolsInterruptAndRerunExtra); ok {
 							tempInfo.interruptExecutedTools[completedTask.nodeKey] = e.ExecutedTools
+							if len(e.ExecutedToolParts) > 0 {
+								tempInfo.interruptExecutedParts[completedTask.nodeKey] = e.ExecutedToolParts
+							}
 						}
 					}
 				}
@@ -430,16 +521,135 @@ func (r *runner) resolveInterruptCompletedTasks(tempInfo *interruptTempInfo, com
 			return wrapGraphNodeError(completedTask.nodeKey, completedTask.err)
 		}
 
+		hit := false
 		for _, key := range r.interruptAfterNodes {
 			if key == completedTask.nodeKey {
 				tempInfo.interruptAfterNodes = append(tempInfo.interruptAfterNodes, key)
+				hit = true
+				break
+			}
+		}
+		if hit || len(r.interruptAfterConditions) == 0 {
+			continue
+		}
+
+		hit, payload, err := r.evalInterruptConditions(ctx, r.interruptAfterConditions, completedTask, &completedTask.output, r.checkPointer.sc.outputPairs, isStream)
+		if err != nil {
+			return newGraphRunError(fmt.Errorf("interrupt after node[%s] condition fail: %w", completedTask.nodeKey, err))
+		}
+		if hit {
+			tempInfo.interruptAfterNodes = append(tempInfo.interruptAfterNodes, completedTask.nodeKey)
+			if payload != nil {
+				if tempInfo.interruptAfterExtra == nil {
+					tempInfo.interruptAfterExtra = map[string]any{}
+				}
+				tempInfo.interruptAfterExtra[completedTask.nodeKey] = payload
+			}
+		}
+	}
+	return nil
+}
+
+func (r *runner) resolveInterruptBeforeTasks(ctx context.Context, tempInfo *interruptTempInfo, nextTasks []*task, isStream bool) error {
+	keys := getHitKey(nextTasks, r.interruptBeforeNodes)
+	tempInfo.interruptBeforeNodes = append(tempInfo.interruptBeforeNodes, keys...)
+	if len(r.interruptBeforeConditions) == 0 {
+		return nil
+	}
+
+	for _, t := range nextTasks {
+		hit := false
+		for _, key := range keys {
+			if key == t.nodeKey {
+				hit = true
 				break
 			}
 		}
+		if hit {
+			continue
+		}
+
+		hit, payload, err := r.evalInterruptConditions(ctx, r.interruptBeforeConditions, t, &t.input, r.checkPointer.sc.inputPairs, isStream)
+		if err != nil {
+			return newGraphRunError(fmt.Errorf("interrupt before node[%s] condition fail: %w", t.nodeKey, err))
+		}
+		if hit {
+			tempInfo.interruptBeforeNodes = append(tempInfo.interruptBeforeNodes, t.nodeKey)
+			if payload != nil {
+				if tempInfo.interruptBeforeExtra == nil {
+					tempInfo.interruptBeforeExtra = map[string]any{}
+				}
+				tempInfo.interruptBeforeExtra[t.nodeKey] = payload
+			}
+		}
 	}
 	return nil
 }
 
+// evalInterruptConditions calls the conditions matching the task's node with *value, which is the input or output of the task.
+// In stream mode, *value is concatenated for the conditions and replaced by a restored stream.
+func (r *runner) evalInterruptConditions(ctx context.Context, conds []*interruptCondition, t *task, value *any,
+	pairs map[string]streamConvertPair, isStream bool) (bool, any, error) {
+	var matched []*interruptCondition
+	for _, c := range conds {
+		if c.match(t.nodeKey) {
+			matched = append(matched, c)
+		}
+	}
+	if len(matched) == 0 {
+		return false, nil, nil
+	}
+
+	v := *value
+	if isStream {
+		pair, ok := pairs[t.nodeKey]
+		if !ok {
+			return false, nil, fmt.Errorf("stream convert pair of node[%s] not found", t.nodeKey)
+		}
+		sr, ok := v.(streamReader)
+		if !ok {
+			return false, nil, fmt.Errorf("value of node[%s] isn't stream", t.nodeKey)
+		}
+
+		var err error
+		v, err = pair.concatStream(sr)
+		if err != nil {
+			return false, nil, err
+		}
+		*value, err = pair.restoreStream(v)
+		if err != nil {
+			return false, nil, err
+		}
+	}
+
+	in := &InterruptConditionInput{
+		NodeKey: t.nodeKey,
+		Value:   v,
+	}
+	if path, ok := getNodeKey(t.ctx); ok {
+		in.Path = *path
+	}
+
+	if r.runCtx != nil {
+		if state, ok := ctx.Value(stateKey{}).(*internalState); ok {
+			state.mu.Lock()
+			defer state.mu.Unlock()
+			in.State = state.state
+		}
+	}
+
+	for _, c := range matched {
+		hit, payload, err := c.cond(t.ctx, in)
+		if err != nil {
+			return false, nil, err
+		}
+		if hit {
+			return true, payload, nil
+		}
+	}
+	return false, nil, nil
+}
+
 func getHitKey(tasks []*task, keys []string) []string {
 	var ret []string
 	for _, t := range tasks {
@@ -460,11 +670,13 @@ func (r *runner) handleInterrupt(
 	isStream bool,
 	isSubGraph bool,
 	checkPointID *string,
+	threadStateVersion *int64,
 ) error {
 	cp := &checkpoint{
-		Channels:       channels,
-		Inputs:         make(map[string]any),
-		SkipPreHandler: map[string]bool{},
+		Channels:           channels,
+		Inputs:             make(map[string]any),
+		SkipPreHandler:     map[string]bool{},
+		ThreadStateVersion: threadStateVersion,
 	}
 	if r.runCtx != nil {
 		// current graph has enable state
@@ -479,6 +691,9 @@ func (r *runner) handleInterrupt(
 		RerunNodes:      tempInfo.interruptRerunNodes,
 		RerunNodesExtra: tempInfo.interruptRerunExtra,
 		SubGraphs:       make(map[string]*InterruptInfo),
+
+		BeforeNodesExtra: tempInfo.interruptBeforeExtra,
+		AfterNodesExtra:  tempInfo.interruptAfterExtra,
 	}
 	for _, t := range nextTasks {
 		cp.Inputs[t.nodeKey] = t.input
@@ -509,6 +724,7 @@ func (r *runner) handleInterruptWithSubGraphAndRerunNodes(
 	isSubGraph bool,
 	cm *channelManager,
 	isStream bool,
+	threadStateVersion *int64,
 ) error {
 	var rerunTasks, subgraphTasks, otherTasks []*task
 	skipPreHandler := map[string]bool{}
@@ -551,6 +767,9 @@ func (r *runner) handleInterruptWithSubGraphAndRerunNodes(
 		SkipPreHandler:         skipPreHandler,
 		ToolsNodeExecutedTools: tempInfo.interruptExecutedTools,
 		SubGraphs:              make(map[string]*checkpoint),
+
+		ToolsNodeExecutedToolParts: tempInfo.interruptExecutedParts,
+		ThreadStateVersion:         threadStateVersion,
 	}
 	if r.runCtx != nil {
 		// current graph has enable state
@@ -566,6 +785,9 @@ func (r *runner) handleInterruptWithSubGraphAndRerunNodes(
 		RerunNodes:      tempInfo.interruptRerunNodes,
 		RerunNodesExtra: tempInfo.interruptRerunExtra,
 		SubGraphs:       make(map[string]*InterruptInfo),
+
+		BeforeNodesExtra: tempInfo.interruptBeforeExtra,
+		AfterNodesExtra:  tempInfo.interruptAfterExtra,
 	}
 	for _, t := range subgraphTasks {
 		cp.RerunNodes = append(cp.RerunNodes, t.nodeKey)
@@ -665,6 +887,7 @@ func (r *runner) restoreTasks(
 	inputs map[string]any,
 	skipPreHandler map[string]bool,
 	toolNodeExecutedTools map[string]map[string]string,
+	toolNodeExecutedParts map[string]map[string][]schema.MessageInputPart,
 	rerunNodes []string,
 	isStream bool,
 	optMap map[string][]any) ([]*task, error) {
@@ -703,7 +926,7 @@ func (r *runner) restoreTasks(
 			newTask.option = opt
 		}
 		if executedTools, ok := toolNodeExecutedTools[key]; ok {
-			newTask.option = append(newTask.option, withExecutedTools(executedTools))
+			newTask.option = append(newTask.option, withExecutedTools(executedTools, toolNodeExecutedParts[key]))
 		}
 
 		ret = append(ret, newTask)
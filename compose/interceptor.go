@@ -0,0 +1,419 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// RunMode is the data flow pattern a Runnable or a graph node is executed with.
+type RunMode string
+
+const (
+	RunModeInvoke    RunMode = "Invoke"
+	RunModeStream    RunMode = "Stream"
+	RunModeCollect   RunMode = "Collect"
+	RunModeTransform RunMode = "Transform"
+)
+
+// IsInputStream reports whether the input is a stream in this mode, i.e. Collect and Transform.
+func (m RunMode) IsInputStream() bool {
+	return m == RunModeCollect || m == RunModeTransform
+}
+
+// IsOutputStream reports whether the output is a stream in this mode, i.e. Stream and Transform.
+func (m RunMode) IsOutputStream() bool {
+	return m == RunModeStream || m == RunModeTransform
+}
+
+// InterceptorInfo describes the execution being intercepted.
+type InterceptorInfo struct {
+	// NodeKey is the key of the intercepted node, empty when a compiled Runnable is intercepted.
+	NodeKey string
+	// Name is the node name set by WithNodeName, or the graph name set by WithGraphName for a compiled Runnable.
+	Name string
+	// Mode is the data flow pattern of the execution.
+	// A compiled Runnable can be intercepted in all the four modes,
+	// while graph nodes are executed in RunModeInvoke when the graph is invoked, and in RunModeTransform otherwise.
+	Mode RunMode
+
+	InputType  reflect.Type
+	OutputType reflect.Type
+}
+
+// InterceptorInput is the input of an execution seen by an Interceptor.
+type InterceptorInput struct {
+	// Value is the input when Mode.IsInputStream() is false.
+	Value any
+	// Stream is the input when Mode.IsInputStream() is true.
+	Stream *schema.StreamReader[any]
+	// Options are the call options, which are compose.Option for a compiled Runnable or a subgraph node,
+	// and the component options such as model.Option for other nodes.
+	Options []any
+}
+
+// InterceptorOutput is the output of an execution seen by an Interceptor.
+// Either Value or Stream should be set, if the one not matching the mode is set, it is converted automatically.
+type InterceptorOutput struct {
+	Value  any
+	Stream *schema.StreamReader[any]
+}
+
+// InterceptorHandler executes the remaining interceptors and the intercepted Runnable or node.
+type InterceptorHandler func(ctx context.Context, in *InterceptorInput) (*InterceptorOutput, error)
+
+// Interceptor is an around-style middleware of a compiled Runnable or a graph node.
+// It can modify the context, input and options before calling next, short-circuit by returning without calling next,
+// and modify or wrap the output returned by next.
+// e.g.
+//
+//	auth := func(ctx context.Context, info *compose.InterceptorInfo, in *compose.InterceptorInput, next compose.InterceptorHandler) (*compose.InterceptorOutput, error) {
+//		if !isAuthorized(ctx) {
+//			return nil, errors.New("unauthorized")
+//		}
+//		return next(ctx, in)
+//	}
+//
+//	runnable, err := graph.Compile(ctx, compose.WithGraphInterceptors(auth))
+type Interceptor func(ctx context.Context, info *InterceptorInfo, in *InterceptorInput, next InterceptorHandler) (*InterceptorOutput, error)
+
+// WithGraphInterceptors adds interceptors to the Runnable compiled from the graph, they are executed in the order given.
+// Only takes effect on the top level graph, for a subgraph use WithNodeInterceptors when adding it as a node.
+func WithGraphInterceptors(interceptors ...Interceptor) GraphCompileOption {
+	return func(o *graphCompileOptions) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+// WithNodeInterceptors adds interceptors to the node, they are executed in the order given.
+// The interceptors wrap the component itself, so they see the input after WithInputKey and StatePreHandler are applied.
+func WithNodeInterceptors(interceptors ...Interceptor) GraphAddNodeOpt {
+	return func(o *graphAddNodeOpts) {
+		o.nodeOptions.interceptors = append(o.nodeOptions.interceptors, interceptors...)
+	}
+}
+
+// WithCallInterceptors adds interceptors for a single call, they are executed after the interceptors added at compile time.
+// By default, they intercept the compiled Runnable, use DesignateNode or DesignateNodeWithPath to intercept nodes instead.
+// e.g.
+//
+//	runnable.Invoke(ctx, input, compose.WithCallInterceptors(quota).DesignateNode("chat_model"))
+func WithCallInterceptors(interceptors ...Interceptor) Option {
+	return Option{
+		interceptors: interceptors,
+	}
+}
+
+// nodeCallInterceptors carries the call interceptors designated to a node among the node's options.
+type nodeCallInterceptors struct {
+	interceptors []Interceptor
+}
+
+func runInterceptors(ctx context.Context, interceptors []Interceptor, info *InterceptorInfo, in *InterceptorInput,
+	endpoint InterceptorHandler) (*InterceptorOutput, error) {
+	handler := endpoint
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, in *InterceptorInput) (*InterceptorOutput, error) {
+			return interceptor(ctx, info, in, next)
+		}
+	}
+
+	out, err := handler(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	if out == nil {
+		return nil, errors.New("interceptor returns nil output")
+	}
+	return out, nil
+}
+
+func getCallInterceptors(interceptors []Interceptor, opts []Option) []Interceptor {
+	ret := interceptors
+	for _, opt := range opts {
+		if len(opt.paths) == 0 && len(opt.interceptors) > 0 {
+			ret = append(ret[:len(ret):len(ret)], opt.interceptors...)
+		}
+	}
+	return ret
+}
+
+func splitNodeCallInterceptors(interceptors []Interceptor, opts []any) ([]Interceptor, []any) {
+	var nOpts []any
+	for i, opt := range opts {
+		nci, ok := opt.(*nodeCallInterceptors)
+		if !ok {
+			if nOpts != nil {
+				nOpts = append(nOpts, opt)
+			}
+			continue
+		}
+
+		if nOpts == nil {
+			nOpts = make([]any, i, len(opts))
+			copy(nOpts, opts[:i])
+		}
+		interceptors = append(interceptors[:len(interceptors):len(interceptors)], nci.interceptors...)
+	}
+
+	if nOpts == nil {
+		return interceptors, opts
+	}
+	return interceptors, nOpts
+}
+
+func interceptedValue[T any](v any) (T, error) {
+	if v == nil {
+		var t T
+		return t, nil
+	}
+	t, ok := v.(T)
+	if !ok {
+		return t, fmt.Errorf("intercepted value type mismatch, expected: %T, actual: %T", t, v)
+	}
+	return t, nil
+}
+
+func interceptedStream[T any](sr *schema.StreamReader[any]) *schema.StreamReader[T] {
+	return schema.StreamReaderWithConvert(sr, interceptedValue[T])
+}
+
+func toAnyStream[T any](sr *schema.StreamReader[T]) *schema.StreamReader[any] {
+	return schema.StreamReaderWithConvert(sr, func(t T) (any, error) {
+		return t, nil
+	})
+}
+
+func interceptedOutputValue[T any](out *InterceptorOutput) (T, error) {
+	if out.Value == nil && out.Stream != nil {
+		return defaultImplConcatStreamReader(interceptedStream[T](out.Stream))
+	}
+	return interceptedValue[T](out.Value)
+}
+
+func interceptedOutputStream[T any](out *InterceptorOutput) (*schema.StreamReader[T], error) {
+	if out.Stream != nil {
+		return interceptedStream[T](out.Stream), nil
+	}
+	v, err := interceptedValue[T](out.Value)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]T{v}), nil
+}
+
+// interceptedRunnable wraps all the four modes of a compiled Runnable with the interceptors added at compile time and call time.
+func interceptedRunnable[I, O any](rp *runnablePacker[I, O, Option], name string, interceptors []Interceptor) {
+	i, s, c, t := rp.i, rp.s, rp.c, rp.t
+	newInfo := func(mode RunMode) *InterceptorInfo {
+		return &InterceptorInfo{
+			Name:       name,
+			Mode:       mode,
+			InputType:  reflect.TypeOf((*I)(nil)).Elem(),
+			OutputType: reflect.TypeOf((*O)(nil)).Elem(),
+		}
+	}
+
+	rp.i = func(ctx context.Context, input I, opts ...Option) (output O, err error) {
+		chain := getCallInterceptors(interceptors, opts)
+		if len(chain) == 0 {
+			return i(ctx, input, opts...)
+		}
+
+		out, err := runInterceptors(ctx, chain, newInfo(RunModeInvoke), &InterceptorInput{Value: input, Options: toAnyList(opts)},
+			func(ctx context.Context, in *InterceptorInput) (*InterceptorOutput, error) {
+				nInput, err := interceptedValue[I](in.Value)
+				if err != nil {
+					return nil, err
+				}
+				nOpts, err := convertOption[Option](in.Options...)
+				if err != nil {
+					return nil, err
+				}
+				o, err := i(ctx, nInput, nOpts...)
+				if err != nil {
+					return nil, err
+				}
+				return &InterceptorOutput{Value: o}, nil
+			})
+		if err != nil {
+			return output, err
+		}
+		return interceptedOutputValue[O](out)
+	}
+
+	rp.s = func(ctx context.Context, input I, opts ...Option) (output *schema.StreamReader[O], err error) {
+		chain := getCallInterceptors(interceptors, opts)
+		if len(chain) == 0 {
+			return s(ctx, input, opts...)
+		}
+
+		out, err := runInterceptors(ctx, chain, newInfo(RunModeStream), &InterceptorInput{Value: input, Options: toAnyList(opts)},
+			func(ctx context.Context, in *InterceptorInput) (*InterceptorOutput, error) {
+				nInput, err := interceptedValue[I](in.Value)
+				if err != nil {
+					return nil, err
+				}
+				nOpts, err := convertOption[Option](in.Options...)
+				if err != nil {
+					return nil, err
+				}
+				o, err := s(ctx, nInput, nOpts...)
+				if err != nil {
+					return nil, err
+				}
+				return &InterceptorOutput{Stream: toAnyStream(o)}, nil
+			})
+		if err != nil {
+			return nil, err
+		}
+		return interceptedOutputStream[O](out)
+	}
+
+	rp.c = func(ctx context.Context, input *schema.StreamReader[I], opts ...Option) (output O, err error) {
+		chain := getCallInterceptors(interceptors, opts)
+		if len(chain) == 0 {
+			return c(ctx, input, opts...)
+		}
+
+		out, err := runInterceptors(ctx, chain, newInfo(RunModeCollect), &InterceptorInput{Stream: toAnyStream(input), Options: toAnyList(opts)},
+			func(ctx context.Context, in *InterceptorInput) (*InterceptorOutput, error) {
+				nOpts, err := convertOption[Option](in.Options...)
+				if err != nil {
+					return nil, err
+				}
+				o, err := c(ctx, interceptedStream[I](in.Stream), nOpts...)
+				if err != nil {
+					return nil, err
+				}
+				return &InterceptorOutput{Value: o}, nil
+			})
+		if err != nil {
+			return output, err
+		}
+		return interceptedOutputValue[O](out)
+	}
+
+	rp.t = func(ctx context.Context, input *schema.StreamReader[I], opts ...Option) (output *schema.StreamReader[O], err error) {
+		chain := getCallInterceptors(interceptors, opts)
+		if len(chain) == 0 {
+			return t(ctx, input, opts...)
+		}
+
+		out, err := runInterceptors(ctx, chain, newInfo(RunModeTransform), &InterceptorInput{Stream: toAnyStream(input), Options: toAnyList(opts)},
+			func(ctx context.Context, in *InterceptorInput) (*InterceptorOutput, error) {
+				nOpts, err := convertOption[Option](in.Options...)
+				if err != nil {
+					return nil, err
+				}
+				o, err := t(ctx, interceptedStream[I](in.Stream), nOpts...)
+				if err != nil {
+					return nil, err
+				}
+				return &InterceptorOutput{Stream: toAnyStream(o)}, nil
+			})
+		if err != nil {
+			return nil, err
+		}
+		return interceptedOutputStream[O](out)
+	}
+}
+
+// interceptedComposableRunnable wraps a graph node with the interceptors added to the node and the call interceptors designated to it.
+func interceptedComposableRunnable(nodeKey string, r *composableRunnable, interceptors []Interceptor) *composableRunnable {
+	wrapper := *r
+	newInfo := func(mode RunMode) *InterceptorInfo {
+		info := &InterceptorInfo{
+			NodeKey:    nodeKey,
+			Mode:       mode,
+			InputType:  r.inputType,
+			OutputType: r.outputType,
+		}
+		if r.nodeInfo != nil {
+			info.Name = r.nodeInfo.name
+		}
+		return info
+	}
+
+	i := r.i
+	wrapper.i = func(ctx context.Context, input any, opts ...any) (output any, err error) {
+		chain, opts := splitNodeCallInterceptors(interceptors, opts)
+		if len(chain) == 0 {
+			return i(ctx, input, opts...)
+		}
+
+		out, err := runInterceptors(ctx, chain, newInfo(RunModeInvoke), &InterceptorInput{Value: input, Options: opts},
+			func(ctx context.Context, in *InterceptorInput) (*InterceptorOutput, error) {
+				nInput := in.Value
+				if nInput != nil {
+					nInput, err = r.inputConverter.invoke(nInput)
+					if err != nil {
+						return nil, err
+					}
+				}
+				o, err := i(ctx, nInput, in.Options...)
+				if err != nil {
+					return nil, err
+				}
+				return &InterceptorOutput{Value: o}, nil
+			})
+		if err != nil {
+			return nil, err
+		}
+
+		if out.Value == nil && out.Stream != nil {
+			return r.outputStreamConvertPair.concatStream(r.outputConverter.transform(packStreamReader(out.Stream)))
+		}
+		if out.Value == nil {
+			return nil, nil
+		}
+		return r.outputConverter.invoke(out.Value)
+	}
+
+	t := r.t
+	wrapper.t = func(ctx context.Context, input streamReader, opts ...any) (output streamReader, err error) {
+		chain, opts := splitNodeCallInterceptors(interceptors, opts)
+		if len(chain) == 0 {
+			return t(ctx, input, opts...)
+		}
+
+		out, err := runInterceptors(ctx, chain, newInfo(RunModeTransform), &InterceptorInput{Stream: input.toAnyStreamReader(), Options: opts},
+			func(ctx context.Context, in *InterceptorInput) (*InterceptorOutput, error) {
+				o, err := t(ctx, r.inputConverter.transform(packStreamReader(in.Stream)), in.Options...)
+				if err != nil {
+					return nil, err
+				}
+				return &InterceptorOutput{Stream: o.toAnyStreamReader()}, nil
+			})
+		if err != nil {
+			return nil, err
+		}
+
+		if out.Stream != nil {
+			return r.outputConverter.transform(packStreamReader(out.Stream)), nil
+		}
+		return r.outputStreamConvertPair.restoreStream(out.Value)
+	}
+
+	return &wrapper
+}
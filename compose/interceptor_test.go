@@ -0,0 +1,217 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+type lambdaSuffixOption struct {
+	suffix string
+}
+
+func newInterceptorTestGraph(t *testing.T, opts ...GraphAddNodeOpt) *Graph[string, string] {
+	g := NewGraph[string, string]()
+	assert.NoError(t, g.AddLambdaNode("1", InvokableLambdaWithOption(func(ctx context.Context, input string, opts ...lambdaSuffixOption) (string, error) {
+		for _, opt := range opts {
+			input += opt.suffix
+		}
+		return input + "1", nil
+	}), opts...))
+	assert.NoError(t, g.AddEdge(START, "1"))
+	assert.NoError(t, g.AddEdge("1", END))
+	return g
+}
+
+func TestGraphInterceptors(t *testing.T) {
+	ctx := context.Background()
+
+	var modes []RunMode
+	upper := func(ctx context.Context, info *InterceptorInfo, in *InterceptorInput, next InterceptorHandler) (*InterceptorOutput, error) {
+		modes = append(modes, info.Mode)
+		if info.Mode.IsInputStream() {
+			in.Stream = schema.StreamReaderWithConvert(in.Stream, func(v any) (any, error) {
+				return v.(string) + "+", nil
+			})
+		} else {
+			in.Value = in.Value.(string) + "+"
+		}
+
+		out, err := next(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode.IsOutputStream() {
+			out.Stream = schema.StreamReaderWithConvert(out.Stream, func(v any) (any, error) {
+				return v.(string) + "!", nil
+			})
+		} else {
+			out.Value = out.Value.(string) + "!"
+		}
+		return out, nil
+	}
+
+	r, err := newInterceptorTestGraph(t).Compile(ctx, WithGraphInterceptors(upper), WithGraphName("g"))
+	assert.NoError(t, err)
+
+	out, err := r.Invoke(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "a+1!", out)
+
+	sr, err := r.Stream(ctx, "a")
+	assert.NoError(t, err)
+	out, err = concatStreamReader(sr)
+	assert.NoError(t, err)
+	assert.Equal(t, "a+1!", out)
+
+	out, err = r.Collect(ctx, schema.StreamReaderFromArray([]string{"a"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "a+1!", out)
+
+	sr, err = r.Transform(ctx, schema.StreamReaderFromArray([]string{"a"}))
+	assert.NoError(t, err)
+	out, err = concatStreamReader(sr)
+	assert.NoError(t, err)
+	assert.Equal(t, "a+1!", out)
+
+	assert.Equal(t, []RunMode{RunModeInvoke, RunModeStream, RunModeCollect, RunModeTransform}, modes)
+
+	t.Run("short circuit and call interceptors", func(t *testing.T) {
+		var order []string
+		record := func(name string) Interceptor {
+			return func(ctx context.Context, info *InterceptorInfo, in *InterceptorInput, next InterceptorHandler) (*InterceptorOutput, error) {
+				order = append(order, name)
+				assert.Equal(t, "g", info.Name)
+				return next(ctx, in)
+			}
+		}
+		cached := func(ctx context.Context, info *InterceptorInfo, in *InterceptorInput, next InterceptorHandler) (*InterceptorOutput, error) {
+			if in.Value == "cached" {
+				return &InterceptorOutput{Value: "from cache"}, nil
+			}
+			return next(ctx, in)
+		}
+
+		r, err := newInterceptorTestGraph(t).Compile(ctx, WithGraphInterceptors(record("compile"), cached), WithGraphName("g"))
+		assert.NoError(t, err)
+
+		out, err := r.Invoke(ctx, "cached", WithCallInterceptors(record("call")))
+		assert.NoError(t, err)
+		assert.Equal(t, "from cache", out)
+		assert.Equal(t, []string{"compile"}, order)
+
+		sr, err := r.Stream(ctx, "cached")
+		assert.NoError(t, err)
+		out, err = concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, "from cache", out)
+
+		order = nil
+		out, err = r.Invoke(ctx, "a", WithCallInterceptors(record("call")))
+		assert.NoError(t, err)
+		assert.Equal(t, "a1", out)
+		assert.Equal(t, []string{"compile", "call"}, order)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		r, err := newInterceptorTestGraph(t).Compile(ctx, WithGraphInterceptors(
+			func(ctx context.Context, info *InterceptorInfo, in *InterceptorInput, next InterceptorHandler) (*InterceptorOutput, error) {
+				return nil, errors.New("unauthorized")
+			}))
+		assert.NoError(t, err)
+		_, err = r.Invoke(ctx, "a")
+		assert.EqualError(t, err, "unauthorized")
+	})
+
+	t.Run("modify input type", func(t *testing.T) {
+		r, err := newInterceptorTestGraph(t).Compile(ctx, WithGraphInterceptors(
+			func(ctx context.Context, info *InterceptorInfo, in *InterceptorInput, next InterceptorHandler) (*InterceptorOutput, error) {
+				in.Value = 1
+				return next(ctx, in)
+			}))
+		assert.NoError(t, err)
+		_, err = r.Invoke(ctx, "a")
+		assert.ErrorContains(t, err, "intercepted value type mismatch, expected: string, actual: int")
+	})
+}
+
+func TestNodeInterceptors(t *testing.T) {
+	ctx := context.Background()
+
+	addSuffix := func(ctx context.Context, info *InterceptorInfo, in *InterceptorInput, next InterceptorHandler) (*InterceptorOutput, error) {
+		assert.Equal(t, "1", info.NodeKey)
+		in.Options = append(in.Options, lambdaSuffixOption{suffix: "-opt"})
+		return next(ctx, in)
+	}
+
+	r, err := newInterceptorTestGraph(t, WithNodeInterceptors(addSuffix)).Compile(ctx)
+	assert.NoError(t, err)
+
+	out, err := r.Invoke(ctx, "a", WithLambdaOption(lambdaSuffixOption{suffix: "-call"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "a-call-opt1", out)
+
+	sr, err := r.Stream(ctx, "a")
+	assert.NoError(t, err)
+	out, err = concatStreamReader(sr)
+	assert.NoError(t, err)
+	assert.Equal(t, "a-opt1", out)
+
+	t.Run("designated call interceptors", func(t *testing.T) {
+		var modes []RunMode
+		wrap := func(ctx context.Context, info *InterceptorInfo, in *InterceptorInput, next InterceptorHandler) (*InterceptorOutput, error) {
+			modes = append(modes, info.Mode)
+			out, err := next(ctx, in)
+			if err != nil {
+				return nil, err
+			}
+			if info.Mode.IsOutputStream() {
+				out.Stream = schema.StreamReaderWithConvert(out.Stream, func(v any) (any, error) {
+					return "[" + v.(string) + "]", nil
+				})
+			} else {
+				out.Value = "[" + out.Value.(string) + "]"
+			}
+			return out, nil
+		}
+
+		g := NewGraph[string, string]()
+		assert.NoError(t, g.AddGraphNode("sub", newInterceptorTestGraph(t)))
+		assert.NoError(t, g.AddEdge(START, "sub"))
+		assert.NoError(t, g.AddEdge("sub", END))
+		r, err := g.Compile(ctx)
+		assert.NoError(t, err)
+
+		out, err := r.Invoke(ctx, "a", WithCallInterceptors(wrap).DesignateNodeWithPath(NewNodePath("sub", "1")))
+		assert.NoError(t, err)
+		assert.Equal(t, "[a1]", out)
+
+		sr, err := r.Stream(ctx, "a", WithCallInterceptors(wrap).DesignateNode("sub"))
+		assert.NoError(t, err)
+		out, err = concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, "[a1]", out)
+
+		assert.Equal(t, []RunMode{RunModeInvoke, RunModeTransform}, modes)
+	})
+}
@@ -17,6 +17,7 @@
 package compose
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -35,6 +36,71 @@ func WithInterruptAfterNodes(nodes []string) GraphCompileOption {
 	}
 }
 
+// InterruptConditionInput is the input of an InterruptCondition.
+type InterruptConditionInput struct {
+	// NodeKey is the key of the node within the graph the condition is compiled with.
+	NodeKey string
+	// Path is the full path of the node from the root graph, which is useful when the same condition is shared by subgraphs.
+	Path NodePath
+	// Value is the node input for WithInterruptBeforeNodesIf, or the node output for WithInterruptAfterNodesIf.
+	// When the graph runs in stream mode, the stream is concatenated into a single value.
+	Value any
+	// State is the graph state, nil if the graph has no state.
+	State any
+}
+
+// InterruptCondition decides at request time whether the graph should interrupt before or after a node.
+// If interrupt is true, payload is reported in InterruptInfo.BeforeNodesExtra or InterruptInfo.AfterNodesExtra, keyed by the node key.
+// The state is locked while the condition is called, so do not call ProcessState in the condition.
+type InterruptCondition func(ctx context.Context, in *InterruptConditionInput) (interrupt bool, payload any, err error)
+
+type interruptCondition struct {
+	cond  InterruptCondition
+	nodes map[string]bool
+}
+
+func (c *interruptCondition) match(nodeKey string) bool {
+	return len(c.nodes) == 0 || c.nodes[nodeKey]
+}
+
+func newInterruptCondition(cond InterruptCondition, nodes []string) *interruptCondition {
+	ic := &interruptCondition{cond: cond}
+	if len(nodes) > 0 {
+		ic.nodes = make(map[string]bool, len(nodes))
+		for _, node := range nodes {
+			ic.nodes[node] = true
+		}
+	}
+	return ic
+}
+
+// WithInterruptBeforeNodesIf interrupts the graph before a node runs if cond returns true.
+// cond is called for the given nodes, or for every node if no node is given.
+// In stream mode the node input has to be concatenated before calling cond, so prefer restricting cond to the nodes really needed.
+// To interrupt within a subgraph, set this option through WithGraphCompileOptions when adding the subgraph.
+// e.g.
+//
+//	graph.Compile(ctx, compose.WithCheckPointStore(store), compose.WithInterruptBeforeNodesIf(
+//		func(ctx context.Context, in *compose.InterruptConditionInput) (bool, any, error) {
+//			msg := in.Value.(*schema.Message)
+//			return len(msg.ToolCalls) > 0, msg.ToolCalls, nil
+//		}, "tools"))
+func WithInterruptBeforeNodesIf(cond InterruptCondition, nodes ...string) GraphCompileOption {
+	return func(options *graphCompileOptions) {
+		options.interruptBeforeConditions = append(options.interruptBeforeConditions, newInterruptCondition(cond, nodes))
+	}
+}
+
+// WithInterruptAfterNodesIf interrupts the graph after a node finishes if cond returns true.
+// cond is called for the given nodes, or for every node if no node is given.
+// In stream mode the node output has to be concatenated before calling cond, so prefer restricting cond to the nodes really needed.
+// To interrupt within a subgraph, set this option through WithGraphCompileOptions when adding the subgraph.
+func WithInterruptAfterNodesIf(cond InterruptCondition, nodes ...string) GraphCompileOption {
+	return func(options *graphCompileOptions) {
+		options.interruptAfterConditions = append(options.interruptAfterConditions, newInterruptCondition(cond, nodes))
+	}
+}
+
 var InterruptAndRerun = errors.New("interrupt and rerun")
 
 func NewInterruptAndRerunErr(extra any) error {
@@ -67,6 +133,11 @@ type InterruptInfo struct {
 	RerunNodes      []string
 	RerunNodesExtra map[string]any
 	SubGraphs       map[string]*InterruptInfo
+
+	// BeforeNodesExtra and AfterNodesExtra hold the payloads returned by the conditions of
+	// WithInterruptBeforeNodesIf and WithInterruptAfterNodesIf, keyed by node key.
+	BeforeNodesExtra map[string]any
+	AfterNodesExtra  map[string]any
 }
 
 func init() {
@@ -87,6 +87,10 @@ func (ch *pregelChannel) get(isStream bool, name string, edgeHandler *edgeHandle
 	return v, true, nil
 }
 
+func (ch *pregelChannel) isWaiting() bool {
+	return len(ch.Values) > 0
+}
+
 func (ch *pregelChannel) reportSkip(_ []string) bool {
 	return false
 }
@@ -0,0 +1,317 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+)
+
+// RunInfo is a snapshot of an in-flight graph run.
+type RunInfo struct {
+	RunID     string
+	GraphName string
+	StartTime time.Time
+	// Step is the current super step, starting from 0.
+	// In eager mode, a step ends whenever a node completes.
+	Step int
+	// RunningNodes are the nodes that have been started and not completed yet.
+	RunningNodes []*NodeRunInfo
+	// WaitingNodes are the nodes that have received part of their inputs and are waiting for other predecessors.
+	WaitingNodes []string
+	// CompletedNodes are the node executions that have completed, in the order of completion.
+	CompletedNodes []*NodeRunInfo
+	// State is the json snapshot of the graph state, nil if the graph has no state.
+	State json.RawMessage
+	// StateErr is the error occurs when marshaling the graph state.
+	StateErr error
+}
+
+// NodeRunInfo is the execution info of a node in a graph run.
+type NodeRunInfo struct {
+	NodeKey   string
+	Step      int
+	StartTime time.Time
+	// Elapsed is the time elapsed until now for running nodes, or the total duration for completed nodes.
+	Elapsed time.Duration
+}
+
+// RunRegistry tracks the in-flight runs of graphs compiled with WithRunRegistry,
+// so that the runs can be listed, inspected and interrupted, e.g. by an ops dashboard or a debug endpoint.
+// A run is registered when it starts and removed when it ends. Only top level graphs are tracked.
+// It's safe to use a registry concurrently, and to share it among graphs.
+type RunRegistry struct {
+	mu   sync.Mutex
+	runs map[string]*runTracker
+}
+
+// NewRunRegistry creates an empty RunRegistry.
+func NewRunRegistry() *RunRegistry {
+	return &RunRegistry{
+		runs: make(map[string]*runTracker),
+	}
+}
+
+// WithRunRegistry registers the runs of the graph to the registry.
+// Notice: nodes of a tracked run are always executed in new goroutines, in order to be interruptable, the same as WithGraphInterrupt.
+func WithRunRegistry(registry *RunRegistry) GraphCompileOption {
+	return func(o *graphCompileOptions) {
+		o.runRegistry = registry
+	}
+}
+
+// WithRunID specifies the id of the run in the RunRegistry, a random uuid is used if not set.
+// The id must be unique among the in-flight runs of the registry.
+func WithRunID(runID string) Option {
+	return Option{
+		runID: &runID,
+	}
+}
+
+func getRunID(opts ...Option) *string {
+	var runID *string
+	for _, opt := range opts {
+		if opt.runID != nil {
+			runID = opt.runID
+		}
+	}
+	return runID
+}
+
+// List returns the snapshots of all the in-flight runs, sorted by start time.
+func (r *RunRegistry) List() []*RunInfo {
+	r.mu.Lock()
+	trackers := make([]*runTracker, 0, len(r.runs))
+	for _, t := range r.runs {
+		trackers = append(trackers, t)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(trackers, func(i, j int) bool {
+		return trackers[i].startTime.Before(trackers[j].startTime)
+	})
+
+	infos := make([]*RunInfo, 0, len(trackers))
+	for _, t := range trackers {
+		infos = append(infos, t.snapshot())
+	}
+	return infos
+}
+
+// Get returns the snapshot of the in-flight run, false if the run doesn't exist or has ended.
+func (r *RunRegistry) Get(runID string) (*RunInfo, bool) {
+	r.mu.Lock()
+	t, ok := r.runs[runID]
+	r.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return t.snapshot(), true
+}
+
+// Interrupt triggers a graceful interrupt of the in-flight run, the same as the interrupt function returned by WithGraphInterrupt.
+// The run waits for the running nodes to complete by default, and returns an interrupt error, which can be resumed from the checkpoint if a CheckPointStore is set.
+// Interrupting a run more than once takes no effect.
+func (r *RunRegistry) Interrupt(runID string, opts ...GraphInterruptOption) error {
+	r.mu.Lock()
+	t, ok := r.runs[runID]
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("run[%s] not found", runID)
+	}
+
+	o := &graphInterruptOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	t.interrupt(o.timeout)
+	return nil
+}
+
+// register starts tracking a run, and returns the ctx that can be interrupted by the registry.
+func (r *RunRegistry) register(ctx context.Context, runID *string, graphName string) (context.Context, *runTracker, error) {
+	id := uuid.NewString()
+	if runID != nil {
+		id = *runID
+	}
+
+	t := &runTracker{
+		id:          id,
+		graphName:   graphName,
+		startTime:   time.Now(),
+		running:     make(map[string]*NodeRunInfo),
+		interruptCh: make(chan *time.Duration, 1),
+		done:        make(chan struct{}),
+	}
+
+	r.mu.Lock()
+	if _, ok := r.runs[id]; ok {
+		r.mu.Unlock()
+		return ctx, nil, fmt.Errorf("run[%s] is already running", id)
+	}
+	r.runs[id] = t
+	r.mu.Unlock()
+
+	if parent := getGraphCancel(ctx); parent != nil {
+		// forward the interrupt from WithGraphInterrupt
+		go func() {
+			select {
+			case timeout, ok := <-parent.ch:
+				if ok {
+					t.interrupt(timeout)
+				}
+			case <-t.done:
+			}
+		}()
+	}
+
+	return context.WithValue(ctx, graphCancelChanKey{}, &graphCancelChanVal{ch: t.interruptCh}), t, nil
+}
+
+func (r *RunRegistry) unregister(t *runTracker) {
+	r.mu.Lock()
+	delete(r.runs, t.id)
+	r.mu.Unlock()
+	close(t.done)
+}
+
+type runTracker struct {
+	id        string
+	graphName string
+	startTime time.Time
+
+	mu        sync.Mutex
+	step      int
+	running   map[string]*NodeRunInfo
+	completed []*NodeRunInfo
+	waiting   []string
+	state     *internalState
+
+	interruptCh   chan *time.Duration
+	interruptOnce sync.Once
+	done          chan struct{}
+}
+
+func (t *runTracker) interrupt(timeout *time.Duration) {
+	t.interruptOnce.Do(func() {
+		t.interruptCh <- timeout
+		close(t.interruptCh)
+	})
+}
+
+// the following methods are called by the run goroutine, and are no-op on nil tracker.
+
+func (t *runTracker) setState(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	state, _ := ctx.Value(stateKey{}).(*internalState)
+	t.mu.Lock()
+	t.state = state
+	t.mu.Unlock()
+}
+
+func (t *runTracker) onSubmit(step int, tasks []*task, waiting []string) {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.step = step
+	t.waiting = waiting
+	for _, ta := range tasks {
+		t.running[ta.nodeKey] = &NodeRunInfo{
+			NodeKey:   ta.nodeKey,
+			Step:      step,
+			StartTime: now,
+		}
+	}
+}
+
+func (t *runTracker) onComplete(tasks []*task) {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, ta := range tasks {
+		info, ok := t.running[ta.nodeKey]
+		if !ok {
+			continue
+		}
+		delete(t.running, ta.nodeKey)
+		info.Elapsed = now.Sub(info.StartTime)
+		t.completed = append(t.completed, info)
+	}
+}
+
+func (t *runTracker) snapshot() *RunInfo {
+	now := time.Now()
+	t.mu.Lock()
+	info := &RunInfo{
+		RunID:          t.id,
+		GraphName:      t.graphName,
+		StartTime:      t.startTime,
+		Step:           t.step,
+		RunningNodes:   make([]*NodeRunInfo, 0, len(t.running)),
+		WaitingNodes:   append([]string{}, t.waiting...),
+		CompletedNodes: make([]*NodeRunInfo, 0, len(t.completed)),
+	}
+	for _, n := range t.running {
+		rn := *n
+		rn.Elapsed = now.Sub(rn.StartTime)
+		info.RunningNodes = append(info.RunningNodes, &rn)
+	}
+	for _, n := range t.completed {
+		cn := *n
+		info.CompletedNodes = append(info.CompletedNodes, &cn)
+	}
+	state := t.state
+	t.mu.Unlock()
+
+	sort.Slice(info.RunningNodes, func(i, j int) bool {
+		return info.RunningNodes[i].NodeKey < info.RunningNodes[j].NodeKey
+	})
+
+	if state != nil {
+		state.mu.Lock()
+		info.State, info.StateErr = sonic.Marshal(state.state)
+		state.mu.Unlock()
+	}
+	return info
+}
+
+// waitingNodes returns the nodes that have received part of their inputs.
+func waitingNodes(cm *channelManager) []string {
+	var nodes []string
+	for key, ch := range cm.channels {
+		if key != END && ch.isWaiting() {
+			nodes = append(nodes, key)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
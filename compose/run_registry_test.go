@@ -0,0 +1,112 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunRegistry(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRunRegistry()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	g := NewGraph[string, string](WithGenLocalState(func(ctx context.Context) (state *testStruct) {
+		return &testStruct{A: "init"}
+	}))
+	assert.NoError(t, g.AddLambdaNode("1", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+		return input + "1", nil
+	})))
+	assert.NoError(t, g.AddLambdaNode("2", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+		close(started)
+		<-release
+		return input + "2", nil
+	})))
+	assert.NoError(t, g.AddEdge(START, "1"))
+	assert.NoError(t, g.AddEdge("1", "2"))
+	assert.NoError(t, g.AddEdge("2", END))
+	r, err := g.Compile(ctx, WithRunRegistry(registry), WithGraphName("g"), WithCheckPointStore(newInMemoryStore()))
+	assert.NoError(t, err)
+
+	t.Run("inspect", func(t *testing.T) {
+		started = make(chan struct{})
+		release = make(chan struct{})
+		done := make(chan struct{})
+		var out string
+		var runErr error
+		go func() {
+			defer close(done)
+			out, runErr = r.Invoke(ctx, "a", WithRunID("run1"))
+		}()
+
+		<-started
+		infos := registry.List()
+		assert.Len(t, infos, 1)
+		info := infos[0]
+		assert.Equal(t, "run1", info.RunID)
+		assert.Equal(t, "g", info.GraphName)
+		assert.Equal(t, 1, info.Step)
+		assert.Len(t, info.RunningNodes, 1)
+		assert.Equal(t, "2", info.RunningNodes[0].NodeKey)
+		assert.Len(t, info.CompletedNodes, 1)
+		assert.Equal(t, "1", info.CompletedNodes[0].NodeKey)
+		assert.Empty(t, info.WaitingNodes)
+		assert.NoError(t, info.StateErr)
+		assert.JSONEq(t, `{"A":"init"}`, string(info.State))
+
+		_, err := r.Invoke(ctx, "a", WithRunID("run1"))
+		assert.ErrorContains(t, err, "run[run1] is already running")
+
+		close(release)
+		<-done
+		assert.NoError(t, runErr)
+		assert.Equal(t, "a12", out)
+
+		_, ok := registry.Get("run1")
+		assert.False(t, ok)
+		assert.Empty(t, registry.List())
+	})
+
+	t.Run("interrupt", func(t *testing.T) {
+		started = make(chan struct{})
+		release = make(chan struct{})
+		done := make(chan struct{})
+		var runErr error
+		go func() {
+			defer close(done)
+			_, runErr = r.Invoke(ctx, "a", WithRunID("run2"), WithCheckPointID("cp"))
+		}()
+
+		<-started
+		_, ok := registry.Get("run2")
+		assert.True(t, ok)
+		assert.NoError(t, registry.Interrupt("run2", WithGraphInterruptTimeout(time.Millisecond)))
+		assert.NoError(t, registry.Interrupt("run2"))
+		<-done
+		close(release)
+
+		info, ok := ExtractInterruptInfo(runErr)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"2"}, info.RerunNodes)
+		assert.ErrorContains(t, registry.Interrupt("run2"), "run[run2] not found")
+	})
+}
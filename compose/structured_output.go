@@ -0,0 +1,373 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/schema"
+)
+
+// RepairingParserConfig is the config for RepairingMessageParser.
+type RepairingParserConfig struct {
+	// parse from content or tool call, default is content.
+	ParseFrom schema.MessageParseFrom
+	// parse key path, default is empty, eg: field.sub_field
+	ParseKeyPath string
+
+	// ParamsOneOf is the schema the output must conform to, inferred from T by utils.GoStruct2ParamsOneOf if nil.
+	ParamsOneOf *schema.ParamsOneOf
+
+	// Model re-generates the output that can't be fixed deterministically, optional.
+	Model model.BaseChatModel
+	// MaxRetries is the max times to re-prompt the Model, default is 2 if nil, 0 means no re-prompting.
+	MaxRetries *int
+	// RepairPrompt builds the messages asking the Model to correct the output, defaultRepairPrompt is used if nil.
+	RepairPrompt func(ctx context.Context, output string, violations []string, jsonSchema string) []*schema.Message
+}
+
+// StructuredOutputAttempt is the callback input and output of each attempt of RepairingMessageParser,
+// whose RunInfo.Component is ComponentOfStructuredOutputAttempt.
+// The callback output is sent by OnEnd if the attempt succeeds, otherwise OnError is called with a *StructuredOutputError.
+type StructuredOutputAttempt struct {
+	// Attempt is 0 for the original output, and n for the output re-generated by the model for the nth time.
+	Attempt int
+	// Raw is the text to be parsed.
+	Raw string
+	// Repaired is true if the text is fixed deterministically, e.g. by removing trailing commas.
+	Repaired bool
+	// Violations are the errors found in the text.
+	Violations []string
+}
+
+// StructuredOutputError is the error of the output that doesn't conform to the schema.
+type StructuredOutputError struct {
+	Violations []string
+}
+
+func (e *StructuredOutputError) Error() string {
+	return fmt.Sprintf("output doesn't conform to the schema: %s", strings.Join(e.Violations, "; "))
+}
+
+// ComponentOfStructuredOutputAttempt is the component of the callbacks of the attempts of RepairingMessageParser.
+const ComponentOfStructuredOutputAttempt component = "StructuredOutputAttempt"
+
+const defaultRepairMaxRetries = 2
+
+// NewRepairingMessageParser creates a RepairingMessageParser.
+func NewRepairingMessageParser[T any](config *RepairingParserConfig) (*RepairingMessageParser[T], error) {
+	if config == nil {
+		config = &RepairingParserConfig{}
+	}
+
+	paramsOneOf := config.ParamsOneOf
+	if paramsOneOf == nil {
+		var err error
+		if paramsOneOf, err = utils.GoStruct2ParamsOneOf[T](); err != nil {
+			return nil, fmt.Errorf("infer schema from %T failed: %w", *new(T), err)
+		}
+	}
+
+	validator, err := paramsOneOf.ToOpenAPIV3()
+	if err != nil {
+		return nil, fmt.Errorf("convert schema to OpenAPIV3 failed: %w", err)
+	}
+	js, err := paramsOneOf.ToJSONSchema()
+	if err != nil {
+		return nil, fmt.Errorf("convert schema to JSONSchema failed: %w", err)
+	}
+	jsonSchema, err := sonic.MarshalString(js)
+	if err != nil {
+		return nil, fmt.Errorf("marshal JSONSchema failed: %w", err)
+	}
+
+	p := &RepairingMessageParser[T]{
+		parseFrom:    config.ParseFrom,
+		parseKeyPath: config.ParseKeyPath,
+		validator:    validator,
+		jsonSchema:   jsonSchema,
+		model:        config.Model,
+		maxRetries:   defaultRepairMaxRetries,
+		repairPrompt: config.RepairPrompt,
+	}
+	if p.parseFrom == "" {
+		p.parseFrom = schema.MessageParseFromContent
+	}
+	if config.MaxRetries != nil {
+		if *config.MaxRetries < 0 {
+			return nil, fmt.Errorf("max retries of repairing message parser is negative: %d", *config.MaxRetries)
+		}
+		p.maxRetries = *config.MaxRetries
+	}
+	if p.repairPrompt == nil {
+		p.repairPrompt = defaultRepairPrompt
+	}
+	return p, nil
+}
+
+// RepairingMessageParser parses a message into T like schema.MessageJSONParser, and validates it against the json schema.
+// If the output is invalid, it first tries deterministic fixes: extracting the json from markdown code blocks or surrounding text,
+// removing trailing commas and replacing single quoted strings. If that still fails and a Model is configured,
+// the Model is asked to correct the output with the violations, up to MaxRetries times.
+// The error finally returned is a *schema.MessageParseError wrapping *StructuredOutputError, with the last raw output.
+// Each attempt is reported to the callbacks, see StructuredOutputAttempt.
+// It implements schema.MessageParser, so it can be used as a Lambda by MessageParser.
+// e.g.
+//
+//	parser, err := compose.NewRepairingMessageParser[Plan](&compose.RepairingParserConfig{
+//		Model: chatModel,
+//	})
+//	chain.AppendChatModel(chatModel)
+//	chain.AppendLambda(compose.MessageParser[Plan](parser))
+type RepairingMessageParser[T any] struct {
+	parseFrom    schema.MessageParseFrom
+	parseKeyPath string
+	validator    *openapi3.Schema
+	jsonSchema   string
+	model        model.BaseChatModel
+	maxRetries   int
+	repairPrompt func(ctx context.Context, output string, violations []string, jsonSchema string) []*schema.Message
+}
+
+// Parse parses a message into an object T, re-prompting the model if necessary.
+func (p *RepairingMessageParser[T]) Parse(ctx context.Context, m *schema.Message) (parsed T, err error) {
+	text, err := messageParseText(m, p.parseFrom)
+	if err != nil {
+		return parsed, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		var violations []string
+		parsed, violations, err = p.runAttempt(ctx, attempt, text)
+		if err == nil {
+			return parsed, nil
+		}
+		if p.model == nil || attempt >= p.maxRetries {
+			return parsed, &schema.MessageParseError{Parser: "json_schema", Raw: text, Err: err}
+		}
+
+		if text, err = p.regenerate(ctx, text, violations); err != nil {
+			return parsed, fmt.Errorf("regenerate structured output failed: %w", err)
+		}
+	}
+}
+
+func (p *RepairingMessageParser[T]) runAttempt(ctx context.Context, attempt int, text string) (parsed T, violations []string, err error) {
+	ctx = callbacks.ReuseHandlers(ctx, &callbacks.RunInfo{
+		Name:      string(ComponentOfStructuredOutputAttempt),
+		Type:      string(ComponentOfStructuredOutputAttempt),
+		Component: ComponentOfStructuredOutputAttempt,
+	})
+	ctx = callbacks.OnStart(ctx, &StructuredOutputAttempt{Attempt: attempt, Raw: text})
+
+	parsed, repaired, violations := p.parseText(text)
+	if len(violations) > 0 {
+		err = &StructuredOutputError{Violations: violations}
+		callbacks.OnError(ctx, err)
+		return parsed, violations, err
+	}
+
+	callbacks.OnEnd(ctx, &StructuredOutputAttempt{Attempt: attempt, Raw: text, Repaired: repaired})
+	return parsed, nil, nil
+}
+
+// parseText returns the violations if text can't be parsed into a valid T.
+func (p *RepairingMessageParser[T]) parseText(text string) (parsed T, repaired bool, violations []string) {
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		fixed := repairJSON(text)
+		if fErr := json.Unmarshal([]byte(fixed), &value); fErr != nil {
+			return parsed, false, []string{fmt.Sprintf("invalid json: %v", err)}
+		}
+		repaired = true
+	}
+
+	if p.parseKeyPath != "" {
+		for _, key := range strings.Split(p.parseKeyPath, ".") {
+			obj, ok := value.(map[string]any)
+			if !ok {
+				return parsed, repaired, []string{fmt.Sprintf("parse key path %s not found", p.parseKeyPath)}
+			}
+			if value, ok = obj[key]; !ok {
+				return parsed, repaired, []string{fmt.Sprintf("parse key path %s not found", p.parseKeyPath)}
+			}
+		}
+	}
+
+	if err := p.validator.VisitJSON(value, openapi3.MultiErrors()); err != nil {
+		return parsed, repaired, schemaViolations(err)
+	}
+
+	data, err := sonic.Marshal(value)
+	if err == nil {
+		err = sonic.Unmarshal(data, &parsed)
+	}
+	if err != nil {
+		return parsed, repaired, []string{fmt.Sprintf("unmarshal into %T failed: %v", parsed, err)}
+	}
+	return parsed, repaired, nil
+}
+
+func (p *RepairingMessageParser[T]) regenerate(ctx context.Context, output string, violations []string) (string, error) {
+	input := p.repairPrompt(ctx, output, violations, p.jsonSchema)
+
+	generate := p.model.Generate
+	if !components.IsCallbacksEnabled(p.model) {
+		ctx = callbacks.ReuseHandlers(ctx, &callbacks.RunInfo{
+			Type:      componentTypeOf(p.model),
+			Component: components.ComponentOfChatModel,
+		})
+		generate = invokeWithCallbacks(generate)
+	}
+
+	msg, err := generate(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	if msg.Content == "" && len(msg.ToolCalls) > 0 {
+		return msg.ToolCalls[0].Function.Arguments, nil
+	}
+	return msg.Content, nil
+}
+
+func componentTypeOf(c any) string {
+	typ, _ := components.GetType(c)
+	return typ
+}
+
+func messageParseText(m *schema.Message, from schema.MessageParseFrom) (string, error) {
+	if m == nil {
+		return "", fmt.Errorf("message is nil")
+	}
+
+	switch from {
+	case "", schema.MessageParseFromContent:
+		return m.Content, nil
+	case schema.MessageParseFromToolCall:
+		if len(m.ToolCalls) == 0 {
+			return "", fmt.Errorf("no tool call found")
+		}
+		return m.ToolCalls[0].Function.Arguments, nil
+	default:
+		return "", fmt.Errorf("invalid parse from type: %s", from)
+	}
+}
+
+func defaultRepairPrompt(_ context.Context, output string, violations []string, jsonSchema string) []*schema.Message {
+	return []*schema.Message{
+		schema.SystemMessage("Your previous output doesn't conform to the JSON schema below. " +
+			"Reply with the corrected JSON only, without any explanation or markdown.\n\nJSON schema:\n" + jsonSchema),
+		schema.UserMessage("Previous output:\n" + output + "\n\nErrors:\n- " + strings.Join(violations, "\n- ")),
+	}
+}
+
+func schemaViolations(err error) []string {
+	var me openapi3.MultiError
+	if errors.As(err, &me) {
+		var violations []string
+		for _, e := range me {
+			violations = append(violations, schemaViolations(e)...)
+		}
+		return violations
+	}
+
+	var se *openapi3.SchemaError
+	if errors.As(err, &se) {
+		reason := se.Reason
+		if reason == "" {
+			reason = fmt.Sprintf("doesn't match schema %q", se.SchemaField)
+		}
+		if se.Origin != nil {
+			reason = se.Origin.Error()
+		}
+		return []string{"/" + strings.Join(se.JSONPointer(), "/") + ": " + reason}
+	}
+
+	return []string{err.Error()}
+}
+
+// repairJSON fixes the common mistakes of models deterministically:
+// the json wrapped in markdown code blocks or surrounded by text, single quoted strings and trailing commas.
+func repairJSON(text string) string {
+	text = strings.TrimSpace(text)
+	if start := strings.Index(text, "```"); start >= 0 {
+		body := text[start+3:]
+		if nl := strings.IndexByte(body, '\n'); nl >= 0 {
+			body = body[nl+1:]
+		}
+		if end := strings.Index(body, "```"); end >= 0 {
+			body = body[:end]
+		}
+		text = strings.TrimSpace(body)
+	}
+
+	if start := strings.IndexAny(text, "{["); start > 0 {
+		text = text[start:]
+	}
+	if end := strings.LastIndexAny(text, "}]"); end >= 0 && end < len(text)-1 {
+		text = text[:end+1]
+	}
+
+	var sb strings.Builder
+	var quote byte // the quote of the current string, 0 if not in a string
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0 && c == '\\' && i+1 < len(text):
+			if quote == '\'' && text[i+1] == '\'' {
+				sb.WriteByte('\'')
+			} else {
+				sb.WriteByte(c)
+				sb.WriteByte(text[i+1])
+			}
+			i++
+		case quote != 0 && c == quote:
+			sb.WriteByte('"')
+			quote = 0
+		case quote == '\'' && c == '"':
+			sb.WriteString(`\"`)
+		case quote != 0:
+			sb.WriteByte(c)
+		case c == '"' || c == '\'':
+			sb.WriteByte('"')
+			quote = c
+		case c == ',':
+			j := i + 1
+			for j < len(text) && strings.IndexByte(" \t\r\n", text[j]) >= 0 {
+				j++
+			}
+			if j < len(text) && (text[j] == '}' || text[j] == ']') {
+				continue
+			}
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
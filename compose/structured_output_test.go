@@ -0,0 +1,207 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/internal/generic"
+	mockModel "github.com/cloudwego/eino/internal/mock/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+type structuredPlan struct {
+	Name  string   `json:"name"`
+	Steps []string `json:"steps"`
+	Count int      `json:"count"`
+}
+
+func TestRepairJSON(t *testing.T) {
+	cases := map[string]string{
+		"```json\n{\"a\": 1,}\n```":          `{"a": 1}`,
+		"Sure! Here it is: [1, 2, ] Thanks.": `[1, 2 ]`,
+		`{'a': 'it\'s "ok"', "b": ['x',]}`:   `{"a": "it's \"ok\"", "b": ["x"]}`,
+		`{"a": "keep, } this"}`:              `{"a": "keep, } this"}`,
+	}
+	for input, expected := range cases {
+		assert.Equal(t, expected, repairJSON(input), input)
+	}
+}
+
+func TestRepairingMessageParser(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("valid and deterministic fix", func(t *testing.T) {
+		parser, err := NewRepairingMessageParser[structuredPlan](nil)
+		assert.NoError(t, err)
+
+		parsed, err := parser.Parse(ctx, &schema.Message{Content: `{"name": "a", "steps": ["x"], "count": 1}`})
+		assert.NoError(t, err)
+		assert.Equal(t, structuredPlan{Name: "a", Steps: []string{"x"}, Count: 1}, parsed)
+
+		parsed, err = parser.Parse(ctx, &schema.Message{Content: "```json\n{'name': 'b', 'steps': [], 'count': 2,}\n```"})
+		assert.NoError(t, err)
+		assert.Equal(t, structuredPlan{Name: "b", Steps: []string{}, Count: 2}, parsed)
+	})
+
+	t.Run("violations without model", func(t *testing.T) {
+		parser, err := NewRepairingMessageParser[structuredPlan](nil)
+		assert.NoError(t, err)
+
+		_, err = parser.Parse(ctx, &schema.Message{Content: `{"name": 1, "steps": []}`})
+		var parseErr *schema.MessageParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, `{"name": 1, "steps": []}`, parseErr.Raw)
+		var outputErr *StructuredOutputError
+		assert.True(t, errors.As(err, &outputErr))
+		assert.Len(t, outputErr.Violations, 2)
+		assert.Contains(t, strings.Join(outputErr.Violations, "\n"), "/name:")
+		assert.Contains(t, strings.Join(outputErr.Violations, "\n"), "count")
+
+		_, err = parser.Parse(ctx, &schema.Message{Content: `not json`})
+		assert.True(t, errors.As(err, &outputErr))
+		assert.Contains(t, outputErr.Violations[0], "invalid json")
+
+		_, err = parser.Parse(ctx, nil)
+		assert.ErrorContains(t, err, "message is nil")
+	})
+
+	t.Run("params one of and key path", func(t *testing.T) {
+		parser, err := NewRepairingMessageParser[map[string]any](&RepairingParserConfig{
+			ParseFrom:    schema.MessageParseFromToolCall,
+			ParseKeyPath: "result",
+			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+				"level": {Type: schema.String, Enum: []string{"low", "high"}, Required: true},
+			}),
+		})
+		assert.NoError(t, err)
+
+		parsed, err := parser.Parse(ctx, &schema.Message{ToolCalls: []schema.ToolCall{{Function: schema.FunctionCall{Arguments: `{"result": {"level": "low"}}`}}}})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"level": "low"}, parsed)
+
+		_, err = parser.Parse(ctx, &schema.Message{ToolCalls: []schema.ToolCall{{Function: schema.FunctionCall{Arguments: `{"result": {"level": "mid"}}`}}}})
+		assert.ErrorContains(t, err, "/level:")
+
+		_, err = parser.Parse(ctx, &schema.Message{})
+		assert.ErrorContains(t, err, "no tool call found")
+	})
+
+	t.Run("retry with model", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cm := mockModel.NewMockBaseChatModel(ctrl)
+		var prompts [][]*schema.Message
+		cm.EXPECT().Generate(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, input []*schema.Message, opts ...any) (*schema.Message, error) {
+				prompts = append(prompts, input)
+				if len(prompts) == 1 {
+					return schema.AssistantMessage(`{"name": "a"}`, nil), nil
+				}
+				return schema.AssistantMessage(`{"name": "a", "steps": [], "count": 3}`, nil), nil
+			}).Times(2)
+
+		parser, err := NewRepairingMessageParser[structuredPlan](&RepairingParserConfig{Model: cm})
+		assert.NoError(t, err)
+
+		var mu sync.Mutex
+		var attempts []string
+		handler := callbacks.NewHandlerBuilder().
+			OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+				mu.Lock()
+				defer mu.Unlock()
+				if a, ok := output.(*StructuredOutputAttempt); ok {
+					attempts = append(attempts, "ok:"+a.Raw)
+				} else if info.Component == components.ComponentOfChatModel {
+					attempts = append(attempts, "model")
+				}
+				return ctx
+			}).
+			OnErrorFn(func(ctx context.Context, info *callbacks.RunInfo, err error) context.Context {
+				mu.Lock()
+				defer mu.Unlock()
+				if info.Component == ComponentOfStructuredOutputAttempt {
+					assert.Equal(t, "StructuredOutputAttempt", info.Name)
+				}
+				attempts = append(attempts, info.Type+":"+err.Error()[:10])
+				return ctx
+			}).Build()
+
+		chain := NewChain[*schema.Message, structuredPlan]()
+		chain.AppendLambda(MessageParser[structuredPlan](parser))
+		r, err := chain.Compile(ctx)
+		assert.NoError(t, err)
+
+		parsed, err := r.Invoke(ctx, &schema.Message{Content: `{"name": 1}`}, WithCallbacks(handler))
+		assert.NoError(t, err)
+		assert.Equal(t, structuredPlan{Name: "a", Steps: []string{}, Count: 3}, parsed)
+
+		assert.Len(t, prompts, 2)
+		assert.Contains(t, prompts[0][0].Content, `"required"`)
+		assert.Contains(t, prompts[0][1].Content, `{"name": 1}`)
+		assert.Contains(t, prompts[1][1].Content, `{"name": "a"}`)
+
+		assert.Equal(t, []string{
+			"StructuredOutputAttempt:output doe",
+			"model",
+			"StructuredOutputAttempt:output doe",
+			"model",
+			`ok:{"name": "a", "steps": [], "count": 3}`,
+		}, attempts[:5])
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cm := mockModel.NewMockBaseChatModel(ctrl)
+		cm.EXPECT().Generate(gomock.Any(), gomock.Any()).Return(schema.AssistantMessage(`{}`, nil), nil).Times(1)
+
+		parser, err := NewRepairingMessageParser[structuredPlan](&RepairingParserConfig{Model: cm, MaxRetries: generic.PtrOf(1)})
+		assert.NoError(t, err)
+		_, err = parser.Parse(ctx, &schema.Message{Content: `[]`})
+		var parseErr *schema.MessageParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, `{}`, parseErr.Raw)
+
+		cm.EXPECT().Generate(gomock.Any(), gomock.Any()).Return(nil, errors.New("model down")).Times(1)
+		_, err = parser.Parse(ctx, &schema.Message{Content: `[]`})
+		assert.ErrorContains(t, err, "model down")
+	})
+
+	t.Run("retry disabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cm := mockModel.NewMockBaseChatModel(ctrl)
+		cm.EXPECT().Generate(gomock.Any(), gomock.Any()).Times(0)
+
+		parser, err := NewRepairingMessageParser[structuredPlan](&RepairingParserConfig{Model: cm, MaxRetries: generic.PtrOf(0)})
+		assert.NoError(t, err)
+		_, err = parser.Parse(ctx, &schema.Message{Content: `[]`})
+		var parseErr *schema.MessageParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, `[]`, parseErr.Raw)
+
+		_, err = NewRepairingMessageParser[structuredPlan](&RepairingParserConfig{Model: cm, MaxRetries: generic.PtrOf(-1)})
+		assert.ErrorContains(t, err, "max retries of repairing message parser is negative")
+	})
+}
@@ -0,0 +1,213 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/cloudwego/eino/internal/generic"
+	"github.com/cloudwego/eino/schema"
+)
+
+func init() {
+	schema.RegisterName[*threadState]("_eino_thread_state")
+}
+
+// ErrThreadStateConflict is returned by ThreadStateStore.Set when the state of the thread has been saved by another run
+// since it was loaded, i.e. the version in the store is not the expected one.
+var ErrThreadStateConflict = errors.New("thread state has been modified concurrently")
+
+// ThreadStateStore persists the graph state of threads, so that the state survives between independent runs on the same thread.
+// Each saved state has a version, which is used to detect concurrent writes.
+type ThreadStateStore interface {
+	// Get returns the saved state of the thread and its version.
+	// existed is false if nothing has been saved for the thread yet.
+	Get(ctx context.Context, threadID string) (state []byte, version int64, existed bool, err error)
+	// Set saves the state of the thread only if the current version in the store equals expectedVersion,
+	// expectedVersion is 0 if the thread had no saved state when it was loaded.
+	// The store should increase the version on each successful Set,
+	// and return ErrThreadStateConflict (possibly wrapped) if the version doesn't match.
+	Set(ctx context.Context, threadID string, state []byte, expectedVersion int64) error
+}
+
+// WithThreadStateStore sets the store of thread states, which is required by WithThreadID.
+// The graph must be created with WithGenLocalState, and the state type should be registered with schema.RegisterName
+// to be serialized, the same as checkpoint. The serializer can be customized by WithSerializer.
+func WithThreadStateStore(store ThreadStateStore) GraphCompileOption {
+	return func(o *graphCompileOptions) {
+		o.threadStateStore = store
+	}
+}
+
+// WithThreadStateMerger sets the function to merge the input of a new run into the state loaded for the thread,
+// e.g. appending the new user message to the message history in the state. The returned value is used as the input of the run.
+// It's called with the state locked before the graph starts, and only for new runs, not when resuming from a checkpoint.
+// In stream mode, the input stream is concatenated before calling merger.
+// I: input type of the graph
+// S: state type defined in WithGenLocalState
+func WithThreadStateMerger[I, S any](merger func(ctx context.Context, input I, state S) (I, error)) GraphCompileOption {
+	return func(o *graphCompileOptions) {
+		o.threadStateMerger = &threadStateMerger{
+			inputType: generic.TypeOf[I](),
+			stateType: generic.TypeOf[S](),
+			merge: func(ctx context.Context, input, state any) (any, error) {
+				in, ok := input.(I)
+				if !ok && input != nil {
+					return nil, fmt.Errorf("unexpected input type of thread state merger, expected: %v, actual: %T", generic.TypeOf[I](), input)
+				}
+				s, ok := state.(S)
+				if !ok {
+					return nil, fmt.Errorf("unexpected state type of thread state merger, expected: %v, actual: %T", generic.TypeOf[S](), state)
+				}
+				return merger(ctx, in, s)
+			},
+		}
+	}
+}
+
+// WithThreadID makes the run belong to the thread.
+// Before the run starts, the state saved for the thread is loaded in place of the state generated by WithGenLocalState,
+// and merged with the input if WithThreadStateMerger is set.
+// After the run completes, the state is saved for the thread. If the state was saved by another run in the meantime,
+// the run fails with an error wrapping ErrThreadStateConflict, and the caller may retry.
+// Interrupted or failed runs don't save the state. When an interrupted run is resumed from its checkpoint with the same thread ID,
+// the state in the checkpoint is used, and saved for the thread on completion,
+// failing with ErrThreadStateConflict if another run has saved the state since the interrupted run loaded it.
+// In stream mode, the state is saved once the graph reaches END, which may be earlier than the output stream is fully consumed.
+// Only takes effect on the top level graph.
+func WithThreadID(threadID string) Option {
+	return Option{
+		threadID: &threadID,
+	}
+}
+
+func getThreadID(opts ...Option) *string {
+	var threadID *string
+	for _, opt := range opts {
+		if opt.threadID != nil {
+			threadID = opt.threadID
+		}
+	}
+	return threadID
+}
+
+type threadStateMerger struct {
+	inputType reflect.Type
+	stateType reflect.Type
+	merge     func(ctx context.Context, input, state any) (any, error)
+}
+
+type threadState struct {
+	State any
+}
+
+func newThreadStater(store ThreadStateStore, serializer Serializer, merger *threadStateMerger) *threadStater {
+	return &threadStater{
+		store:      store,
+		serializer: serializer,
+		merger:     merger,
+	}
+}
+
+type threadStater struct {
+	store      ThreadStateStore
+	serializer Serializer
+	merger     *threadStateMerger
+}
+
+// load replaces the state in ctx with the one saved for the thread, and returns the version of the saved state.
+func (t *threadStater) load(ctx context.Context, threadID string, applyState bool) (int64, error) {
+	data, version, existed, err := t.store.Get(ctx, threadID)
+	if err != nil {
+		return 0, err
+	}
+	if !existed {
+		return 0, nil
+	}
+	if !applyState {
+		return version, nil
+	}
+
+	ts := &threadState{}
+	if err = t.serializer.Unmarshal(data, ts); err != nil {
+		return 0, fmt.Errorf("unmarshal thread state fail: %w", err)
+	}
+
+	state, ok := ctx.Value(stateKey{}).(*internalState)
+	if !ok {
+		// unreachable
+		return 0, errors.New("graph state not found")
+	}
+	state.mu.Lock()
+	state.state = ts.State
+	state.mu.Unlock()
+
+	return version, nil
+}
+
+// merge merges the input into the state in ctx.
+func (t *threadStater) mergeInput(ctx context.Context, input any, isStream bool, pair streamConvertPair) (any, error) {
+	if t.merger == nil {
+		return input, nil
+	}
+
+	state, ok := ctx.Value(stateKey{}).(*internalState)
+	if !ok {
+		// unreachable
+		return nil, errors.New("graph state not found")
+	}
+
+	if isStream {
+		var err error
+		input, err = pair.concatStream(input.(streamReader))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	state.mu.Lock()
+	merged, err := t.merger.merge(ctx, input, state.state)
+	state.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if isStream {
+		return pair.restoreStream(merged)
+	}
+	return merged, nil
+}
+
+func (t *threadStater) save(ctx context.Context, threadID string, version int64) error {
+	state, ok := ctx.Value(stateKey{}).(*internalState)
+	if !ok {
+		// unreachable
+		return errors.New("graph state not found")
+	}
+
+	state.mu.Lock()
+	data, err := t.serializer.Marshal(&threadState{State: state.state})
+	state.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("marshal thread state fail: %w", err)
+	}
+
+	return t.store.Set(ctx, threadID, data, version)
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+type inMemoryThreadStateStore struct {
+	m map[string][]byte
+	v map[string]int64
+}
+
+func (i *inMemoryThreadStateStore) Get(ctx context.Context, threadID string) ([]byte, int64, bool, error) {
+	data, ok := i.m[threadID]
+	return data, i.v[threadID], ok, nil
+}
+
+func (i *inMemoryThreadStateStore) Set(ctx context.Context, threadID string, state []byte, expectedVersion int64) error {
+	if i.v[threadID] != expectedVersion {
+		return ErrThreadStateConflict
+	}
+	i.m[threadID] = state
+	i.v[threadID]++
+	return nil
+}
+
+func newInMemoryThreadStateStore() *inMemoryThreadStateStore {
+	return &inMemoryThreadStateStore{
+		m: make(map[string][]byte),
+		v: make(map[string]int64),
+	}
+}
+
+func newThreadStateTestGraph(t *testing.T) *Graph[string, string] {
+	g := NewGraph[string, string](WithGenLocalState(func(ctx context.Context) (state *testStruct) {
+		return &testStruct{}
+	}))
+	assert.NoError(t, g.AddLambdaNode("1", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+		return input, nil
+	}), WithStatePostHandler(func(ctx context.Context, out string, state *testStruct) (string, error) {
+		return state.A, nil
+	})))
+	assert.NoError(t, g.AddEdge(START, "1"))
+	assert.NoError(t, g.AddEdge("1", END))
+	return g
+}
+
+func TestThreadState(t *testing.T) {
+	ctx := context.Background()
+	store := newInMemoryThreadStateStore()
+
+	r, err := newThreadStateTestGraph(t).Compile(ctx,
+		WithThreadStateStore(store),
+		WithThreadStateMerger(func(ctx context.Context, input string, state *testStruct) (string, error) {
+			state.A += input
+			return input, nil
+		}))
+	assert.NoError(t, err)
+
+	out, err := r.Invoke(ctx, "a", WithThreadID("t1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "a", out)
+
+	sr, err := r.Stream(ctx, "b", WithThreadID("t1"))
+	assert.NoError(t, err)
+	out, err = concatStreamReader(sr)
+	assert.NoError(t, err)
+	assert.Equal(t, "ab", out)
+
+	sr, err = r.Transform(ctx, schema.StreamReaderFromArray([]string{"c", "d"}), WithThreadID("t1"))
+	assert.NoError(t, err)
+	out, err = concatStreamReader(sr)
+	assert.NoError(t, err)
+	assert.Equal(t, "abcd", out)
+	assert.Equal(t, int64(3), store.v["t1"])
+
+	// independent threads and runs without thread
+	out, err = r.Invoke(ctx, "x", WithThreadID("t2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "x", out)
+	out, err = r.Invoke(ctx, "y")
+	assert.NoError(t, err)
+	assert.Equal(t, "", out) // merger only applies to runs with thread id
+	out, err = r.Invoke(ctx, "e", WithThreadID("t1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "abcde", out)
+
+	t.Run("concurrent write", func(t *testing.T) {
+		store := newInMemoryThreadStateStore()
+		g := NewGraph[string, string](WithGenLocalState(func(ctx context.Context) (state *testStruct) {
+			return &testStruct{}
+		}))
+		assert.NoError(t, g.AddLambdaNode("1", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+			if input == "slow" {
+				// another run on the same thread completes in the meantime
+				_ = store.Set(ctx, "t", []byte("{}"), store.v["t"])
+			}
+			return input, nil
+		})))
+		assert.NoError(t, g.AddEdge(START, "1"))
+		assert.NoError(t, g.AddEdge("1", END))
+		r, err := g.Compile(ctx, WithThreadStateStore(store))
+		assert.NoError(t, err)
+
+		_, err = r.Invoke(ctx, "slow", WithThreadID("t"))
+		assert.True(t, errors.Is(err, ErrThreadStateConflict))
+	})
+
+	t.Run("resume from checkpoint", func(t *testing.T) {
+		store := newInMemoryThreadStateStore()
+		r, err := newThreadStateTestGraph(t).Compile(ctx,
+			WithThreadStateStore(store),
+			WithCheckPointStore(newInMemoryStore()),
+			WithInterruptBeforeNodes([]string{"1"}),
+			WithThreadStateMerger(func(ctx context.Context, input string, state *testStruct) (string, error) {
+				state.A += input
+				return input, nil
+			}))
+		assert.NoError(t, err)
+
+		_, err = r.Invoke(ctx, "a", WithThreadID("t"), WithCheckPointID("cp"))
+		_, ok := ExtractInterruptInfo(err)
+		assert.True(t, ok)
+		assert.Empty(t, store.m)
+
+		out, err := r.Invoke(ctx, "", WithThreadID("t"), WithCheckPointID("cp"))
+		assert.NoError(t, err)
+		assert.Equal(t, "a", out)
+		assert.Equal(t, int64(1), store.v["t"])
+	})
+
+	t.Run("concurrent write while interrupted", func(t *testing.T) {
+		store := newInMemoryThreadStateStore()
+		r, err := newThreadStateTestGraph(t).Compile(ctx,
+			WithThreadStateStore(store),
+			WithCheckPointStore(newInMemoryStore()),
+			WithInterruptBeforeNodes([]string{"1"}))
+		assert.NoError(t, err)
+
+		_, err = r.Invoke(ctx, "a", WithThreadID("t"), WithCheckPointID("cp"))
+		_, ok := ExtractInterruptInfo(err)
+		assert.True(t, ok)
+
+		// another run on the same thread completes before resuming
+		assert.NoError(t, store.Set(ctx, "t", []byte("{}"), 0))
+
+		_, err = r.Invoke(ctx, "", WithThreadID("t"), WithCheckPointID("cp"))
+		assert.True(t, errors.Is(err, ErrThreadStateConflict))
+		assert.Equal(t, []byte("{}"), store.m["t"])
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := newThreadStateTestGraph(t).Compile(ctx, WithThreadStateStore(store),
+			WithThreadStateMerger(func(ctx context.Context, input int, state *testStruct) (int, error) {
+				return input, nil
+			}))
+		assert.ErrorContains(t, err, "thread state merger's input type[int] is different from graph[string]")
+
+		_, err = newThreadStateTestGraph(t).Compile(ctx, WithThreadStateMerger(func(ctx context.Context, input string, state *testStruct) (string, error) {
+			return input, nil
+		}))
+		assert.ErrorContains(t, err, "thread state merger is set but thread state store is not set")
+
+		g := NewGraph[string, string]()
+		assert.NoError(t, g.AddLambdaNode("1", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+			return input, nil
+		})))
+		assert.NoError(t, g.AddEdge(START, "1"))
+		assert.NoError(t, g.AddEdge("1", END))
+		_, err = g.Compile(ctx, WithThreadStateStore(store))
+		assert.ErrorContains(t, err, "thread state store is set but graph has no state")
+
+		r, err := newThreadStateTestGraph(t).Compile(ctx)
+		assert.NoError(t, err)
+		_, err = r.Invoke(ctx, "a", WithThreadID("t"))
+		assert.ErrorContains(t, err, "receive thread id but have not set thread state store")
+	})
+}
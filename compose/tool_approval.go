@@ -0,0 +1,118 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ToolApprovalRequest is the extra of a tool call waiting for human approval,
+// which is put in ToolsInterruptAndRerunExtra.RerunExtraMap by the tool call ID.
+type ToolApprovalRequest struct {
+	CallID    string
+	ToolName  string
+	Arguments string
+}
+
+// ToolApprovalDecision is the human decision on a tool call waiting for approval.
+type ToolApprovalDecision struct {
+	// Approved executes the tool call, otherwise the call is rejected and Reason is returned to the model as the tool result.
+	Approved bool
+	// Reason is why the call is rejected.
+	Reason string
+	// Arguments replaces the arguments of the approved call if not empty, e.g. the recipients edited by the user.
+	// The edited arguments are decided by ToolsNodeConfig.ToolPermissions again, and the call is denied if a deny rule matches them.
+	Arguments string
+}
+
+func init() {
+	schema.RegisterName[*ToolApprovalRequest]("_eino_compose_tool_approval_request")
+}
+
+// WithToolApprovals sets the decisions on the tool calls waiting for approval by the tool call ID, used when resuming the interrupted run.
+// Calls without a decision keep waiting and interrupt the run again.
+// e.g.
+//
+//	_, err := runnable.Invoke(ctx, input, compose.WithCheckPointID(id))
+//	info, _ := compose.ExtractInterruptInfo(err)
+//	extra := info.RerunNodesExtra["tools"].(*compose.ToolsInterruptAndRerunExtra)
+//	for callID, e := range extra.RerunExtraMap {
+//		if req, ok := e.(*compose.ToolApprovalRequest); ok {
+//			// ask the user about req.ToolName and req.Arguments
+//		}
+//	}
+//
+//	out, err := runnable.Invoke(ctx, input, compose.WithCheckPointID(id),
+//		compose.WithToolsNodeOption(compose.WithToolApprovals(map[string]*compose.ToolApprovalDecision{
+//			"call_1": {Approved: true},
+//			"call_2": {Reason: "don't email the CEO"},
+//		})).DesignateNode("tools"))
+func WithToolApprovals(decisions map[string]*ToolApprovalDecision) ToolsNodeOption {
+	return func(o *toolsNodeOptions) {
+		if o.approvals == nil {
+			o.approvals = make(map[string]*ToolApprovalDecision, len(decisions))
+		}
+		for id, d := range decisions {
+			o.approvals[id] = d
+		}
+	}
+}
+
+func (tn *ToolsNode) approvalRequired(ctx context.Context, name, arguments string) bool {
+	if _, ok := tn.approvalRequiredTools[name]; ok {
+		return true
+	}
+	return tn.approvalRequiredFn != nil && tn.approvalRequiredFn(ctx, name, arguments)
+}
+
+func newPendingApprovalTask(name, arg, callID string) toolCallTask {
+	req := &ToolApprovalRequest{CallID: callID, ToolName: name, Arguments: arg}
+	return newToolResultTask(name, arg, callID, "PendingApproval", func(ctx context.Context) (string, error) {
+		return "", NewInterruptAndRerunErr(req)
+	})
+}
+
+func newRejectedToolTask(name, arg, callID, reason string) toolCallTask {
+	return newToolResultTask(name, arg, callID, "RejectedTool", func(ctx context.Context) (string, error) {
+		result := "The user rejected this tool call, it's not executed."
+		if reason != "" {
+			result += " Reason: " + reason
+		}
+		return result, nil
+	})
+}
+
+// newToolResultTask creates a task that returns the result without calling the tool.
+func newToolResultTask(name, arg, callID, implType string, result func(ctx context.Context) (string, error)) toolCallTask {
+	return toolCallTask{
+		r: newRunnablePacker(func(ctx context.Context, input string, opts ...tool.Option) (output string, err error) {
+			return result(ctx)
+		}, nil, nil, nil, false),
+		meta: &executorMeta{
+			component:                  components.ComponentOfTool,
+			isComponentCallbackEnabled: false,
+			componentImplType:          implType,
+		},
+		name:   name,
+		arg:    arg,
+		callID: callID,
+	}
+}
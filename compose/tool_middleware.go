@@ -0,0 +1,159 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ToolCallInput is a tool call seen by a ToolMiddleware.
+type ToolCallInput struct {
+	// Name is the name of the called tool, changing it has no effect.
+	Name string
+	// CallID is the ID of the tool call, also available by GetToolCallID(ctx), changing it has no effect.
+	CallID string
+	// Mode is RunModeInvoke when the ToolsNode is invoked, and RunModeStream when it's streamed.
+	Mode RunMode
+
+	// Arguments are the arguments passed to the tool, after ToolArgumentsHandler, approval and validation.
+	Arguments string
+	// Options are the tool options passed to the tool.
+	Options []tool.Option
+}
+
+// ToolCallOutput is the result of a tool call seen by a ToolMiddleware.
+// Result is the output in RunModeInvoke, and Stream is the output in RunModeStream,
+// if only the one not matching the mode is set, it is converted automatically.
+type ToolCallOutput struct {
+	Result string
+	Stream *schema.StreamReader[string]
+}
+
+// ToolCallHandler executes the remaining middlewares and the tool.
+type ToolCallHandler func(ctx context.Context, in *ToolCallInput) (*ToolCallOutput, error)
+
+// ToolMiddleware is an around-style middleware of each tool call in ToolsNode, for both invokable and streamable tools.
+// It can modify the context, arguments and options before calling next, short-circuit by returning without calling next,
+// and transform the result, the error, or wrap the output stream returned by next.
+// It runs outside the timeout, retry and concurrency limit of the ToolPolicy, and outside the tool's callbacks,
+// so a short-circuited call doesn't trigger the tool's callbacks.
+// The results built by ToolsNode itself, e.g. for invalid arguments or rejected calls, don't go through the middlewares.
+// e.g.
+//
+//	audit := func(ctx context.Context, in *compose.ToolCallInput, next compose.ToolCallHandler) (*compose.ToolCallOutput, error) {
+//		out, err := next(ctx, in)
+//		log.Printf("tool %s call %s arguments %s err %v", in.Name, in.CallID, in.Arguments, err)
+//		return out, err
+//	}
+type ToolMiddleware func(ctx context.Context, in *ToolCallInput, next ToolCallHandler) (*ToolCallOutput, error)
+
+// WithToolMiddlewares adds middlewares to the tool calls of a single run,
+// they are executed after the ToolMiddlewares of ToolsNodeConfig, in the order given.
+func WithToolMiddlewares(middlewares ...ToolMiddleware) ToolsNodeOption {
+	return func(o *toolsNodeOptions) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	}
+}
+
+func runToolMiddlewares(ctx context.Context, task *toolCallTask, mode RunMode, opts []tool.Option,
+	endpoint ToolCallHandler) (*ToolCallOutput, error) {
+	handler := endpoint
+	for i := len(task.middlewares) - 1; i >= 0; i-- {
+		middleware, next := task.middlewares[i], handler
+		handler = func(ctx context.Context, in *ToolCallInput) (*ToolCallOutput, error) {
+			return middleware(ctx, in, next)
+		}
+	}
+
+	out, err := handler(ctx, &ToolCallInput{
+		Name:      task.name,
+		CallID:    task.callID,
+		Mode:      mode,
+		Arguments: task.arg,
+		Options:   opts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out == nil {
+		return nil, errors.New("tool middleware returns nil output")
+	}
+	return out, nil
+}
+
+func invokeToolWithMiddlewares(ctx context.Context, task *toolCallTask, opts ...tool.Option) (string, error) {
+	if len(task.middlewares) == 0 {
+		return invokeToolEndpoint(ctx, task, task.arg, opts...)
+	}
+
+	out, err := runToolMiddlewares(ctx, task, RunModeInvoke, opts, func(ctx context.Context, in *ToolCallInput) (*ToolCallOutput, error) {
+		result, err := invokeToolEndpoint(ctx, task, in.Arguments, in.Options...)
+		if err != nil {
+			return nil, err
+		}
+		return &ToolCallOutput{Result: result}, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if out.Result == "" && out.Stream != nil {
+		return concatStreamReader(out.Stream)
+	}
+	return out.Result, nil
+}
+
+func streamToolWithMiddlewares(ctx context.Context, task *toolCallTask, opts ...tool.Option) (*schema.StreamReader[string], error) {
+	if len(task.middlewares) == 0 {
+		return streamToolEndpoint(ctx, task, task.arg, opts...)
+	}
+
+	out, err := runToolMiddlewares(ctx, task, RunModeStream, opts, func(ctx context.Context, in *ToolCallInput) (*ToolCallOutput, error) {
+		sr, err := streamToolEndpoint(ctx, task, in.Arguments, in.Options...)
+		if err != nil {
+			return nil, err
+		}
+		return &ToolCallOutput{Stream: sr}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Stream == nil {
+		return schema.StreamReaderFromArray([]string{out.Result}), nil
+	}
+	return out.Stream, nil
+}
+
+// invokeToolEndpoint invokes the tool with the ToolPolicy and limits the output with the ToolOutputPolicy.
+func invokeToolEndpoint(ctx context.Context, task *toolCallTask, arg string, opts ...tool.Option) (string, error) {
+	output, err := invokeToolWithPolicy(ctx, task, arg, opts...)
+	if err != nil || task.outputLimiter == nil {
+		return output, err
+	}
+	return task.outputLimiter.limit(ctx, task.name, task.callID, output)
+}
+
+func streamToolEndpoint(ctx context.Context, task *toolCallTask, arg string, opts ...tool.Option) (*schema.StreamReader[string], error) {
+	sr, err := streamToolWithPolicy(ctx, task, arg, opts...)
+	if err != nil || task.outputLimiter == nil {
+		return sr, err
+	}
+	return task.outputLimiter.limitStream(ctx, task.name, task.callID, sr), nil
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestToolMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	echoCalls := 0
+	echo, err := utils.InferTool("echo", "echo tool", func(ctx context.Context, in *policyToolInput) (string, error) {
+		echoCalls++
+		return "secret " + in.Value, nil
+	})
+	assert.NoError(t, err)
+	streamEcho, err := utils.InferStreamTool("stream_echo", "stream echo tool", func(ctx context.Context, in *policyToolInput) (*schema.StreamReader[string], error) {
+		return schema.StreamReaderFromArray([]string{"secret ", in.Value}), nil
+	})
+	assert.NoError(t, err)
+	failing, err := utils.InferTool("failing", "failing tool", func(ctx context.Context, in *policyToolInput) (string, error) {
+		return "", errors.New("boom")
+	})
+	assert.NoError(t, err)
+
+	var (
+		mu     sync.Mutex
+		audits []string
+	)
+	audit := func(ctx context.Context, in *ToolCallInput, next ToolCallHandler) (*ToolCallOutput, error) {
+		out, err := next(ctx, in)
+		mu.Lock()
+		audits = append(audits, fmt.Sprintf("%s %s %s %s %s %v", in.Mode, in.Name, in.CallID, GetToolCallID(ctx), in.Arguments, err))
+		mu.Unlock()
+		return out, err
+	}
+	redact := func(ctx context.Context, in *ToolCallInput, next ToolCallHandler) (*ToolCallOutput, error) {
+		out, err := next(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		if out.Stream != nil {
+			out.Stream = schema.StreamReaderWithConvert(out.Stream, func(s string) (string, error) {
+				return strings.ReplaceAll(s, "secret", "***"), nil
+			})
+		}
+		out.Result = strings.ReplaceAll(out.Result, "secret", "***")
+		return out, nil
+	}
+
+	tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+		Tools:           []tool.BaseTool{echo, streamEcho, failing},
+		ToolMiddlewares: []ToolMiddleware{audit, redact},
+	})
+	assert.NoError(t, err)
+
+	t.Run("invoke", func(t *testing.T) {
+		audits = nil
+		output, err := tn.Invoke(ctx, policyToolCalls("echo", 1))
+		assert.NoError(t, err)
+		assert.Equal(t, "*** 0", output[0].Content)
+		assert.Equal(t, []string{`Invoke echo call_0 call_0 {"value": "0"} <nil>`}, audits)
+
+		// the output stream of a streamable tool is concatenated for invoke
+		output, err = tn.Invoke(ctx, policyToolCalls("stream_echo", 1))
+		assert.NoError(t, err)
+		assert.Equal(t, "*** 0", output[0].Content)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		audits = nil
+		sr, err := tn.Stream(ctx, policyToolCalls("stream_echo", 2))
+		assert.NoError(t, err)
+		msgs, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, "*** 0", msgs[0].Content)
+		assert.Equal(t, "*** 1", msgs[1].Content)
+		assert.Len(t, audits, 2)
+		assert.Contains(t, audits, `Stream stream_echo call_1 call_1 {"value": "1"} <nil>`)
+	})
+
+	t.Run("modify arguments and short-circuit per run", func(t *testing.T) {
+		echoCalls = 0
+		cache := map[string]string{`{"value": "0"}`: "secret cached"}
+		caching := func(ctx context.Context, in *ToolCallInput, next ToolCallHandler) (*ToolCallOutput, error) {
+			if result, ok := cache[in.Arguments]; ok {
+				return &ToolCallOutput{Result: result}, nil
+			}
+			return next(ctx, in)
+		}
+		rewrite := func(ctx context.Context, in *ToolCallInput, next ToolCallHandler) (*ToolCallOutput, error) {
+			in.Arguments = strings.ReplaceAll(in.Arguments, "1", "one")
+			return next(ctx, in)
+		}
+
+		output, err := tn.Invoke(ctx, policyToolCalls("echo", 2), WithToolMiddlewares(caching, rewrite))
+		assert.NoError(t, err)
+		assert.Equal(t, "*** cached", output[0].Content)
+		assert.Equal(t, "*** one", output[1].Content)
+		assert.Equal(t, 1, echoCalls)
+
+		// a result short-circuited in stream mode is converted to a stream
+		sr, err := tn.Stream(ctx, policyToolCalls("echo", 1), WithToolMiddlewares(caching))
+		assert.NoError(t, err)
+		msgs, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, "*** cached", msgs[0].Content)
+	})
+
+	t.Run("transform error", func(t *testing.T) {
+		fallback := func(ctx context.Context, in *ToolCallInput, next ToolCallHandler) (*ToolCallOutput, error) {
+			out, err := next(ctx, in)
+			if err != nil {
+				return &ToolCallOutput{Result: "tool failed: " + err.Error()}, nil
+			}
+			return out, nil
+		}
+
+		_, err := tn.Invoke(ctx, policyToolCalls("failing", 1))
+		assert.ErrorContains(t, err, "boom")
+
+		output, err := tn.Invoke(ctx, policyToolCalls("failing", 1), WithToolMiddlewares(fallback))
+		assert.NoError(t, err)
+		assert.Contains(t, output[0].Content, "tool failed: ")
+		assert.Contains(t, output[0].Content, "boom")
+	})
+
+	t.Run("nil output", func(t *testing.T) {
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{Tools: []tool.BaseTool{echo}})
+		assert.NoError(t, err)
+		_, err = tn.Invoke(ctx, policyToolCalls("echo", 1), WithToolMiddlewares(
+			func(ctx context.Context, in *ToolCallInput, next ToolCallHandler) (*ToolCallOutput, error) {
+				return nil, nil
+			}))
+		assert.ErrorContains(t, err, "tool middleware returns nil output")
+	})
+}
@@ -18,11 +18,15 @@ package compose
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"runtime/debug"
+	"strings"
 	"sync"
 
+	"github.com/getkin/kin-openapi/openapi3"
+
 	"github.com/cloudwego/eino/callbacks"
 	"github.com/cloudwego/eino/components"
 	"github.com/cloudwego/eino/components/tool"
@@ -34,6 +38,9 @@ type toolsNodeOptions struct {
 	ToolOptions   []tool.Option
 	ToolList      []tool.BaseTool
 	executedTools map[string]string
+	executedParts map[string][]schema.MessageInputPart
+	approvals     map[string]*ToolApprovalDecision
+	middlewares   []ToolMiddleware
 }
 
 // ToolsNodeOption is the option func type for ToolsNode.
@@ -53,9 +60,10 @@ func WithToolList(tool ...tool.BaseTool) ToolsNodeOption {
 	}
 }
 
-func withExecutedTools(executedTools map[string]string) ToolsNodeOption {
+func withExecutedTools(executedTools map[string]string, executedParts map[string][]schema.MessageInputPart) ToolsNodeOption {
 	return func(o *toolsNodeOptions) {
 		o.executedTools = executedTools
+		o.executedParts = executedParts
 	}
 }
 
@@ -69,9 +77,26 @@ func withExecutedTools(executedTools map[string]string) ToolsNodeOption {
 // Output: An array of ToolMessage where the order of elements corresponds to the order of ToolCalls in the input
 type ToolsNode struct {
 	tuple                *toolsTuple
+	tools                []tool.BaseTool
+	toolkits             []tool.Toolkit
 	unknownToolHandler   func(ctx context.Context, name, input string) (string, error)
 	executeSequentially  bool
 	toolArgumentsHandler func(ctx context.Context, name, input string) (string, error)
+
+	validateArguments        bool
+	repairArguments          bool
+	invalidArgumentsResponse func(ctx context.Context, name, arguments string, violations []string) (string, error)
+
+	policies *toolPolicies
+
+	permissions *toolPermissions
+
+	approvalRequiredTools map[string]struct{}
+	approvalRequiredFn    func(ctx context.Context, name, arguments string) bool
+
+	middlewares []ToolMiddleware
+
+	outputPolicies *toolOutputPolicies
 }
 
 // ToolsNodeConfig is the config for ToolsNode.
@@ -79,6 +104,11 @@ type ToolsNodeConfig struct {
 	// Tools specify the list of tools can be called which are BaseTool but must implement InvokableTool or StreamableTool.
 	Tools []tool.BaseTool
 
+	// Toolkits are listed on each run of the ToolsNode, providing the tools in addition to Tools, optional.
+	// The tools of Tools and Toolkits must have distinct names, use tool.PrefixToolkit to namespace them.
+	// Ignored when the tool list is given by WithToolList.
+	Toolkits []tool.Toolkit
+
 	// UnknownToolsHandler handles tool calls for non-existent tools when LLM hallucinates.
 	// This field is optional. When not set, calling a non-existent tool will result in an error.
 	// When provided, if the LLM attempts to call a tool that doesn't exist in the Tools list,
@@ -107,6 +137,70 @@ type ToolsNodeConfig struct {
 	//   - string: The processed arguments string to be used for tool execution
 	//   - error: Any error that occurred during preprocessing
 	ToolArgumentsHandler func(ctx context.Context, name, arguments string) (string, error)
+
+	// ValidateArguments enables validating the arguments of each tool call against the ParamsOneOf of the tool before execution,
+	// after ToolArgumentsHandler. Tools without ParamsOneOf are not validated.
+	// When the arguments are invalid, the tool is not called, and a tool message describing the violations
+	// is returned as the result instead of an error, so that the model can correct the arguments and call again.
+	ValidateArguments bool
+
+	// RepairArguments enables lenient repair of malformed json arguments before ToolArgumentsHandler,
+	// e.g. arguments wrapped in markdown code blocks, single quoted strings and trailing commas.
+	// Arguments that are valid json are left unchanged.
+	RepairArguments bool
+
+	// InvalidArgumentsResponse builds the content of the tool message for invalid arguments when ValidateArguments is set.
+	// This field is optional, a message listing the violations is used by default.
+	// Returning an error aborts the run as other tool errors do.
+	InvalidArgumentsResponse func(ctx context.Context, name, arguments string, violations []string) (string, error)
+
+	// DefaultToolPolicy is the timeout, concurrency and retry policy of the tools not in ToolPolicies, optional.
+	DefaultToolPolicy *ToolPolicy
+	// ToolPolicies are the policies by tool name, overriding DefaultToolPolicy, optional.
+	// e.g.
+	//
+	//	ToolPolicies: map[string]*ToolPolicy{
+	//		"web_search": {Timeout: 10 * time.Second, MaxConcurrency: 2, SharedAcrossRuns: true, MaxRetries: 2},
+	//		"run_code":   {Timeout: time.Minute, OnTimeout: ToolTimeoutActionReturnMessage},
+	//	}
+	ToolPolicies map[string]*ToolPolicy
+
+	// ApprovalRequiredTools are the names of the tools whose calls require human approval before execution, e.g. "send_email".
+	// When such a tool is called, the ToolsNode executes the other calls, and interrupts the run with ToolsInterruptAndRerunExtra,
+	// whose RerunExtraMap has a *ToolApprovalRequest for each call waiting for approval.
+	// Resume the run with WithToolApprovals to approve, reject or edit the calls individually.
+	// Requires the graph to be compiled with a CheckPointStore.
+	ApprovalRequiredTools []string
+	// ApprovalRequired decides whether a tool call requires human approval, in addition to ApprovalRequiredTools, optional.
+	// e.g. only deleting more than 10 records requires approval.
+	ApprovalRequired func(ctx context.Context, name, arguments string) bool
+
+	// ToolPermissions decides whether each tool call is allowed, denied or requires approval before execution,
+	// by the rules on the tool name, the calling agent, the session values and the arguments, optional.
+	// It's evaluated after ToolArgumentsHandler, and the decisions are emitted as the audit records
+	// by the callbacks with ComponentOfToolPermission.
+	// The calls requiring approval are handled as the calls of ApprovalRequiredTools.
+	ToolPermissions *ToolPermissionPolicy
+
+	// ToolMiddlewares wrap each tool call of the ToolsNode, executed in the order given, optional.
+	// Use WithToolMiddlewares to add middlewares for a single run.
+	ToolMiddlewares []ToolMiddleware
+
+	// DefaultToolOutputPolicy limits the outputs of the tools not in ToolOutputPolicies, optional.
+	DefaultToolOutputPolicy *ToolOutputPolicy
+	// ToolOutputPolicies are the output policies by tool name, overriding DefaultToolOutputPolicy, optional.
+	// e.g.
+	//
+	//	ToolOutputPolicies: map[string]*ToolOutputPolicy{
+	//		"web_search": {MaxTokens: 2000, Strategy: ToolOutputStrategySummarize, SummaryModel: cm},
+	//		"read_file":  {MaxChars: 8000, Strategy: ToolOutputStrategyPaging},
+	//	}
+	//
+	// When ToolOutputStrategyPaging is used, the ReadMoreToolName tool is registered to the ToolsNode,
+	// use ReadMoreToolInfo to bind it to the ChatModel.
+	ToolOutputPolicies map[string]*ToolOutputPolicy
+	// ToolOutputStore stores the full outputs for ToolOutputStrategyPaging, default is NewInMemoryToolOutputStore.
+	ToolOutputStore ToolOutputStore
 }
 
 // NewToolNode creates a new ToolsNode.
@@ -117,41 +211,118 @@ type ToolsNodeConfig struct {
 //	}
 //	toolsNode, err := NewToolNode(ctx, conf)
 func NewToolNode(ctx context.Context, conf *ToolsNodeConfig) (*ToolsNode, error) {
-	tuple, err := convTools(ctx, conf.Tools)
+	outputPolicies, err := newToolOutputPolicies(conf.DefaultToolOutputPolicy, conf.ToolOutputPolicies, conf.ToolOutputStore)
+	if err != nil {
+		return nil, err
+	}
+
+	tuple, err := convTools(ctx, outputPolicies.withReadMoreTool(conf.Tools), conf.ValidateArguments)
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := newToolPolicies(conf.DefaultToolPolicy, conf.ToolPolicies)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := newToolPermissions(conf.ToolPermissions)
 	if err != nil {
 		return nil, err
 	}
 
-	return &ToolsNode{
-		tuple:                tuple,
-		unknownToolHandler:   conf.UnknownToolsHandler,
-		executeSequentially:  conf.ExecuteSequentially,
-		toolArgumentsHandler: conf.ToolArgumentsHandler,
-	}, nil
+	tn := &ToolsNode{
+		tuple:                    tuple,
+		tools:                    conf.Tools,
+		toolkits:                 conf.Toolkits,
+		unknownToolHandler:       conf.UnknownToolsHandler,
+		executeSequentially:      conf.ExecuteSequentially,
+		toolArgumentsHandler:     conf.ToolArgumentsHandler,
+		validateArguments:        conf.ValidateArguments,
+		repairArguments:          conf.RepairArguments,
+		invalidArgumentsResponse: conf.InvalidArgumentsResponse,
+		policies:                 policies,
+		permissions:              permissions,
+		approvalRequiredFn:       conf.ApprovalRequired,
+		middlewares:              conf.ToolMiddlewares,
+		outputPolicies:           outputPolicies,
+	}
+	if len(conf.ApprovalRequiredTools) > 0 {
+		tn.approvalRequiredTools = make(map[string]struct{}, len(conf.ApprovalRequiredTools))
+		for _, name := range conf.ApprovalRequiredTools {
+			tn.approvalRequiredTools[name] = struct{}{}
+		}
+	}
+	if tn.invalidArgumentsResponse == nil {
+		tn.invalidArgumentsResponse = defaultInvalidArgumentsResponse
+	}
+	return tn, nil
 }
 
 type ToolsInterruptAndRerunExtra struct {
 	ToolCalls     []schema.ToolCall
 	ExecutedTools map[string]string
-	RerunTools    []string
-	RerunExtraMap map[string]any
+	// ExecutedToolParts are the multimodal parts of the executed rich tools by tool call id, restored to their tool messages on resume.
+	// The artifacts are not kept, they have been delivered to the callbacks when the tools were executed.
+	ExecutedToolParts map[string][]schema.MessageInputPart
+	RerunTools        []string
+	RerunExtraMap     map[string]any
+}
+
+// keepParts keeps the multimodal parts of the executed rich tool, so that its tool message is complete on resume.
+func (e *ToolsInterruptAndRerunExtra) keepParts(t *toolCallTask) {
+	if t.result == nil || len(t.result.Parts) == 0 {
+		return
+	}
+	if e.ExecutedToolParts == nil {
+		e.ExecutedToolParts = make(map[string][]schema.MessageInputPart)
+	}
+	e.ExecutedToolParts[t.callID] = t.result.Parts
 }
 
 func init() {
 	schema.RegisterName[*ToolsInterruptAndRerunExtra]("_eino_compose_tools_interrupt_and_rerun_extra") // TODO: check if this is really needed when refactoring adk resume
 }
 
+// getTuple returns the tools of the run, which are the tools given by WithToolList if any,
+// or the tools of the config along with the tools listed from the toolkits.
+func (tn *ToolsNode) getTuple(ctx context.Context, opt *toolsNodeOptions) (*toolsTuple, error) {
+	if opt.ToolList != nil {
+		tuple, err := convTools(ctx, tn.outputPolicies.withReadMoreTool(opt.ToolList), tn.validateArguments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert tool list from call option: %w", err)
+		}
+		return tuple, nil
+	}
+	if len(tn.toolkits) == 0 {
+		return tn.tuple, nil
+	}
+
+	tools, err := tool.MergeToolkits(append([]tool.Toolkit{tool.NewToolkit(tn.tools...)}, tn.toolkits...)...).Tools(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools from toolkits: %w", err)
+	}
+	tuple, err := convTools(ctx, tn.outputPolicies.withReadMoreTool(tools), tn.validateArguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert tools from toolkits: %w", err)
+	}
+	return tuple, nil
+}
+
 type toolsTuple struct {
 	indexes map[string]int
 	meta    []*executorMeta
 	rps     []*runnablePacker[string, string, tool.Option]
+	// validators are the schemas of the arguments, only set when validating arguments, nil for tools without ParamsOneOf.
+	validators []*openapi3.Schema
 }
 
-func convTools(ctx context.Context, tools []tool.BaseTool) (*toolsTuple, error) {
+func convTools(ctx context.Context, tools []tool.BaseTool, withValidators bool) (*toolsTuple, error) {
 	ret := &toolsTuple{
-		indexes: make(map[string]int),
-		meta:    make([]*executorMeta, len(tools)),
-		rps:     make([]*runnablePacker[string, string, tool.Option], len(tools)),
+		indexes:    make(map[string]int),
+		meta:       make([]*executorMeta, len(tools)),
+		rps:        make([]*runnablePacker[string, string, tool.Option], len(tools)),
+		validators: make([]*openapi3.Schema, len(tools)),
 	}
 	for idx, bt := range tools {
 		tl, err := bt.Info(ctx)
@@ -160,9 +331,16 @@ func convTools(ctx context.Context, tools []tool.BaseTool) (*toolsTuple, error)
 		}
 
 		toolName := tl.Name
+		if withValidators && tl.ParamsOneOf != nil {
+			if ret.validators[idx], err = tl.ParamsOneOf.ToOpenAPIV3(); err != nil {
+				return nil, fmt.Errorf("(NewToolNode) failed to convert params of tool %s to OpenAPIV3 schema: %w", toolName, err)
+			}
+		}
+
 		var (
 			st tool.StreamableTool
 			it tool.InvokableTool
+			rt tool.RichInvokableTool
 
 			invokable  func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error)
 			streamable func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error)
@@ -179,24 +357,58 @@ func convTools(ctx context.Context, tools []tool.BaseTool) (*toolsTuple, error)
 			invokable = it.InvokableRun
 		}
 
-		if st == nil && it == nil {
+		rt, _ = bt.(tool.RichInvokableTool)
+
+		if st == nil && it == nil && rt == nil {
 			return nil, fmt.Errorf("tool %s is not invokable or streamable", toolName)
 		}
 
-		if st != nil {
-			meta = parseExecutorInfoFromComponent(components.ComponentOfTool, st)
-		} else {
-			meta = parseExecutorInfoFromComponent(components.ComponentOfTool, it)
+		meta = parseExecutorInfoFromComponent(components.ComponentOfTool, bt)
+		enableCallback := !meta.isComponentCallbackEnabled
+
+		if rt != nil {
+			// the callbacks of the rich tool are triggered by richToolInvokable to deliver the rich result
+			invokable = richToolInvokable(rt, enableCallback)
+			if streamable != nil && enableCallback {
+				streamable = streamWithCallbacks(streamable)
+			}
+			enableCallback = false
 		}
 
 		ret.indexes[toolName] = idx
 		ret.meta[idx] = meta
 		ret.rps[idx] = newRunnablePacker(invokable, streamable,
-			nil, nil, !meta.isComponentCallbackEnabled)
+			nil, nil, enableCallback)
 	}
 	return ret, nil
 }
 
+// richToolInvokable adapts the rich tool to the invokable of runnablePacker, setting the rich result to the toolCallInfo in ctx.
+func richToolInvokable(rt tool.RichInvokableTool, withCallbacks bool) func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	return func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+		if withCallbacks {
+			ctx, _ = onStart[callbacks.CallbackInput](ctx, &tool.CallbackInput{ArgumentsInJSON: argumentsInJSON})
+		}
+
+		result, err := rt.RichInvokableRun(ctx, argumentsInJSON, opts...)
+		if err != nil {
+			if withCallbacks {
+				_, err = onError(ctx, err)
+			}
+			return "", err
+		}
+		if result == nil {
+			result = &tool.Result{}
+		}
+
+		if withCallbacks {
+			_, _ = onEnd[callbacks.CallbackOutput](ctx, &tool.CallbackOutput{Response: result.Content, Result: result})
+		}
+		setToolCallResult(ctx, result)
+		return result.Content, nil
+	}
+}
+
 type toolCallTask struct {
 	// in
 	r      *runnablePacker[string, string, tool.Option]
@@ -204,6 +416,16 @@ type toolCallTask struct {
 	name   string
 	arg    string
 	callID string
+	policy *toolPolicy
+	// limiter limits the concurrent calls of the tool, nil if unlimited.
+	limiter chan struct{}
+	// middlewares wrap the call, nil for the results built by ToolsNode itself.
+	middlewares []ToolMiddleware
+	// outputLimiter limits the output of the tool, nil if unlimited.
+	outputLimiter *toolOutputLimiter
+
+	// result is set when the tool implements tool.RichInvokableTool.
+	result *tool.Result
 
 	// out
 	executed bool
@@ -212,8 +434,17 @@ type toolCallTask struct {
 	err      error
 }
 
+// toolMessage builds the tool message of the task, with the multimodal parts of the rich result.
+func (t *toolCallTask) toolMessage(content string) *schema.Message {
+	msg := schema.ToolMessage(content, t.callID, schema.WithToolName(t.name))
+	if t.result != nil && len(t.result.Parts) > 0 {
+		msg.UserInputMultiContent = t.result.Parts
+	}
+	return msg
+}
+
 func (tn *ToolsNode) genToolCallTasks(ctx context.Context, tuple *toolsTuple,
-	input *schema.Message, executedTools map[string]string, isStream bool) ([]toolCallTask, error) {
+	input *schema.Message, opt *toolsNodeOptions, isStream bool) ([]toolCallTask, error) {
 
 	if input.Role != schema.Assistant {
 		return nil, fmt.Errorf("expected message role is Assistant, got %s", input.Role)
@@ -225,14 +456,21 @@ func (tn *ToolsNode) genToolCallTasks(ctx context.Context, tuple *toolsTuple,
 	}
 
 	toolCallTasks := make([]toolCallTask, n)
+	middlewares := tn.middlewares
+	if len(opt.middlewares) > 0 {
+		middlewares = append(middlewares[:len(middlewares):len(middlewares)], opt.middlewares...)
+	}
 
 	for i := 0; i < n; i++ {
 		toolCall := input.ToolCalls[i]
-		if result, executed := executedTools[toolCall.ID]; executed {
+		if result, executed := opt.executedTools[toolCall.ID]; executed {
 			toolCallTasks[i].name = toolCall.Function.Name
 			toolCallTasks[i].arg = toolCall.Function.Arguments
 			toolCallTasks[i].callID = toolCall.ID
 			toolCallTasks[i].executed = true
+			if parts := opt.executedParts[toolCall.ID]; len(parts) > 0 {
+				toolCallTasks[i].result = &tool.Result{Content: result, Parts: parts}
+			}
 			if isStream {
 				toolCallTasks[i].sOutput = schema.StreamReaderFromArray([]string{result})
 			} else {
@@ -246,22 +484,73 @@ func (tn *ToolsNode) genToolCallTasks(ctx context.Context, tuple *toolsTuple,
 				return nil, fmt.Errorf("tool %s not found in toolsNode indexes", toolCall.Function.Name)
 			}
 			toolCallTasks[i] = newUnknownToolTask(toolCall.Function.Name, toolCall.Function.Arguments, toolCall.ID, tn.unknownToolHandler)
+			toolCallTasks[i].middlewares = middlewares
 		} else {
 			toolCallTasks[i].r = tuple.rps[index]
 			toolCallTasks[i].meta = tuple.meta[index]
 			toolCallTasks[i].name = toolCall.Function.Name
 			toolCallTasks[i].callID = toolCall.ID
+			toolCallTasks[i].policy = tn.policies.get(toolCall.Function.Name)
+			toolCallTasks[i].middlewares = middlewares
+			toolCallTasks[i].outputLimiter = tn.outputPolicies.get(toolCall.Function.Name)
+			arg := toolCall.Function.Arguments
+			if tn.repairArguments && !json.Valid([]byte(arg)) {
+				if repaired := repairJSON(arg); json.Valid([]byte(repaired)) {
+					arg = repaired
+				}
+			}
 			if tn.toolArgumentsHandler != nil {
-				arg, err := tn.toolArgumentsHandler(ctx, toolCall.Function.Name, toolCall.Function.Arguments)
+				var err error
+				arg, err = tn.toolArgumentsHandler(ctx, toolCall.Function.Name, arg)
 				if err != nil {
 					return nil, fmt.Errorf("failed to executed tool[name:%s arguments:%s] arguments handler: %w", toolCall.Function.Name, toolCall.Function.Arguments, err)
 				}
-				toolCallTasks[i].arg = arg
-			} else {
-				toolCallTasks[i].arg = toolCall.Function.Arguments
+			}
+			toolCallTasks[i].arg = arg
+
+			requireApproval := false
+			if tn.permissions != nil {
+				audit := tn.permissions.decide(ctx, toolCall.ID, toolCall.Function.Name, arg, opt.approvals[toolCall.ID])
+				switch audit.Action {
+				case ToolPermissionDeny:
+					toolCallTasks[i] = newDeniedToolTask(toolCall.Function.Name, arg, toolCall.ID, audit.Message)
+					continue
+				case ToolPermissionRequireApproval:
+					requireApproval = true
+				}
+			}
+
+			if requireApproval || tn.approvalRequired(ctx, toolCall.Function.Name, arg) {
+				decision, decided := opt.approvals[toolCall.ID]
+				switch {
+				case !decided || decision == nil:
+					toolCallTasks[i] = newPendingApprovalTask(toolCall.Function.Name, arg, toolCall.ID)
+					continue
+				case !decision.Approved:
+					toolCallTasks[i] = newRejectedToolTask(toolCall.Function.Name, arg, toolCall.ID, decision.Reason)
+					continue
+				case decision.Arguments != "":
+					arg = decision.Arguments
+					toolCallTasks[i].arg = arg
+					if tn.permissions != nil {
+						// the edited arguments are checked again, so that the edit can't get past the rules
+						audit := tn.permissions.decide(ctx, toolCall.ID, toolCall.Function.Name, arg, decision)
+						if audit.Action == ToolPermissionDeny {
+							toolCallTasks[i] = newDeniedToolTask(toolCall.Function.Name, arg, toolCall.ID, audit.Message)
+							continue
+						}
+					}
+				}
+			}
+
+			if tn.validateArguments && tuple.validators[index] != nil {
+				if violations := validateToolArguments(tuple.validators[index], arg); len(violations) > 0 {
+					toolCallTasks[i] = newInvalidArgumentsTask(toolCall.Function.Name, arg, toolCall.ID, violations, tn.invalidArgumentsResponse)
+				}
 			}
 		}
 	}
+	tn.policies.limiters(toolCallTasks)
 
 	return toolCallTasks, nil
 }
@@ -282,6 +571,34 @@ func newUnknownToolTask(name, arg, callID string, unknownToolHandler func(ctx co
 	}
 }
 
+func newInvalidArgumentsTask(name, arg, callID string, violations []string,
+	response func(ctx context.Context, name, arguments string, violations []string) (string, error)) toolCallTask {
+	return newToolResultTask(name, arg, callID, "InvalidArguments", func(ctx context.Context) (string, error) {
+		return response(ctx, name, arg, violations)
+	})
+}
+
+// validateToolArguments returns the violations of the arguments against the schema, empty arguments are treated as {}.
+func validateToolArguments(validator *openapi3.Schema, arguments string) []string {
+	if strings.TrimSpace(arguments) == "" {
+		arguments = "{}"
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(arguments), &value); err != nil {
+		return []string{fmt.Sprintf("invalid json: %v", err)}
+	}
+	if err := validator.VisitJSON(value, openapi3.MultiErrors()); err != nil {
+		return schemaViolations(err)
+	}
+	return nil
+}
+
+func defaultInvalidArgumentsResponse(_ context.Context, name, _ string, violations []string) (string, error) {
+	return fmt.Sprintf("The arguments of tool %s are invalid, the tool is not called:\n- %s\nPlease fix the arguments and call the tool again.",
+		name, strings.Join(violations, "\n- ")), nil
+}
+
 func runToolCallTaskByInvoke(ctx context.Context, task *toolCallTask, opts ...tool.Option) {
 	if task.executed {
 		return
@@ -292,24 +609,31 @@ func runToolCallTaskByInvoke(ctx context.Context, task *toolCallTask, opts ...to
 		Component: task.meta.component,
 	})
 
-	ctx = setToolCallInfo(ctx, &toolCallInfo{toolCallID: task.callID})
-	task.output, task.err = task.r.Invoke(ctx, task.arg, opts...)
+	info := &toolCallInfo{toolCallID: task.callID}
+	ctx = setToolCallInfo(ctx, info)
+	task.output, task.err = invokeToolWithMiddlewares(ctx, task, opts...)
 	if task.err == nil {
 		task.executed = true
+		task.result = info.getResult()
 	}
 }
 
 func runToolCallTaskByStream(ctx context.Context, task *toolCallTask, opts ...tool.Option) {
+	if task.executed {
+		return
+	}
 	ctx = callbacks.ReuseHandlers(ctx, &callbacks.RunInfo{
 		Name:      task.name,
 		Type:      task.meta.componentImplType,
 		Component: task.meta.component,
 	})
 
-	ctx = setToolCallInfo(ctx, &toolCallInfo{toolCallID: task.callID})
-	task.sOutput, task.err = task.r.Stream(ctx, task.arg, opts...)
+	info := &toolCallInfo{toolCallID: task.callID}
+	ctx = setToolCallInfo(ctx, info)
+	task.sOutput, task.err = streamToolWithMiddlewares(ctx, task, opts...)
 	if task.err == nil {
 		task.executed = true
+		task.result = info.getResult()
 	}
 }
 
@@ -362,16 +686,12 @@ func (tn *ToolsNode) Invoke(ctx context.Context, input *schema.Message,
 	opts ...ToolsNodeOption) ([]*schema.Message, error) {
 
 	opt := getToolsNodeOptions(opts...)
-	tuple := tn.tuple
-	if opt.ToolList != nil {
-		var err error
-		tuple, err = convTools(ctx, opt.ToolList)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert tool list from call option: %w", err)
-		}
+	tuple, err := tn.getTuple(ctx, opt)
+	if err != nil {
+		return nil, err
 	}
 
-	tasks, err := tn.genToolCallTasks(ctx, tuple, input, opt.executedTools, false)
+	tasks, err := tn.genToolCallTasks(ctx, tuple, input, opt, false)
 	if err != nil {
 		return nil, err
 	}
@@ -404,9 +724,10 @@ func (tn *ToolsNode) Invoke(ctx context.Context, input *schema.Message,
 		}
 		if tasks[i].executed {
 			rerunExtra.ExecutedTools[tasks[i].callID] = tasks[i].output
+			rerunExtra.keepParts(&tasks[i])
 		}
 		if !rerun {
-			output[i] = schema.ToolMessage(tasks[i].output, tasks[i].callID, schema.WithToolName(tasks[i].name))
+			output[i] = tasks[i].toolMessage(tasks[i].output)
 		}
 	}
 	if rerun {
@@ -422,16 +743,12 @@ func (tn *ToolsNode) Stream(ctx context.Context, input *schema.Message,
 	opts ...ToolsNodeOption) (*schema.StreamReader[[]*schema.Message], error) {
 
 	opt := getToolsNodeOptions(opts...)
-	tuple := tn.tuple
-	if opt.ToolList != nil {
-		var err error
-		tuple, err = convTools(ctx, opt.ToolList)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert tool list from call option: %w", err)
-		}
+	tuple, err := tn.getTuple(ctx, opt)
+	if err != nil {
+		return nil, err
 	}
 
-	tasks, err := tn.genToolCallTasks(ctx, tuple, input, opt.executedTools, true)
+	tasks, err := tn.genToolCallTasks(ctx, tuple, input, opt, true)
 	if err != nil {
 		return nil, err
 	}
@@ -474,6 +791,7 @@ func (tn *ToolsNode) Stream(ctx context.Context, input *schema.Message,
 					return nil, fmt.Errorf("failed to concat tool[name:%s id:%s]'s stream output: %w", t.name, t.callID, err_)
 				}
 				rerunExtra.ExecutedTools[t.callID] = o
+				rerunExtra.keepParts(&t)
 			}
 		}
 		return nil, NewInterruptAndRerunErr(rerunExtra)
@@ -483,11 +801,17 @@ func (tn *ToolsNode) Stream(ctx context.Context, input *schema.Message,
 	sOutput := make([]*schema.StreamReader[[]*schema.Message], n)
 	for i := 0; i < n; i++ {
 		index := i
-		callID := tasks[i].callID
-		callName := tasks[i].name
+		task := &tasks[i]
+		first := true
 		cvt := func(s string) ([]*schema.Message, error) {
 			ret := make([]*schema.Message, n)
-			ret[index] = schema.ToolMessage(s, callID, schema.WithToolName(callName))
+			if first {
+				// the multimodal parts are only in the first chunk, as they are appended when concatenated
+				ret[index] = task.toolMessage(s)
+				first = false
+			} else {
+				ret[index] = schema.ToolMessage(s, task.callID, schema.WithToolName(task.name))
+			}
 
 			return ret, nil
 		}
@@ -514,6 +838,23 @@ func getToolsNodeOptions(opts ...ToolsNodeOption) *toolsNodeOptions {
 type toolCallInfoKey struct{}
 type toolCallInfo struct {
 	toolCallID string
+
+	mu     sync.Mutex
+	result *tool.Result
+}
+
+func (t *toolCallInfo) getResult() *tool.Result {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.result
+}
+
+func setToolCallResult(ctx context.Context, result *tool.Result) {
+	if info, ok := ctx.Value(toolCallInfoKey{}).(*toolCallInfo); ok {
+		info.mu.Lock()
+		info.result = result
+		info.mu.Unlock()
+	}
 }
 
 func setToolCallInfo(ctx context.Context, toolCallInfo *toolCallInfo) context.Context {
@@ -21,11 +21,13 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/bytedance/sonic"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/cloudwego/eino/callbacks"
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
@@ -889,3 +891,367 @@ func (m *myTool4) StreamableRun(ctx context.Context, argumentsInJSON string, opt
 	m.times++
 	return schema.StreamReaderFromArray([]string{"tool4 input: ", argumentsInJSON}), nil
 }
+
+func TestToolArgumentsValidation(t *testing.T) {
+	ctx := context.Background()
+
+	type weatherInput struct {
+		City string `json:"city" jsonschema:"required"`
+		Days int    `json:"days,omitempty"`
+	}
+	called := 0
+	weather, err := utils.InferTool("weather", "query weather", func(ctx context.Context, in *weatherInput) (string, error) {
+		called++
+		return fmt.Sprintf("%s:%d", in.City, in.Days), nil
+	})
+	assert.NoError(t, err)
+
+	input := func(args ...string) *schema.Message {
+		msg := schema.AssistantMessage("", nil)
+		for i, arg := range args {
+			msg.ToolCalls = append(msg.ToolCalls, schema.ToolCall{
+				ID:       fmt.Sprintf("call_%d", i),
+				Function: schema.FunctionCall{Name: "weather", Arguments: arg},
+			})
+		}
+		return msg
+	}
+
+	t.Run("validate", func(t *testing.T) {
+		called = 0
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools:             []tool.BaseTool{weather},
+			ValidateArguments: true,
+		})
+		assert.NoError(t, err)
+
+		output, err := tn.Invoke(ctx, input(`{"city": "Paris", "days": 2}`, `{"days": "two"}`, `{"city": 1`))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, called)
+		assert.Equal(t, "Paris:2", output[0].Content)
+		assert.Equal(t, "call_1", output[1].ToolCallID)
+		assert.Contains(t, output[1].Content, "The arguments of tool weather are invalid")
+		assert.Contains(t, output[1].Content, "/city:")
+		assert.Contains(t, output[1].Content, "/days:")
+		assert.Contains(t, output[2].Content, "invalid json")
+
+		sr, err := tn.Stream(ctx, input(`{}`))
+		assert.NoError(t, err)
+		msgs, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Contains(t, msgs[0].Content, "/city:")
+		assert.Equal(t, 1, called)
+	})
+
+	t.Run("repair and custom response", func(t *testing.T) {
+		called = 0
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools:             []tool.BaseTool{weather},
+			ValidateArguments: true,
+			RepairArguments:   true,
+			InvalidArgumentsResponse: func(ctx context.Context, name, arguments string, violations []string) (string, error) {
+				return fmt.Sprintf("%s(%s): %d violations", name, arguments, len(violations)), nil
+			},
+		})
+		assert.NoError(t, err)
+
+		output, err := tn.Invoke(ctx, input("```json\n{'city': 'Rome', 'days': 3,}\n```", `{"days": "two",}`))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, called)
+		assert.Equal(t, "Rome:3", output[0].Content)
+		assert.Equal(t, `weather({"days": "two"}): 2 violations`, output[1].Content)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{Tools: []tool.BaseTool{weather}})
+		assert.NoError(t, err)
+
+		_, err = tn.Invoke(ctx, input(`{"city": 1`))
+		assert.Error(t, err)
+	})
+}
+
+type approvalTestTool struct {
+	name  string
+	calls []string
+}
+
+func (a *approvalTestTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: a.name}, nil
+}
+
+func (a *approvalTestTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	a.calls = append(a.calls, argumentsInJSON)
+	return a.name + " " + argumentsInJSON + ";", nil
+}
+
+func TestToolApproval(t *testing.T) {
+	type myToolApprovalState struct {
+		In *schema.Message
+	}
+	schema.Register[myToolApprovalState]()
+
+	tc := []schema.ToolCall{
+		{ID: "1", Function: schema.FunctionCall{Name: "search", Arguments: `{"q":"report"}`}},
+		{ID: "2", Function: schema.FunctionCall{Name: "send_email", Arguments: `{"to":"boss"}`}},
+		{ID: "3", Function: schema.FunctionCall{Name: "send_email", Arguments: `{"to":"ceo"}`}},
+		{ID: "4", Function: schema.FunctionCall{Name: "delete", Arguments: `{"count":100}`}},
+		{ID: "5", Function: schema.FunctionCall{Name: "delete", Arguments: `{"count":1}`}},
+	}
+
+	ctx := context.Background()
+	search := &approvalTestTool{name: "search"}
+	email := &approvalTestTool{name: "send_email"}
+	del := &approvalTestTool{name: "delete"}
+	tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+		Tools:                 []tool.BaseTool{search, email, del},
+		ApprovalRequiredTools: []string{"send_email"},
+		ApprovalRequired: func(ctx context.Context, name, arguments string) bool {
+			return name == "delete" && arguments != `{"count":1}`
+		},
+	})
+	assert.NoError(t, err)
+
+	g := NewGraph[*schema.Message, string](WithGenLocalState(func(ctx context.Context) (state *myToolApprovalState) {
+		return &myToolApprovalState{In: &schema.Message{Role: schema.Assistant, ToolCalls: tc}}
+	}))
+	assert.NoError(t, g.AddToolsNode("tools", tn, WithStatePreHandler(func(ctx context.Context, in *schema.Message, state *myToolApprovalState) (*schema.Message, error) {
+		return state.In, nil
+	})))
+	assert.NoError(t, g.AddLambdaNode("concat", InvokableLambda(func(ctx context.Context, input []*schema.Message) (output string, err error) {
+		sb := strings.Builder{}
+		for _, m := range input {
+			sb.WriteString(m.Content)
+		}
+		return sb.String(), nil
+	})))
+	assert.NoError(t, g.AddEdge(START, "tools"))
+	assert.NoError(t, g.AddEdge("tools", "concat"))
+	assert.NoError(t, g.AddEdge("concat", END))
+
+	r, err := g.Compile(ctx, WithCheckPointStore(newInMemoryStore()))
+	assert.NoError(t, err)
+
+	_, err = r.Invoke(ctx, &schema.Message{Role: schema.Assistant, ToolCalls: tc}, WithCheckPointID("1"))
+	info, ok := ExtractInterruptInfo(err)
+	assert.True(t, ok)
+	assert.Equal(t, &ToolsInterruptAndRerunExtra{
+		ToolCalls:  tc,
+		RerunTools: []string{"2", "3", "4"},
+		RerunExtraMap: map[string]any{
+			"2": &ToolApprovalRequest{CallID: "2", ToolName: "send_email", Arguments: `{"to":"boss"}`},
+			"3": &ToolApprovalRequest{CallID: "3", ToolName: "send_email", Arguments: `{"to":"ceo"}`},
+			"4": &ToolApprovalRequest{CallID: "4", ToolName: "delete", Arguments: `{"count":100}`},
+		},
+		ExecutedTools: map[string]string{
+			"1": `search {"q":"report"};`,
+			"5": `delete {"count":1};`,
+		},
+	}, info.RerunNodesExtra["tools"])
+	assert.Empty(t, email.calls)
+
+	// call 4 has no decision and keeps waiting
+	_, err = r.Invoke(ctx, nil, WithCheckPointID("1"),
+		WithToolsNodeOption(WithToolApprovals(map[string]*ToolApprovalDecision{
+			"2": {Approved: true, Arguments: `{"to":"team"}`},
+			"3": {Reason: "don't email the CEO"},
+		})).DesignateNode("tools"))
+	info, ok = ExtractInterruptInfo(err)
+	assert.True(t, ok)
+	extra := info.RerunNodesExtra["tools"].(*ToolsInterruptAndRerunExtra)
+	assert.Equal(t, []string{"4"}, extra.RerunTools)
+	assert.Equal(t, `send_email {"to":"team"};`, extra.ExecutedTools["2"])
+	assert.Equal(t, "The user rejected this tool call, it's not executed. Reason: don't email the CEO", extra.ExecutedTools["3"])
+	assert.Equal(t, []string{`{"to":"team"}`}, email.calls)
+
+	sr, err := r.Stream(ctx, nil, WithCheckPointID("1"),
+		WithToolsNodeOption(WithToolApprovals(map[string]*ToolApprovalDecision{
+			"4": {Approved: true},
+		})).DesignateNode("tools"))
+	assert.NoError(t, err)
+	result, err := concatStreamReader(sr)
+	assert.NoError(t, err)
+	assert.Equal(t, `search {"q":"report"};send_email {"to":"team"};`+
+		`The user rejected this tool call, it's not executed. Reason: don't email the CEO`+
+		`delete {"count":100};delete {"count":1};`, result)
+	assert.Equal(t, []string{`{"q":"report"}`}, search.calls)
+	assert.Equal(t, []string{`{"to":"team"}`}, email.calls)
+	assert.Equal(t, []string{`{"count":1}`, `{"count":100}`}, del.calls)
+}
+
+type richTestTool struct {
+	result *tool.Result
+}
+
+func (r *richTestTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: "chart"}, nil
+}
+
+func (r *richTestTool) RichInvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*tool.Result, error) {
+	return r.result, nil
+}
+
+func TestRichTool(t *testing.T) {
+	ctx := context.Background()
+
+	chartURL := "https://example.com/chart.png"
+	parts := []schema.MessageInputPart{
+		{Type: schema.ChatMessagePartTypeText, Text: "the chart:"},
+		{Type: schema.ChatMessagePartTypeImageURL, Image: &schema.MessageInputImage{MessagePartCommon: schema.MessagePartCommon{URL: &chartURL}}},
+	}
+	chart := &richTestTool{result: &tool.Result{Content: "chart generated", Parts: parts, Artifact: map[string]int{"points": 3}}}
+
+	tn, err := NewToolNode(ctx, &ToolsNodeConfig{Tools: []tool.BaseTool{chart}})
+	assert.NoError(t, err)
+
+	var (
+		mu      sync.Mutex
+		outputs []*tool.CallbackOutput
+	)
+	handler := callbacks.NewHandlerBuilder().
+		OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+			mu.Lock()
+			outputs = append(outputs, tool.ConvCallbackOutput(output))
+			mu.Unlock()
+			return ctx
+		}).Build()
+	ctx = callbacks.InitCallbacks(ctx, &callbacks.RunInfo{}, handler)
+
+	input := schema.AssistantMessage("", []schema.ToolCall{{ID: "1", Function: schema.FunctionCall{Name: "chart", Arguments: "{}"}}})
+	output, err := tn.Invoke(ctx, input)
+	assert.NoError(t, err)
+	assert.Equal(t, "chart generated", output[0].Content)
+	assert.Equal(t, parts, output[0].UserInputMultiContent)
+	assert.Len(t, outputs, 1)
+	assert.Equal(t, "chart generated", outputs[0].Response)
+	assert.Equal(t, map[string]int{"points": 3}, outputs[0].Result.Artifact)
+
+	sr, err := tn.Stream(ctx, input)
+	assert.NoError(t, err)
+	msgs, err := concatStreamReader(sr)
+	assert.NoError(t, err)
+	assert.Equal(t, "chart generated", msgs[0].Content)
+	assert.Equal(t, parts, msgs[0].UserInputMultiContent)
+	assert.Len(t, outputs, 2)
+	assert.Equal(t, chart.result, outputs[1].Result)
+}
+
+func TestRichToolInterruptAndRerun(t *testing.T) {
+	type richToolRerunState struct {
+		In *schema.Message
+	}
+
+	schema.Register[richToolRerunState]()
+
+	ctx := context.Background()
+
+	chartURL := "https://example.com/chart.png"
+	parts := []schema.MessageInputPart{
+		{Type: schema.ChatMessagePartTypeImageURL, Image: &schema.MessageInputImage{MessagePartCommon: schema.MessagePartCommon{URL: &chartURL}}},
+	}
+	tc := []schema.ToolCall{
+		{ID: "1", Function: schema.FunctionCall{Name: "tool1", Arguments: "input"}},
+		{ID: "2", Function: schema.FunctionCall{Name: "chart", Arguments: "{}"}},
+	}
+
+	for _, stream := range []bool{false, true} {
+		chart := &richTestTool{result: &tool.Result{Content: "chart generated", Parts: parts}}
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{Tools: []tool.BaseTool{&myTool1{}, chart}})
+		assert.NoError(t, err)
+
+		g := NewGraph[*schema.Message, []*schema.Message](WithGenLocalState(func(ctx context.Context) *richToolRerunState {
+			return &richToolRerunState{In: &schema.Message{Role: schema.Assistant, ToolCalls: tc}}
+		}))
+		assert.NoError(t, g.AddToolsNode("tool node", tn, WithStatePreHandler(func(ctx context.Context, in *schema.Message, state *richToolRerunState) (*schema.Message, error) {
+			return state.In, nil
+		})))
+		assert.NoError(t, g.AddEdge(START, "tool node"))
+		assert.NoError(t, g.AddEdge("tool node", END))
+		r, err := g.Compile(ctx, WithCheckPointStore(&inMemoryStore{m: map[string][]byte{}}))
+		assert.NoError(t, err)
+
+		run := func(input *schema.Message) ([]*schema.Message, error) {
+			if !stream {
+				return r.Invoke(ctx, input, WithCheckPointID("1"))
+			}
+			sr, err := r.Stream(ctx, input, WithCheckPointID("1"))
+			if err != nil {
+				return nil, err
+			}
+			return concatStreamReader(sr)
+		}
+
+		_, err = run(&schema.Message{Role: schema.Assistant, ToolCalls: tc})
+		info, ok := ExtractInterruptInfo(err)
+		assert.True(t, ok)
+		extra := info.RerunNodesExtra["tool node"].(*ToolsInterruptAndRerunExtra)
+		assert.Equal(t, map[string]string{"2": "chart generated"}, extra.ExecutedTools)
+		assert.Equal(t, map[string][]schema.MessageInputPart{"2": parts}, extra.ExecutedToolParts)
+
+		// the chart is not executed again, and its tool message keeps the parts
+		chart.result = nil
+		output, err := run(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "tool1 input: input", output[0].Content)
+		assert.Equal(t, "chart generated", output[1].Content)
+		assert.Equal(t, parts, output[1].UserInputMultiContent)
+	}
+}
+
+func TestToolkits(t *testing.T) {
+	ctx := context.Background()
+
+	newTool := func(name string) tool.BaseTool {
+		return utils.NewTool(&schema.ToolInfo{Name: name}, func(ctx context.Context, _ map[string]any) (string, error) {
+			return name + " done", nil
+		})
+	}
+
+	var listed int
+	toolkit := tool.ToolkitFunc(func(ctx context.Context) ([]tool.BaseTool, error) {
+		listed++
+		if listed == 1 {
+			return []tool.BaseTool{newTool("a")}, nil
+		}
+		return []tool.BaseTool{newTool("b")}, nil
+	})
+
+	tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+		Tools:    []tool.BaseTool{newTool("static")},
+		Toolkits: []tool.Toolkit{toolkit},
+	})
+	assert.NoError(t, err)
+
+	call := func(name string, opts ...ToolsNodeOption) ([]*schema.Message, error) {
+		return tn.Invoke(ctx, schema.AssistantMessage("", []schema.ToolCall{
+			{ID: "1", Function: schema.FunctionCall{Name: "static", Arguments: "{}"}},
+			{ID: "2", Function: schema.FunctionCall{Name: name, Arguments: "{}"}},
+		}), opts...)
+	}
+
+	out, err := call("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "static done", out[0].Content)
+	assert.Equal(t, "a done", out[1].Content)
+
+	// the toolkit is listed again on each run
+	_, err = call("a")
+	assert.ErrorContains(t, err, "tool a not found")
+	out, err = call("b")
+	assert.NoError(t, err)
+	assert.Equal(t, "b done", out[1].Content)
+	assert.Equal(t, 3, listed)
+
+	// the tool list given by option overrides the toolkits
+	_, err = call("c", WithToolList(newTool("static"), newTool("c")))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, listed)
+
+	tn, err = NewToolNode(ctx, &ToolsNodeConfig{
+		Tools:    []tool.BaseTool{newTool("static")},
+		Toolkits: []tool.Toolkit{tool.NewToolkit(newTool("static"))},
+	})
+	assert.NoError(t, err)
+	_, err = call("static")
+	assert.ErrorContains(t, err, "duplicate tool name in toolkits: static")
+}
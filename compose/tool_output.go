@@ -0,0 +1,489 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/internal/safe"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ToolOutputStrategy determines how ToolsNode handles a tool output exceeding the limit of ToolOutputPolicy.
+type ToolOutputStrategy string
+
+const (
+	// ToolOutputStrategyTruncate keeps the beginning of the output within the limit and appends a marker, the default.
+	ToolOutputStrategyTruncate ToolOutputStrategy = "truncate"
+	// ToolOutputStrategySummarize replaces the output with a summary generated by ToolOutputPolicy.SummaryModel.
+	ToolOutputStrategySummarize ToolOutputStrategy = "summarize"
+	// ToolOutputStrategyPaging stores the full output in the ToolOutputStore, and returns its first page with a handle,
+	// the model can page through the rest by calling the ReadMoreToolName tool registered by ToolsNode.
+	ToolOutputStrategyPaging ToolOutputStrategy = "paging"
+)
+
+// ReadMoreToolName is the name of the tool registered by ToolsNode to page through the stored outputs,
+// when any ToolOutputPolicy uses ToolOutputStrategyPaging.
+const ReadMoreToolName = "read_more"
+
+// ToolOutputPolicy limits the size of the tool outputs returned to the model by ToolsNode.
+// The outputs are limited after the ToolPolicy retries, and before the ToolMiddlewares see them,
+// while the callbacks of the tool still receive the full output.
+// For StreamableTool, the chunks are passed through while the output is within the limit,
+// and the rest of the limited output is sent once the limit is exceeded, e.g. the truncate marker.
+// With ToolOutputStrategySummarize, the chunks are held back until the output ends or exceeds the limit,
+// since the summary replaces the whole output.
+type ToolOutputPolicy struct {
+	// MaxChars is the max characters of the output, 0 means unlimited.
+	MaxChars int
+	// MaxTokens is the max estimated tokens of the output, 0 means unlimited.
+	MaxTokens int
+	// TokenEstimator estimates the tokens of the text for MaxTokens, default is 1 token for every 4 bytes.
+	// For streamed outputs, the estimates on the chunks are summed while streaming, and the whole output is estimated once at the end.
+	TokenEstimator func(text string) int
+
+	// Strategy is how to handle the oversized output, default is ToolOutputStrategyTruncate.
+	Strategy ToolOutputStrategy
+	// TruncateMarker is appended to the truncated output, fmt formatted with the characters omitted and the total characters,
+	// default is "\n...[output truncated, %d of %d characters omitted]".
+	TruncateMarker string
+
+	// SummaryModel generates the summary for ToolOutputStrategySummarize, required by it.
+	// The summary still exceeding the limit is truncated.
+	SummaryModel model.BaseChatModel
+	// SummaryPrompt builds the input of SummaryModel, optional.
+	SummaryPrompt func(ctx context.Context, toolName, output string) []*schema.Message
+}
+
+// StoredToolOutput is a full tool output stored by ToolOutputStrategyPaging.
+type StoredToolOutput struct {
+	ToolName string
+	CallID   string
+	Content  string
+}
+
+// ToolOutputStore stores the full tool outputs for ToolOutputStrategyPaging.
+type ToolOutputStore interface {
+	Save(ctx context.Context, output *StoredToolOutput) (handle string, err error)
+	Load(ctx context.Context, handle string) (*StoredToolOutput, error)
+}
+
+// NewInMemoryToolOutputStore creates a ToolOutputStore keeping the outputs in memory, which are never evicted.
+func NewInMemoryToolOutputStore() ToolOutputStore {
+	return &inMemoryToolOutputStore{outputs: make(map[string]*StoredToolOutput)}
+}
+
+type inMemoryToolOutputStore struct {
+	mu      sync.RWMutex
+	outputs map[string]*StoredToolOutput
+}
+
+func (s *inMemoryToolOutputStore) Save(_ context.Context, output *StoredToolOutput) (string, error) {
+	handle := uuid.NewString()
+	s.mu.Lock()
+	s.outputs[handle] = output
+	s.mu.Unlock()
+	return handle, nil
+}
+
+func (s *inMemoryToolOutputStore) Load(_ context.Context, handle string) (*StoredToolOutput, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	output, ok := s.outputs[handle]
+	if !ok {
+		return nil, fmt.Errorf("tool output %s not found", handle)
+	}
+	return output, nil
+}
+
+const (
+	defaultToolOutputTruncateMarker = "\n...[output truncated, %d of %d characters omitted]"
+	defaultReadMorePageChars        = 4000
+)
+
+// toolOutputLimiter applies a ToolOutputPolicy to the outputs of a tool.
+type toolOutputLimiter struct {
+	*ToolOutputPolicy
+	store ToolOutputStore
+}
+
+// toolOutputPolicies resolves the output policies of the tools by name.
+type toolOutputPolicies struct {
+	byName   map[string]*toolOutputLimiter
+	fallback *toolOutputLimiter
+	store    ToolOutputStore
+	paging   bool
+}
+
+func newToolOutputPolicies(defaultPolicy *ToolOutputPolicy, byName map[string]*ToolOutputPolicy, store ToolOutputStore) (*toolOutputPolicies, error) {
+	if defaultPolicy == nil && len(byName) == 0 {
+		return nil, nil
+	}
+
+	tp := &toolOutputPolicies{byName: make(map[string]*toolOutputLimiter, len(byName)), store: store}
+	resolve := func(name string, p *ToolOutputPolicy) (*toolOutputLimiter, error) {
+		if p == nil {
+			return nil, nil
+		}
+		if p.MaxChars < 0 || p.MaxTokens < 0 {
+			return nil, fmt.Errorf("tool output policy of %s has negative limit", name)
+		}
+		switch p.Strategy {
+		case "", ToolOutputStrategyTruncate:
+		case ToolOutputStrategySummarize:
+			if p.SummaryModel == nil {
+				return nil, fmt.Errorf("tool output policy of %s requires SummaryModel to summarize", name)
+			}
+		case ToolOutputStrategyPaging:
+			tp.paging = true
+		default:
+			return nil, fmt.Errorf("tool output policy of %s has unknown strategy: %s", name, p.Strategy)
+		}
+		if p.MaxChars == 0 && p.MaxTokens == 0 {
+			return nil, nil
+		}
+		return &toolOutputLimiter{ToolOutputPolicy: p, store: store}, nil
+	}
+
+	var err error
+	if tp.fallback, err = resolve("default", defaultPolicy); err != nil {
+		return nil, err
+	}
+	for name, p := range byName {
+		if tp.byName[name], err = resolve(name, p); err != nil {
+			return nil, err
+		}
+	}
+	if tp.paging && tp.store == nil {
+		tp.store = NewInMemoryToolOutputStore()
+		for _, l := range tp.byName {
+			if l != nil {
+				l.store = tp.store
+			}
+		}
+		if tp.fallback != nil {
+			tp.fallback.store = tp.store
+		}
+	}
+	return tp, nil
+}
+
+// get returns nil if the outputs of the tool are unlimited.
+func (tp *toolOutputPolicies) get(name string) *toolOutputLimiter {
+	if tp == nil || name == ReadMoreToolName && tp.paging {
+		return nil
+	}
+	if l, ok := tp.byName[name]; ok {
+		return l
+	}
+	return tp.fallback
+}
+
+// withReadMoreTool appends the read_more tool to the tools when paging is used.
+func (tp *toolOutputPolicies) withReadMoreTool(tools []tool.BaseTool) []tool.BaseTool {
+	if tp == nil || !tp.paging {
+		return tools
+	}
+	return append(tools[:len(tools):len(tools)], &readMoreTool{policies: tp})
+}
+
+func (l *toolOutputLimiter) estimateTokens(text string) int {
+	if l.TokenEstimator != nil {
+		return l.TokenEstimator(text)
+	}
+	return (len(text) + 3) / 4
+}
+
+func (l *toolOutputLimiter) exceeds(text string) bool {
+	if l.MaxChars > 0 && utf8.RuneCountInString(text) > l.MaxChars {
+		return true
+	}
+	return l.MaxTokens > 0 && l.estimateTokens(text) > l.MaxTokens
+}
+
+// exceedsSize is exceeds on the counted size of the text, so that a stream is checked without rescanning the received text.
+// tokens is the sum of the estimates by TokenEstimator on the chunks, which is only used if TokenEstimator is set.
+func (l *toolOutputLimiter) exceedsSize(chars, bytes, tokens int) bool {
+	if l.MaxChars > 0 && chars > l.MaxChars {
+		return true
+	}
+	if l.MaxTokens <= 0 {
+		return false
+	}
+	if l.TokenEstimator == nil {
+		tokens = (bytes + 3) / 4
+	}
+	return tokens > l.MaxTokens
+}
+
+// fit returns the end of the longest text starting from start within the limit, which is at least start+1.
+func (l *toolOutputLimiter) fit(runes []rune, start int) int {
+	lo, hi := start+1, len(runes)
+	if l.MaxChars > 0 && start+l.MaxChars < hi {
+		hi = start + l.MaxChars
+	}
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if l.exceeds(string(runes[start:mid])) {
+			hi = mid - 1
+		} else {
+			lo = mid
+		}
+	}
+	return lo
+}
+
+func (l *toolOutputLimiter) limit(ctx context.Context, name, callID, output string) (string, error) {
+	if !l.exceeds(output) {
+		return output, nil
+	}
+
+	switch l.Strategy {
+	case ToolOutputStrategySummarize:
+		summary, err := l.summarize(ctx, name, output)
+		if err != nil {
+			return "", fmt.Errorf("failed to summarize output of tool %s: %w", name, err)
+		}
+		return l.truncate(summary), nil
+	case ToolOutputStrategyPaging:
+		handle, err := l.store.Save(ctx, &StoredToolOutput{ToolName: name, CallID: callID, Content: output})
+		if err != nil {
+			return "", fmt.Errorf("failed to store output of tool %s: %w", name, err)
+		}
+		return l.page(handle, []rune(output), 0), nil
+	default:
+		return l.truncate(output), nil
+	}
+}
+
+// limitStream passes the chunks of sr through while the output is within the limit,
+// once exceeded, it receives the rest of sr and sends what the limited output has beyond the chunks already sent.
+func (l *toolOutputLimiter) limitStream(ctx context.Context, name, callID string, sr *schema.StreamReader[string]) *schema.StreamReader[string] {
+	out, sw := schema.Pipe[string](0)
+	holdBack := l.Strategy == ToolOutputStrategySummarize
+
+	go func() {
+		defer func() {
+			if panicErr := recover(); panicErr != nil {
+				sw.Send("", safe.NewPanicErr(panicErr, debug.Stack()))
+			}
+			sw.Close()
+			sr.Close()
+		}()
+
+		var full strings.Builder
+		sent, exceeded := 0, false
+		chars, tokens := 0, 0
+		for {
+			chunk, err := sr.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				sw.Send("", err)
+				return
+			}
+
+			full.WriteString(chunk)
+			if exceeded {
+				continue
+			}
+			chars += utf8.RuneCountInString(chunk)
+			if l.TokenEstimator != nil && l.MaxTokens > 0 {
+				tokens += l.TokenEstimator(chunk)
+			}
+			if l.exceedsSize(chars, full.Len(), tokens) {
+				exceeded = true
+				continue
+			}
+			if holdBack {
+				continue
+			}
+			sent += len(chunk)
+			if sw.Send(chunk, nil) {
+				return
+			}
+		}
+
+		output := full.String()
+		if !exceeded && l.TokenEstimator != nil && l.MaxTokens > 0 {
+			// the estimates on the chunks may not add up to the one on the whole output
+			exceeded = l.exceeds(output)
+		}
+		if !exceeded {
+			if sent < len(output) {
+				sw.Send(output[sent:], nil)
+			}
+			return
+		}
+
+		limited, err := l.limit(ctx, name, callID, output)
+		if err != nil {
+			sw.Send("", err)
+			return
+		}
+		if !strings.HasPrefix(limited, output[:sent]) {
+			// only happens with a TokenEstimator not increasing with the text
+			total := utf8.RuneCountInString(output)
+			omitted := total - utf8.RuneCountInString(output[:sent])
+			limited = output[:sent] + fmt.Sprintf(l.truncateMarker(), omitted, total)
+		}
+		sw.Send(limited[sent:], nil)
+	}()
+
+	return out
+}
+
+func (l *toolOutputLimiter) truncateMarker() string {
+	if l.TruncateMarker != "" {
+		return l.TruncateMarker
+	}
+	return defaultToolOutputTruncateMarker
+}
+
+func (l *toolOutputLimiter) truncate(output string) string {
+	if !l.exceeds(output) {
+		return output
+	}
+	runes := []rune(output)
+	end := l.fit(runes, 0)
+	return string(runes[:end]) + fmt.Sprintf(l.truncateMarker(), len(runes)-end, len(runes))
+}
+
+func (l *toolOutputLimiter) page(handle string, runes []rune, offset int) string {
+	if offset >= len(runes) {
+		return fmt.Sprintf("[no more output, the output has %d characters]", len(runes))
+	}
+	end := l.fit(runes, offset)
+	if end >= len(runes) {
+		return string(runes[offset:]) + fmt.Sprintf("\n...[end of output, characters %d-%d of %d]", offset, len(runes), len(runes))
+	}
+	return string(runes[offset:end]) + fmt.Sprintf("\n...[showing characters %d-%d of %d, to read more, call tool %s with {\"handle\": %q, \"offset\": %d}]",
+		offset, end, len(runes), ReadMoreToolName, handle, end)
+}
+
+func (l *toolOutputLimiter) summarize(ctx context.Context, name, output string) (string, error) {
+	var input []*schema.Message
+	if l.SummaryPrompt != nil {
+		input = l.SummaryPrompt(ctx, name, output)
+	} else {
+		input = l.defaultSummaryPrompt(name, output)
+	}
+
+	generate := l.SummaryModel.Generate
+	if !components.IsCallbacksEnabled(l.SummaryModel) {
+		ctx = callbacks.ReuseHandlers(ctx, &callbacks.RunInfo{
+			Type:      componentTypeOf(l.SummaryModel),
+			Component: components.ComponentOfChatModel,
+		})
+		generate = invokeWithCallbacks(generate)
+	}
+
+	msg, err := generate(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	return msg.Content, nil
+}
+
+func (l *toolOutputLimiter) defaultSummaryPrompt(name, output string) []*schema.Message {
+	limit := fmt.Sprintf("%d characters", l.MaxChars)
+	if l.MaxChars == 0 || l.MaxTokens > 0 && l.MaxTokens*4 < l.MaxChars {
+		limit = fmt.Sprintf("%d tokens", l.MaxTokens)
+	}
+	return []*schema.Message{
+		schema.SystemMessage(fmt.Sprintf("You summarize the output of the tool %s for an AI assistant. "+
+			"Keep the facts, numbers, identifiers and errors needed to continue the task, and drop the rest. "+
+			"Reply with the summary only, within %s.", name, limit)),
+		schema.UserMessage(output),
+	}
+}
+
+// ReadMoreToolInfo returns the info of the ReadMoreToolName tool registered by the ToolsNode created by the config,
+// or nil if no ToolOutputPolicy of the config uses ToolOutputStrategyPaging.
+// Bind it to the ChatModel along with the other tools, so that the model can page through the stored outputs.
+func ReadMoreToolInfo(conf *ToolsNodeConfig) *schema.ToolInfo {
+	paging := conf.DefaultToolOutputPolicy != nil && conf.DefaultToolOutputPolicy.Strategy == ToolOutputStrategyPaging
+	for _, p := range conf.ToolOutputPolicies {
+		paging = paging || p != nil && p.Strategy == ToolOutputStrategyPaging
+	}
+	if !paging {
+		return nil
+	}
+	return readMoreToolInfo
+}
+
+var readMoreToolInfo = &schema.ToolInfo{
+	Name: ReadMoreToolName,
+	Desc: "Read more of a tool output that is too long to be returned at once, by the handle given at the end of the output.",
+	ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+		"handle": {
+			Type:     schema.String,
+			Desc:     "the handle of the tool output",
+			Required: true,
+		},
+		"offset": {
+			Type: schema.Integer,
+			Desc: "the character offset to read from",
+		},
+	}),
+}
+
+type readMoreTool struct {
+	policies *toolOutputPolicies
+}
+
+func (r *readMoreTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return readMoreToolInfo, nil
+}
+
+func (r *readMoreTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	var args struct {
+		Handle string `json:"handle"`
+		Offset int    `json:"offset"`
+	}
+	if err := sonic.UnmarshalString(argumentsInJSON, &args); err != nil {
+		return fmt.Sprintf("invalid arguments: %v", err), nil
+	}
+
+	output, err := r.policies.store.Load(ctx, args.Handle)
+	if err != nil {
+		return fmt.Sprintf("failed to load tool output %s: %v", args.Handle, err), nil
+	}
+	if args.Offset < 0 {
+		args.Offset = 0
+	}
+
+	l := r.policies.get(output.ToolName)
+	if l == nil {
+		l = &toolOutputLimiter{ToolOutputPolicy: &ToolOutputPolicy{MaxChars: defaultReadMorePageChars}}
+	}
+	return l.page(args.Handle, []rune(output.Content), args.Offset), nil
+}
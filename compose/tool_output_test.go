@@ -0,0 +1,225 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	mockModel "github.com/cloudwego/eino/internal/mock/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestToolOutputPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	long := strings.Repeat("0123456789", 10)
+	big, err := utils.InferTool("big", "big output", func(ctx context.Context, in *policyToolInput) (string, error) {
+		return long, nil
+	})
+	assert.NoError(t, err)
+	bigStream, err := utils.InferStreamTool("big_stream", "big stream output", func(ctx context.Context, in *policyToolInput) (*schema.StreamReader[string], error) {
+		return schema.StreamReaderFromArray([]string{long[:50], long[50:]}), nil
+	})
+	assert.NoError(t, err)
+	small, err := utils.InferTool("small", "small output", func(ctx context.Context, in *policyToolInput) (string, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+
+	t.Run("truncate", func(t *testing.T) {
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools:                   []tool.BaseTool{big, bigStream, small},
+			DefaultToolOutputPolicy: &ToolOutputPolicy{MaxChars: 30},
+			ToolOutputPolicies: map[string]*ToolOutputPolicy{
+				"big_stream": {MaxTokens: 5, TokenEstimator: func(text string) int { return len(text) / 2 }, TruncateMarker: "[-%d/%d]"},
+			},
+		})
+		assert.NoError(t, err)
+
+		var (
+			mu   sync.Mutex
+			full []string
+		)
+		handler := callbacks.NewHandlerBuilder().
+			OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+				mu.Lock()
+				full = append(full, tool.ConvCallbackOutput(output).Response)
+				mu.Unlock()
+				return ctx
+			}).Build()
+		cbCtx := callbacks.InitCallbacks(ctx, &callbacks.RunInfo{}, handler)
+
+		msg := schema.AssistantMessage("", []schema.ToolCall{
+			{ID: "1", Function: schema.FunctionCall{Name: "big", Arguments: "{}"}},
+			{ID: "2", Function: schema.FunctionCall{Name: "small", Arguments: "{}"}},
+		})
+		output, err := tn.Invoke(cbCtx, msg)
+		assert.NoError(t, err)
+		assert.Equal(t, long[:30]+"\n...[output truncated, 70 of 100 characters omitted]", output[0].Content)
+		assert.Equal(t, "ok", output[1].Content)
+		// callbacks receive the full output
+		assert.Contains(t, full, long)
+
+		sr, err := tn.Stream(ctx, policyToolCalls("big_stream", 1))
+		assert.NoError(t, err)
+		msgs, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, long[:11]+"[-89/100]", msgs[0].Content)
+	})
+
+	t.Run("stream passes through within the limit", func(t *testing.T) {
+		release := make(chan struct{})
+		slowStream, err := utils.InferStreamTool("slow_stream", "slow stream output", func(ctx context.Context, in *policyToolInput) (*schema.StreamReader[string], error) {
+			sr, sw := schema.Pipe[string](0)
+			go func() {
+				defer sw.Close()
+				sw.Send(long[:10], nil)
+				<-release
+				sw.Send(long[10:], nil)
+			}()
+			return sr, nil
+		})
+		assert.NoError(t, err)
+
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools:                   []tool.BaseTool{slowStream},
+			DefaultToolOutputPolicy: &ToolOutputPolicy{MaxChars: 30},
+		})
+		assert.NoError(t, err)
+
+		sr, err := tn.Stream(ctx, policyToolCalls("slow_stream", 1))
+		assert.NoError(t, err)
+		// the first chunk arrives before the tool finishes
+		first, err := sr.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, long[:10], first[0].Content)
+
+		close(release)
+		msgs, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, long[10:30]+"\n...[output truncated, 70 of 100 characters omitted]", msgs[0].Content)
+	})
+
+	t.Run("stream is counted by chunks", func(t *testing.T) {
+		chunks := make([]string, 1000)
+		for i := range chunks {
+			chunks[i] = "0123456789"
+		}
+		manyChunks, err := utils.InferStreamTool("many_chunks", "many chunks output", func(ctx context.Context, in *policyToolInput) (*schema.StreamReader[string], error) {
+			return schema.StreamReaderFromArray(chunks), nil
+		})
+		assert.NoError(t, err)
+
+		var estimated int
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools: []tool.BaseTool{manyChunks},
+			DefaultToolOutputPolicy: &ToolOutputPolicy{MaxChars: 20000, MaxTokens: 20000, TokenEstimator: func(text string) int {
+				estimated += len(text)
+				return len(text)
+			}},
+		})
+		assert.NoError(t, err)
+
+		sr, err := tn.Stream(ctx, policyToolCalls("many_chunks", 1))
+		assert.NoError(t, err)
+		msgs, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Repeat("0123456789", 1000), msgs[0].Content)
+		// each chunk is estimated once, and the whole output once at the end
+		assert.Equal(t, 2*10000, estimated)
+	})
+
+	t.Run("summarize", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cm := mockModel.NewMockBaseChatModel(ctrl)
+		cm.EXPECT().Generate(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+				assert.Contains(t, input[0].Content, "the tool big")
+				assert.Contains(t, input[0].Content, "within 20 characters")
+				assert.Equal(t, long, input[1].Content)
+				return schema.AssistantMessage("digits repeated ten times, way too long", nil), nil
+			})
+
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools:                   []tool.BaseTool{big},
+			DefaultToolOutputPolicy: &ToolOutputPolicy{MaxChars: 20, Strategy: ToolOutputStrategySummarize, SummaryModel: cm},
+		})
+		assert.NoError(t, err)
+
+		output, err := tn.Invoke(ctx, policyToolCalls("big", 1))
+		assert.NoError(t, err)
+		assert.Equal(t, "digits repeated ten \n...[output truncated, 19 of 39 characters omitted]", output[0].Content)
+	})
+
+	t.Run("paging", func(t *testing.T) {
+		conf := &ToolsNodeConfig{
+			Tools:              []tool.BaseTool{big},
+			ToolOutputPolicies: map[string]*ToolOutputPolicy{"big": {MaxChars: 40, Strategy: ToolOutputStrategyPaging}},
+		}
+		assert.Equal(t, ReadMoreToolName, ReadMoreToolInfo(conf).Name)
+		assert.Nil(t, ReadMoreToolInfo(&ToolsNodeConfig{DefaultToolOutputPolicy: &ToolOutputPolicy{MaxChars: 40}}))
+
+		tn, err := NewToolNode(ctx, conf)
+		assert.NoError(t, err)
+
+		output, err := tn.Invoke(ctx, policyToolCalls("big", 1))
+		assert.NoError(t, err)
+		content := output[0].Content
+		assert.True(t, strings.HasPrefix(content, long[:40]+"\n...[showing characters 0-40 of 100"), content)
+		handle := regexp.MustCompile(`"handle": "([^"]+)"`).FindStringSubmatch(content)[1]
+
+		readMore := func(offset int) string {
+			sr, err := tn.Stream(ctx, schema.AssistantMessage("", []schema.ToolCall{{
+				ID:       "read",
+				Function: schema.FunctionCall{Name: ReadMoreToolName, Arguments: fmt.Sprintf(`{"handle": %q, "offset": %d}`, handle, offset)},
+			}}))
+			assert.NoError(t, err)
+			msgs, err := concatStreamReader(sr)
+			assert.NoError(t, err)
+			return msgs[0].Content
+		}
+		assert.Equal(t, long[40:80]+fmt.Sprintf("\n...[showing characters 40-80 of 100, to read more, call tool read_more with {\"handle\": %q, \"offset\": 80}]", handle), readMore(40))
+		assert.Equal(t, long[80:]+"\n...[end of output, characters 80-100 of 100]", readMore(80))
+		assert.Equal(t, "[no more output, the output has 100 characters]", readMore(100))
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools:                   []tool.BaseTool{big},
+			DefaultToolOutputPolicy: &ToolOutputPolicy{MaxChars: 10, Strategy: ToolOutputStrategySummarize},
+		})
+		assert.ErrorContains(t, err, "requires SummaryModel")
+
+		_, err = NewToolNode(ctx, &ToolsNodeConfig{
+			Tools:              []tool.BaseTool{big},
+			ToolOutputPolicies: map[string]*ToolOutputPolicy{"big": {MaxChars: 10, Strategy: "drop"}},
+		})
+		assert.ErrorContains(t, err, "unknown strategy")
+	})
+}
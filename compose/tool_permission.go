@@ -0,0 +1,623 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+)
+
+// ComponentOfToolPermission is the component of the callbacks carrying the permission decisions of the tool calls,
+// whose input is *ToolPermissionRequest and output is *ToolPermissionAudit.
+// e.g.
+//
+//	handler := callbacks.NewHandlerBuilder().OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+//		if audit, ok := output.(*compose.ToolPermissionAudit); ok && info.Component == compose.ComponentOfToolPermission {
+//			log.Printf("%s called %s: %s", strings.Join(audit.Caller, "/"), audit.ToolName, audit.Action)
+//		}
+//		return ctx
+//	}).Build()
+const ComponentOfToolPermission component = "ToolPermission"
+
+// ToolPermissionAction is the decision on a tool call.
+type ToolPermissionAction string
+
+const (
+	// ToolPermissionAllow executes the tool call.
+	ToolPermissionAllow ToolPermissionAction = "allow"
+	// ToolPermissionDeny doesn't execute the tool call, and returns the message of the rule to the model as the tool result.
+	ToolPermissionDeny ToolPermissionAction = "deny"
+	// ToolPermissionRequireApproval makes the tool call wait for human approval, as ToolsNodeConfig.ApprovalRequiredTools does.
+	ToolPermissionRequireApproval ToolPermissionAction = "require_approval"
+)
+
+// ToolPermissionOp is the operator of ToolPermissionCondition.
+type ToolPermissionOp string
+
+const (
+	// ToolPermissionOpEq holds if the value equals to Value, compared in json, e.g. 1 equals to 1.0.
+	ToolPermissionOpEq ToolPermissionOp = "eq"
+	// ToolPermissionOpNe holds if the value doesn't equal to Value.
+	ToolPermissionOpNe ToolPermissionOp = "ne"
+	// ToolPermissionOpLt holds if the value is a number less than Value.
+	ToolPermissionOpLt ToolPermissionOp = "lt"
+	// ToolPermissionOpLe holds if the value is a number less than or equal to Value.
+	ToolPermissionOpLe ToolPermissionOp = "le"
+	// ToolPermissionOpGt holds if the value is a number greater than Value.
+	ToolPermissionOpGt ToolPermissionOp = "gt"
+	// ToolPermissionOpGe holds if the value is a number greater than or equal to Value.
+	ToolPermissionOpGe ToolPermissionOp = "ge"
+	// ToolPermissionOpIn holds if the value equals to any element of Value, which is a slice.
+	ToolPermissionOpIn ToolPermissionOp = "in"
+	// ToolPermissionOpNotIn holds if the value equals to none of the elements of Value, which is a slice.
+	ToolPermissionOpNotIn ToolPermissionOp = "not_in"
+	// ToolPermissionOpPrefix holds if the value is a string starting with Value.
+	ToolPermissionOpPrefix ToolPermissionOp = "prefix"
+	// ToolPermissionOpUnder holds if the value is a file path equal to or under the directory Value, after cleaning,
+	// so that "/data/../etc/passwd" is not under "/data".
+	ToolPermissionOpUnder ToolPermissionOp = "under"
+	// ToolPermissionOpExists holds if the value exists, Value is ignored.
+	ToolPermissionOpExists ToolPermissionOp = "exists"
+	// ToolPermissionOpNotExists holds if the value doesn't exist, Value is ignored.
+	ToolPermissionOpNotExists ToolPermissionOp = "not_exists"
+)
+
+// ToolPermissionCondition is a condition on a value of the arguments or the session values.
+// Path locates the value by the keys separated by ".", with "[n]" for the nth element of an array,
+// and "[*]" for all the elements, which requires the condition to hold on every element.
+// e.g. "amount", "to.account", "files[*].path".
+// Conditions on missing values don't hold, except ToolPermissionOpNe, ToolPermissionOpNotIn and ToolPermissionOpNotExists,
+// so that e.g. a deny rule on {Path: "env", Op: ToolPermissionOpNe, Value: "test"} denies the calls without env.
+type ToolPermissionCondition struct {
+	Path  string
+	Op    ToolPermissionOp
+	Value any
+}
+
+// ToolPermissionRule matches tool calls, and decides them by Action.
+// A rule matches a call if all of its criteria are met, empty criteria are always met.
+type ToolPermissionRule struct {
+	// Name identifies the rule in the audit records, optional.
+	Name string
+	// Tools are the patterns of the tool names in the syntax of path.Match, e.g. "github_*".
+	Tools []string
+	// Callers are the patterns of the run path of the calling agent, e.g. "supervisor/*",
+	// matched against the run path joined by "/", or the name of the calling agent if the pattern has no "/".
+	// The run path is set by adk, or by WithToolCallerInfo.
+	Callers []string
+	// Session are the conditions on the session values, whose paths start with the keys of the session values,
+	// e.g. {Path: "user.role", Op: ToolPermissionOpIn, Value: []string{"admin", "finance"}}.
+	Session []*ToolPermissionCondition
+	// Arguments are the conditions on the arguments of the tool call, e.g. {Path: "amount", Op: ToolPermissionOpLt, Value: 1000}.
+	// Empty arguments are taken as {}. If the arguments are not valid json, the conditions fail closed,
+	// i.e. they hold for the rules of ToolPermissionDeny and ToolPermissionRequireApproval, and don't for ToolPermissionAllow.
+	Arguments []*ToolPermissionCondition
+	// Match is the custom criterion, optional.
+	Match func(ctx context.Context, req *ToolPermissionRequest) bool
+
+	// Action is the decision on the matched calls.
+	Action ToolPermissionAction
+	// Message is returned to the model as the result of the denied calls, telling it why.
+	Message string
+}
+
+// ToolPermissionPolicy decides the tool calls by the first matched rule, or DefaultAction if no rule matches.
+// e.g.
+//
+//	&ToolPermissionPolicy{
+//		Rules: []*ToolPermissionRule{
+//			{Tools: []string{"transfer_money"}, Arguments: []*ToolPermissionCondition{{Path: "amount", Op: ToolPermissionOpLt, Value: 1000}}, Action: ToolPermissionAllow},
+//			{Tools: []string{"transfer_money"}, Action: ToolPermissionRequireApproval},
+//			{Tools: []string{"read_file"}, Arguments: []*ToolPermissionCondition{{Path: "path", Op: ToolPermissionOpUnder, Value: "/data"}}, Action: ToolPermissionAllow},
+//			{Tools: []string{"read_file"}, Action: ToolPermissionDeny, Message: "only the files under /data can be read"},
+//		},
+//	}
+type ToolPermissionPolicy struct {
+	Rules []*ToolPermissionRule
+	// DefaultAction decides the calls matching no rule, default is ToolPermissionAllow.
+	DefaultAction ToolPermissionAction
+	// DefaultDenyMessage is the message of the denied calls if the rule has no message, optional.
+	DefaultDenyMessage string
+}
+
+// ToolCallerInfo describes who is calling the tools.
+type ToolCallerInfo struct {
+	// RunPath is the names of the agents from the root agent to the agent calling the tools.
+	RunPath []string
+	// SessionValues returns the session values of the run, optional.
+	SessionValues func() map[string]any
+}
+
+type toolCallerInfoKey struct{}
+
+// WithToolCallerInfo sets the caller of the tools to ctx, used by the rules of ToolPermissionPolicy.
+// The ChatModelAgent of adk sets it with the run path and the session values of the agent.
+func WithToolCallerInfo(ctx context.Context, info *ToolCallerInfo) context.Context {
+	return context.WithValue(ctx, toolCallerInfoKey{}, info)
+}
+
+// GetToolCallerInfo gets the caller of the tools from ctx, nil if not set.
+func GetToolCallerInfo(ctx context.Context) *ToolCallerInfo {
+	info, _ := ctx.Value(toolCallerInfoKey{}).(*ToolCallerInfo)
+	return info
+}
+
+// ToolPermissionRequest is the tool call to decide.
+type ToolPermissionRequest struct {
+	CallID    string
+	ToolName  string
+	Arguments string
+	// Caller is the run path of the calling agent, empty if unknown.
+	Caller []string
+	// Session is the session values, empty if unknown.
+	Session map[string]any
+}
+
+// ToolPermissionAudit is the audit record of the decision on a tool call,
+// emitted as the output of the callbacks with ComponentOfToolPermission.
+type ToolPermissionAudit struct {
+	CallID    string
+	ToolName  string
+	Arguments string
+	Caller    []string
+	Action    ToolPermissionAction
+	// Rule is the name of the matched rule, or the index like "#1" if the rule has no name, empty if no rule matches.
+	Rule string
+	// Message is the message returned to the model for ToolPermissionDeny.
+	Message string
+	// Approval is the human decision given by WithToolApprovals for ToolPermissionRequireApproval, nil if waiting for approval.
+	Approval *ToolApprovalDecision
+	Time     time.Time
+}
+
+const defaultToolDenyMessage = "The tool call is denied by the permission policy, it's not executed."
+
+type toolPermissions struct {
+	policy *ToolPermissionPolicy
+	// args and session are the parsed paths of the conditions by rule.
+	args    [][]*toolPermissionCondition
+	session [][]*toolPermissionCondition
+}
+
+type toolPermissionCondition struct {
+	*ToolPermissionCondition
+	path []jsonPathStep
+	// value is Value normalized as json.
+	value any
+}
+
+func newToolPermissions(policy *ToolPermissionPolicy) (*toolPermissions, error) {
+	if policy == nil {
+		return nil, nil
+	}
+	if err := checkToolPermissionAction(policy.DefaultAction, true); err != nil {
+		return nil, fmt.Errorf("invalid default action of tool permission policy: %w", err)
+	}
+
+	tp := &toolPermissions{
+		policy:  policy,
+		args:    make([][]*toolPermissionCondition, len(policy.Rules)),
+		session: make([][]*toolPermissionCondition, len(policy.Rules)),
+	}
+	for i, rule := range policy.Rules {
+		if rule == nil {
+			return nil, fmt.Errorf("tool permission rule %d is nil", i)
+		}
+		if err := checkToolPermissionAction(rule.Action, false); err != nil {
+			return nil, fmt.Errorf("invalid action of tool permission rule %s: %w", ruleName(rule, i), err)
+		}
+		for _, p := range append(rule.Tools[:len(rule.Tools):len(rule.Tools)], rule.Callers...) {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q of tool permission rule %s: %w", p, ruleName(rule, i), err)
+			}
+		}
+		var err error
+		if tp.args[i], err = parseToolPermissionConditions(rule.Arguments); err != nil {
+			return nil, fmt.Errorf("invalid argument condition of tool permission rule %s: %w", ruleName(rule, i), err)
+		}
+		if tp.session[i], err = parseToolPermissionConditions(rule.Session); err != nil {
+			return nil, fmt.Errorf("invalid session condition of tool permission rule %s: %w", ruleName(rule, i), err)
+		}
+	}
+	return tp, nil
+}
+
+func checkToolPermissionAction(action ToolPermissionAction, allowEmpty bool) error {
+	switch action {
+	case ToolPermissionAllow, ToolPermissionDeny, ToolPermissionRequireApproval:
+		return nil
+	case "":
+		if allowEmpty {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown action: %q", action)
+}
+
+func ruleName(rule *ToolPermissionRule, idx int) string {
+	if rule.Name != "" {
+		return rule.Name
+	}
+	return "#" + strconv.Itoa(idx)
+}
+
+func parseToolPermissionConditions(conds []*ToolPermissionCondition) ([]*toolPermissionCondition, error) {
+	ret := make([]*toolPermissionCondition, len(conds))
+	for i, c := range conds {
+		if c == nil {
+			return nil, fmt.Errorf("condition %d is nil", i)
+		}
+		steps, err := parseJSONPath(c.Path)
+		if err != nil {
+			return nil, err
+		}
+		pc := &toolPermissionCondition{ToolPermissionCondition: c, path: steps}
+
+		switch c.Op {
+		case ToolPermissionOpExists, ToolPermissionOpNotExists:
+		case ToolPermissionOpEq, ToolPermissionOpNe, ToolPermissionOpIn, ToolPermissionOpNotIn,
+			ToolPermissionOpLt, ToolPermissionOpLe, ToolPermissionOpGt, ToolPermissionOpGe,
+			ToolPermissionOpPrefix, ToolPermissionOpUnder:
+			if pc.value, err = normalizeJSONValue(c.Value); err != nil {
+				return nil, fmt.Errorf("invalid value of condition on %s: %w", c.Path, err)
+			}
+		default:
+			return nil, fmt.Errorf("unknown op of condition on %s: %q", c.Path, c.Op)
+		}
+
+		switch c.Op {
+		case ToolPermissionOpLt, ToolPermissionOpLe, ToolPermissionOpGt, ToolPermissionOpGe:
+			if _, ok := pc.value.(float64); !ok {
+				return nil, fmt.Errorf("value of condition on %s should be a number for op %s", c.Path, c.Op)
+			}
+		case ToolPermissionOpIn, ToolPermissionOpNotIn:
+			if _, ok := pc.value.([]any); !ok {
+				return nil, fmt.Errorf("value of condition on %s should be a slice for op %s", c.Path, c.Op)
+			}
+		case ToolPermissionOpPrefix, ToolPermissionOpUnder:
+			if _, ok := pc.value.(string); !ok {
+				return nil, fmt.Errorf("value of condition on %s should be a string for op %s", c.Path, c.Op)
+			}
+		}
+		ret[i] = pc
+	}
+	return ret, nil
+}
+
+// decide evaluates the rules on the call and emits the audit record by the callbacks.
+func (tp *toolPermissions) decide(ctx context.Context, callID, name, arguments string, approval *ToolApprovalDecision) *ToolPermissionAudit {
+	req := &ToolPermissionRequest{CallID: callID, ToolName: name, Arguments: arguments}
+	if caller := GetToolCallerInfo(ctx); caller != nil {
+		req.Caller = caller.RunPath
+		if caller.SessionValues != nil {
+			req.Session = caller.SessionValues()
+		}
+	}
+
+	ctx = callbacks.ReuseHandlers(ctx, &callbacks.RunInfo{
+		Name:      name,
+		Type:      string(ComponentOfToolPermission),
+		Component: ComponentOfToolPermission,
+	})
+	ctx = callbacks.OnStart(ctx, req)
+
+	audit := &ToolPermissionAudit{
+		CallID:    callID,
+		ToolName:  name,
+		Arguments: arguments,
+		Caller:    req.Caller,
+		Action:    tp.policy.DefaultAction,
+	}
+	if audit.Action == "" {
+		audit.Action = ToolPermissionAllow
+	}
+	var args, session any
+	for i, rule := range tp.policy.Rules {
+		if !tp.match(ctx, i, req, &args, &session) {
+			continue
+		}
+		audit.Action = rule.Action
+		audit.Rule = ruleName(rule, i)
+		audit.Message = rule.Message
+		break
+	}
+
+	switch audit.Action {
+	case ToolPermissionDeny:
+		if audit.Message == "" {
+			audit.Message = tp.policy.DefaultDenyMessage
+		}
+		if audit.Message == "" {
+			audit.Message = defaultToolDenyMessage
+		}
+	case ToolPermissionRequireApproval:
+		audit.Approval = approval
+	default:
+		audit.Message = ""
+	}
+	audit.Time = time.Now()
+
+	callbacks.OnEnd(ctx, audit)
+	return audit
+}
+
+// match reports whether the ith rule matches the call, parsing the arguments and the session values into args and session lazily.
+func (tp *toolPermissions) match(ctx context.Context, i int, req *ToolPermissionRequest, args, session *any) bool {
+	rule := tp.policy.Rules[i]
+	if len(rule.Tools) > 0 && !matchPatterns(rule.Tools, req.ToolName) {
+		return false
+	}
+	if len(rule.Callers) > 0 && !matchCaller(rule.Callers, req.Caller) {
+		return false
+	}
+
+	if len(tp.session[i]) > 0 {
+		if *session == nil {
+			v, err := normalizeJSONValue(req.Session)
+			if err != nil || v == nil {
+				v = map[string]any{}
+			}
+			*session = v
+		}
+		if !checkConditions(tp.session[i], *session) {
+			return false
+		}
+	}
+
+	if len(tp.args[i]) > 0 {
+		if *args == nil {
+			*args = parseToolArguments(req.Arguments)
+		}
+		if _, ok := (*args).(invalidToolArguments); ok {
+			if rule.Action == ToolPermissionAllow {
+				return false
+			}
+		} else if !checkConditions(tp.args[i], *args) {
+			return false
+		}
+	}
+
+	return rule.Match == nil || rule.Match(ctx, req)
+}
+
+// invalidToolArguments marks the arguments that are not valid json, on which the argument conditions fail closed.
+type invalidToolArguments struct{}
+
+func parseToolArguments(arguments string) any {
+	if strings.TrimSpace(arguments) == "" {
+		return map[string]any{}
+	}
+	var v any
+	if err := json.Unmarshal([]byte(arguments), &v); err != nil {
+		return invalidToolArguments{}
+	}
+	if v == nil {
+		return map[string]any{}
+	}
+	return v
+}
+
+func matchPatterns(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchCaller(patterns []string, runPath []string) bool {
+	if len(runPath) == 0 {
+		return false
+	}
+	for _, p := range patterns {
+		target := runPath[len(runPath)-1]
+		if strings.Contains(p, "/") {
+			target = strings.Join(runPath, "/")
+		}
+		if ok, _ := path.Match(p, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func checkConditions(conds []*toolPermissionCondition, root any) bool {
+	for _, c := range conds {
+		values := resolveJSONPath(root, c.path)
+		if c.Op == ToolPermissionOpNotExists {
+			if len(values) > 0 {
+				return false
+			}
+			continue
+		}
+		if len(values) == 0 {
+			// a missing value equals to nothing
+			if c.Op == ToolPermissionOpNe || c.Op == ToolPermissionOpNotIn {
+				continue
+			}
+			return false
+		}
+		for _, v := range values {
+			if !c.check(v) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (c *toolPermissionCondition) check(v any) bool {
+	switch c.Op {
+	case ToolPermissionOpExists:
+		return true
+	case ToolPermissionOpEq:
+		return reflect.DeepEqual(v, c.value)
+	case ToolPermissionOpNe:
+		return !reflect.DeepEqual(v, c.value)
+	case ToolPermissionOpIn, ToolPermissionOpNotIn:
+		in := false
+		for _, e := range c.value.([]any) {
+			if reflect.DeepEqual(v, e) {
+				in = true
+				break
+			}
+		}
+		return in == (c.Op == ToolPermissionOpIn)
+	case ToolPermissionOpLt, ToolPermissionOpLe, ToolPermissionOpGt, ToolPermissionOpGe:
+		n, ok := v.(float64)
+		if !ok {
+			return false
+		}
+		limit := c.value.(float64)
+		switch c.Op {
+		case ToolPermissionOpLt:
+			return n < limit
+		case ToolPermissionOpLe:
+			return n <= limit
+		case ToolPermissionOpGt:
+			return n > limit
+		default:
+			return n >= limit
+		}
+	case ToolPermissionOpPrefix:
+		s, ok := v.(string)
+		return ok && strings.HasPrefix(s, c.value.(string))
+	case ToolPermissionOpUnder:
+		s, ok := v.(string)
+		if !ok || s == "" {
+			return false
+		}
+		dir := path.Clean(c.value.(string))
+		p := path.Clean(s)
+		if !path.IsAbs(p) && path.IsAbs(dir) {
+			// relative paths are resolved by the tools, which can't be known here
+			return false
+		}
+		return p == dir || strings.HasPrefix(p, strings.TrimSuffix(dir, "/")+"/")
+	}
+	return false
+}
+
+// normalizeJSONValue converts v to the values decoded from json, e.g. int to float64 and struct to map[string]any.
+func normalizeJSONValue(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var ret any
+	if err = json.Unmarshal(data, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// jsonPathStep is a key of an object, or an index of an array, where -1 means all the elements.
+type jsonPathStep struct {
+	key   string
+	index int
+	isIdx bool
+}
+
+func parseJSONPath(p string) ([]jsonPathStep, error) {
+	p = strings.TrimPrefix(strings.TrimPrefix(p, "$"), ".")
+	if p == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	var steps []jsonPathStep
+	for _, part := range strings.Split(p, ".") {
+		key := part
+		var indexes []string
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			key = part[:i]
+			rest := part[i:]
+			for rest != "" {
+				end := strings.IndexByte(rest, ']')
+				if rest[0] != '[' || end < 0 {
+					return nil, fmt.Errorf("invalid path %q", p)
+				}
+				indexes = append(indexes, rest[1:end])
+				rest = rest[end+1:]
+			}
+		}
+		if key == "" && len(indexes) == 0 {
+			return nil, fmt.Errorf("invalid path %q", p)
+		}
+		if key != "" {
+			steps = append(steps, jsonPathStep{key: key})
+		}
+		for _, idx := range indexes {
+			if idx == "*" {
+				steps = append(steps, jsonPathStep{index: -1, isIdx: true})
+				continue
+			}
+			n, err := strconv.Atoi(idx)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid index %q in path %q", idx, p)
+			}
+			steps = append(steps, jsonPathStep{index: n, isIdx: true})
+		}
+	}
+	return steps, nil
+}
+
+// resolveJSONPath returns the values located by the path, which are more than one if the path has "[*]".
+func resolveJSONPath(root any, steps []jsonPathStep) []any {
+	values := []any{root}
+	for _, step := range steps {
+		var next []any
+		for _, v := range values {
+			if !step.isIdx {
+				if obj, ok := v.(map[string]any); ok {
+					if child, ok := obj[step.key]; ok {
+						next = append(next, child)
+					}
+				}
+				continue
+			}
+			arr, ok := v.([]any)
+			if !ok {
+				continue
+			}
+			if step.index < 0 {
+				next = append(next, arr...)
+			} else if step.index < len(arr) {
+				next = append(next, arr[step.index])
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+func newDeniedToolTask(name, arg, callID, message string) toolCallTask {
+	return newToolResultTask(name, arg, callID, "DeniedTool", func(ctx context.Context) (string, error) {
+		return message, nil
+	})
+}
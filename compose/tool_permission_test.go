@@ -0,0 +1,284 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestToolPermissions(t *testing.T) {
+	policy := &ToolPermissionPolicy{
+		Rules: []*ToolPermissionRule{
+			{
+				Name:    "admin",
+				Session: []*ToolPermissionCondition{{Path: "user.role", Op: ToolPermissionOpEq, Value: "admin"}},
+				Action:  ToolPermissionAllow,
+			},
+			{
+				Name:      "small transfer",
+				Tools:     []string{"transfer_money"},
+				Callers:   []string{"root/finance"},
+				Arguments: []*ToolPermissionCondition{{Path: "amount", Op: ToolPermissionOpLt, Value: 1000}},
+				Action:    ToolPermissionAllow,
+			},
+			{
+				Tools:   []string{"transfer_money"},
+				Callers: []string{"finance"},
+				Action:  ToolPermissionRequireApproval,
+			},
+			{
+				Tools:     []string{"read_*"},
+				Arguments: []*ToolPermissionCondition{{Path: "files[*].path", Op: ToolPermissionOpUnder, Value: "/data"}},
+				Action:    ToolPermissionAllow,
+			},
+			{
+				Tools:   []string{"read_*"},
+				Action:  ToolPermissionDeny,
+				Message: "only the files under /data can be read",
+			},
+		},
+		DefaultAction: ToolPermissionDeny,
+	}
+
+	newToolsNode := func(t *testing.T) (*ToolsNode, *approvalTestTool, *approvalTestTool) {
+		transfer := &approvalTestTool{name: "transfer_money"}
+		read := &approvalTestTool{name: "read_files"}
+		tn, err := NewToolNode(context.Background(), &ToolsNodeConfig{
+			Tools:           []tool.BaseTool{transfer, read, &approvalTestTool{name: "search"}},
+			ToolPermissions: policy,
+		})
+		assert.NoError(t, err)
+		return tn, transfer, read
+	}
+
+	var mu sync.Mutex
+	var audits []*ToolPermissionAudit
+	handler := callbacks.NewHandlerBuilder().
+		OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+			if a, ok := output.(*ToolPermissionAudit); ok && info.Component == ComponentOfToolPermission {
+				mu.Lock()
+				audits = append(audits, a)
+				mu.Unlock()
+			}
+			return ctx
+		}).Build()
+	newCtx := func(runPath []string, session map[string]any) context.Context {
+		audits = nil
+		ctx := callbacks.InitCallbacks(context.Background(), &callbacks.RunInfo{}, handler)
+		return WithToolCallerInfo(ctx, &ToolCallerInfo{
+			RunPath:       runPath,
+			SessionValues: func() map[string]any { return session },
+		})
+	}
+
+	t.Run("allow and deny", func(t *testing.T) {
+		tn, transfer, read := newToolsNode(t)
+		ctx := newCtx([]string{"root", "finance"}, nil)
+		out, err := tn.Invoke(ctx, schema.AssistantMessage("", []schema.ToolCall{
+			{ID: "1", Function: schema.FunctionCall{Name: "transfer_money", Arguments: `{"amount": 100}`}},
+			{ID: "2", Function: schema.FunctionCall{Name: "read_files", Arguments: `{"files": [{"path": "/data/a"}, {"path": "/data/../etc/passwd"}]}`}},
+			{ID: "3", Function: schema.FunctionCall{Name: "read_files", Arguments: `{"files": [{"path": "/data/a"}, {"path": "/data/b/c"}]}`}},
+			{ID: "4", Function: schema.FunctionCall{Name: "search", Arguments: `{}`}},
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, `transfer_money {"amount": 100};`, out[0].Content)
+		assert.Equal(t, "only the files under /data can be read", out[1].Content)
+		assert.Equal(t, `read_files {"files": [{"path": "/data/a"}, {"path": "/data/b/c"}]};`, out[2].Content)
+		assert.Equal(t, defaultToolDenyMessage, out[3].Content)
+		assert.Equal(t, []string{`{"amount": 100}`}, transfer.calls)
+		assert.Len(t, read.calls, 1)
+
+		assert.Len(t, audits, 4)
+		byID := make(map[string]*ToolPermissionAudit)
+		for _, a := range audits {
+			byID[a.CallID] = a
+		}
+		assert.Equal(t, ToolPermissionAllow, byID["1"].Action)
+		assert.Equal(t, "small transfer", byID["1"].Rule)
+		assert.Equal(t, []string{"root", "finance"}, byID["1"].Caller)
+		assert.Equal(t, ToolPermissionDeny, byID["2"].Action)
+		assert.Equal(t, "#4", byID["2"].Rule)
+		assert.Equal(t, ToolPermissionAllow, byID["3"].Action)
+		assert.Equal(t, "#3", byID["3"].Rule)
+		assert.Equal(t, ToolPermissionDeny, byID["4"].Action)
+		assert.Equal(t, "", byID["4"].Rule)
+		assert.False(t, byID["4"].Time.IsZero())
+	})
+
+	t.Run("caller and session", func(t *testing.T) {
+		tn, transfer, _ := newToolsNode(t)
+		msg := schema.AssistantMessage("", []schema.ToolCall{
+			{ID: "1", Function: schema.FunctionCall{Name: "transfer_money", Arguments: `{"amount": 100}`}},
+		})
+
+		// the small transfer is only allowed for root/finance
+		out, err := tn.Invoke(newCtx([]string{"root", "support"}, nil), msg)
+		assert.NoError(t, err)
+		assert.Equal(t, defaultToolDenyMessage, out[0].Content)
+
+		out, err = tn.Invoke(newCtx([]string{"root", "support"}, map[string]any{"user": map[string]any{"role": "admin"}}), msg)
+		assert.NoError(t, err)
+		assert.Equal(t, `transfer_money {"amount": 100};`, out[0].Content)
+		assert.Equal(t, "admin", audits[0].Rule)
+		assert.Len(t, transfer.calls, 1)
+	})
+
+	t.Run("require approval", func(t *testing.T) {
+		tn, transfer, _ := newToolsNode(t)
+		msg := schema.AssistantMessage("", []schema.ToolCall{
+			{ID: "1", Function: schema.FunctionCall{Name: "transfer_money", Arguments: `{"amount": 5000}`}},
+		})
+
+		_, err := tn.Invoke(newCtx([]string{"other", "finance"}, nil), msg)
+		extra, ok := IsInterruptRerunError(err)
+		assert.True(t, ok)
+		assert.Equal(t, &ToolApprovalRequest{CallID: "1", ToolName: "transfer_money", Arguments: `{"amount": 5000}`},
+			extra.(*ToolsInterruptAndRerunExtra).RerunExtraMap["1"])
+		assert.Equal(t, ToolPermissionRequireApproval, audits[0].Action)
+		assert.Nil(t, audits[0].Approval)
+		assert.Empty(t, transfer.calls)
+
+		decision := &ToolApprovalDecision{Approved: true}
+		out, err := tn.Invoke(newCtx([]string{"other", "finance"}, nil), msg,
+			WithToolApprovals(map[string]*ToolApprovalDecision{"1": decision}))
+		assert.NoError(t, err)
+		assert.Equal(t, `transfer_money {"amount": 5000};`, out[0].Content)
+		assert.Equal(t, decision, audits[0].Approval)
+	})
+
+	t.Run("edited arguments", func(t *testing.T) {
+		transfer := &approvalTestTool{name: "transfer_money"}
+		tn, err := NewToolNode(context.Background(), &ToolsNodeConfig{
+			Tools:                 []tool.BaseTool{transfer},
+			ApprovalRequiredTools: []string{"transfer_money"},
+			ToolPermissions: &ToolPermissionPolicy{Rules: []*ToolPermissionRule{{
+				Name:      "large transfer",
+				Tools:     []string{"transfer_money"},
+				Arguments: []*ToolPermissionCondition{{Path: "amount", Op: ToolPermissionOpGe, Value: 10000}},
+				Action:    ToolPermissionDeny,
+				Message:   "large transfers are not allowed",
+			}}},
+		})
+		assert.NoError(t, err)
+		msg := schema.AssistantMessage("", []schema.ToolCall{
+			{ID: "1", Function: schema.FunctionCall{Name: "transfer_money", Arguments: `{"amount": 100}`}},
+		})
+
+		_, err = tn.Invoke(newCtx(nil, nil), msg)
+		_, ok := IsInterruptRerunError(err)
+		assert.True(t, ok)
+
+		out, err := tn.Invoke(newCtx(nil, nil), msg, WithToolApprovals(map[string]*ToolApprovalDecision{
+			"1": {Approved: true, Arguments: `{"amount": 50000}`},
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, "large transfers are not allowed", out[0].Content)
+		assert.Empty(t, transfer.calls)
+		assert.Len(t, audits, 2)
+		assert.Equal(t, `{"amount": 50000}`, audits[1].Arguments)
+		assert.Equal(t, "large transfer", audits[1].Rule)
+
+		out, err = tn.Invoke(newCtx(nil, nil), msg, WithToolApprovals(map[string]*ToolApprovalDecision{
+			"1": {Approved: true, Arguments: `{"amount": 200}`},
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, `transfer_money {"amount": 200};`, out[0].Content)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		for _, p := range []*ToolPermissionPolicy{
+			{DefaultAction: "ask"},
+			{Rules: []*ToolPermissionRule{{Action: "ask"}}},
+			{Rules: []*ToolPermissionRule{{Tools: []string{"["}, Action: ToolPermissionAllow}}},
+			{Rules: []*ToolPermissionRule{{Arguments: []*ToolPermissionCondition{{Path: "a[x]", Op: ToolPermissionOpExists}}, Action: ToolPermissionAllow}}},
+			{Rules: []*ToolPermissionRule{{Arguments: []*ToolPermissionCondition{{Path: "a", Op: ToolPermissionOpLt, Value: "1"}}, Action: ToolPermissionAllow}}},
+			{Rules: []*ToolPermissionRule{{Session: []*ToolPermissionCondition{{Path: "a", Op: "like"}}, Action: ToolPermissionAllow}}},
+		} {
+			_, err := NewToolNode(context.Background(), &ToolsNodeConfig{ToolPermissions: p})
+			assert.Error(t, err)
+		}
+	})
+}
+
+func TestToolPermissionConditions(t *testing.T) {
+	conds, err := parseToolPermissionConditions([]*ToolPermissionCondition{
+		{Path: "$.to.account", Op: ToolPermissionOpPrefix, Value: "ACME-"},
+		{Path: "currency", Op: ToolPermissionOpIn, Value: []string{"USD", "EUR"}},
+		{Path: "items[0].qty", Op: ToolPermissionOpLe, Value: 10},
+		{Path: "note", Op: ToolPermissionOpNotExists},
+	})
+	assert.NoError(t, err)
+
+	check := func(args string) bool {
+		tp := &toolPermissions{
+			policy:  &ToolPermissionPolicy{Rules: []*ToolPermissionRule{{Action: ToolPermissionAllow}}},
+			args:    [][]*toolPermissionCondition{conds},
+			session: [][]*toolPermissionCondition{nil},
+		}
+		var parsedArgs, session any
+		return tp.match(context.Background(), 0, &ToolPermissionRequest{Arguments: args}, &parsedArgs, &session)
+	}
+
+	assert.True(t, check(`{"to": {"account": "ACME-1"}, "currency": "USD", "items": [{"qty": 10}, {"qty": 99}]}`))
+	assert.False(t, check(`{"to": {"account": "EVIL-1"}, "currency": "USD", "items": [{"qty": 10}]}`))
+	assert.False(t, check(`{"to": {"account": "ACME-1"}, "currency": "JPY", "items": [{"qty": 10}]}`))
+	assert.False(t, check(`{"to": {"account": "ACME-1"}, "currency": "USD", "items": [{"qty": 11}]}`))
+	assert.False(t, check(`{"to": {"account": "ACME-1"}, "currency": "USD", "items": [{"qty": 1}], "note": "x"}`))
+	assert.False(t, check(`{"to": {"account": "ACME-1"}, "currency": "USD"}`))
+	assert.False(t, check(`not json`))
+}
+
+func TestToolPermissionConditionsOnMissingValues(t *testing.T) {
+	tp, err := newToolPermissions(&ToolPermissionPolicy{
+		Rules: []*ToolPermissionRule{
+			{Tools: []string{"deploy"}, Arguments: []*ToolPermissionCondition{{Path: "env", Op: ToolPermissionOpNe, Value: "test"}}, Action: ToolPermissionDeny},
+			{Tools: []string{"delete"}, Arguments: []*ToolPermissionCondition{{Path: "path", Op: ToolPermissionOpNotIn, Value: []string{"/tmp/a", "/tmp/b"}}}, Action: ToolPermissionDeny},
+			{Tools: []string{"transfer"}, Arguments: []*ToolPermissionCondition{{Path: "amount", Op: ToolPermissionOpLt, Value: 1000}}, Action: ToolPermissionAllow},
+			{Tools: []string{"transfer"}, Arguments: []*ToolPermissionCondition{{Path: "amount", Op: ToolPermissionOpGe, Value: 1000}}, Action: ToolPermissionRequireApproval},
+		},
+	})
+	assert.NoError(t, err)
+
+	decide := func(name, args string) ToolPermissionAction {
+		return tp.decide(context.Background(), "call-1", name, args, nil).Action
+	}
+
+	t.Run("missing value satisfies ne and not_in", func(t *testing.T) {
+		assert.Equal(t, ToolPermissionAllow, decide("deploy", `{"env": "test"}`))
+		assert.Equal(t, ToolPermissionDeny, decide("deploy", `{"env": "prod"}`))
+		assert.Equal(t, ToolPermissionDeny, decide("deploy", `{}`))
+		assert.Equal(t, ToolPermissionDeny, decide("deploy", ``))
+		assert.Equal(t, ToolPermissionAllow, decide("delete", `{"path": "/tmp/a"}`))
+		assert.Equal(t, ToolPermissionDeny, decide("delete", `{"path": "/etc/passwd"}`))
+		assert.Equal(t, ToolPermissionDeny, decide("delete", `{}`))
+	})
+
+	t.Run("invalid arguments fail closed", func(t *testing.T) {
+		assert.Equal(t, ToolPermissionDeny, decide("deploy", `{"env": "test"`))
+		assert.Equal(t, ToolPermissionRequireApproval, decide("transfer", `{"amount": 1`))
+		assert.Equal(t, ToolPermissionAllow, decide("transfer", `{"amount": 1}`))
+		assert.Equal(t, ToolPermissionRequireApproval, decide("transfer", `{"amount": 1000}`))
+	})
+}
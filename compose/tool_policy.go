@@ -0,0 +1,364 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/internal/safe"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ErrToolTimeout is the error of a tool call exceeding ToolPolicy.Timeout.
+var ErrToolTimeout = errors.New("tool call timeout")
+
+// ToolTimeoutAction determines what to do when a tool call exceeds ToolPolicy.Timeout.
+type ToolTimeoutAction string
+
+const (
+	// ToolTimeoutActionFail fails the run with ErrToolTimeout, the default.
+	ToolTimeoutActionFail ToolTimeoutAction = "fail"
+	// ToolTimeoutActionReturnMessage returns a tool message telling the model the tool call timed out, so that the run goes on.
+	ToolTimeoutActionReturnMessage ToolTimeoutAction = "return_message"
+)
+
+// ToolPolicy controls how the calls of a tool are executed by ToolsNode.
+type ToolPolicy struct {
+	// Timeout is the max duration of each attempt of a tool call, 0 means no timeout.
+	// The tool receives a ctx with the deadline, but the call returns at the deadline even if the tool ignores ctx.
+	// For StreamableTool, it bounds both creating the stream and reading it, a stream exceeding it receives schema.ErrStreamTimeout.
+	Timeout time.Duration
+	// OnTimeout determines what to do when a tool call still times out after the retries, default is ToolTimeoutActionFail.
+	// For StreamableTool, it only applies to creating the stream.
+	OnTimeout ToolTimeoutAction
+	// TimeoutMessage is the content of the tool message for ToolTimeoutActionReturnMessage,
+	// default is "tool {name} timed out after {timeout}, try again later or use another way".
+	TimeoutMessage string
+
+	// MaxConcurrency is the max concurrent calls of the tool, 0 means unlimited.
+	// By default, it limits the calls in the same tool call message, set SharedAcrossRuns to limit all the calls of the ToolsNode.
+	// For StreamableTool, the call is counted until the stream is created.
+	MaxConcurrency int
+	// SharedAcrossRuns makes MaxConcurrency shared by all the runs of the ToolsNode, e.g. for a rate limited API.
+	SharedAcrossRuns bool
+
+	// MaxRetries is the max times to retry a failed call, 0 means no retry.
+	MaxRetries int
+	// Retryable reports whether a failed call should be retried, tool.IsRetryableError by default.
+	// Set it to also retry ErrToolTimeout, e.g. func(err error) bool { return tool.IsRetryableError(err) || errors.Is(err, compose.ErrToolTimeout) }.
+	Retryable func(err error) bool
+	// Backoff returns the wait before the nth retry, starting from 1, default is 100ms * 2^(n-1), capped at 10s.
+	Backoff func(attempt int) time.Duration
+}
+
+const (
+	defaultToolRetryBackoff    = 100 * time.Millisecond
+	maxDefaultToolRetryBackoff = 10 * time.Second
+)
+
+// toolPolicy is the resolved ToolPolicy of a tool.
+type toolPolicy struct {
+	*ToolPolicy
+	name string
+	// sharedLimiter is set when SharedAcrossRuns, otherwise the limiter is created for each tool call message.
+	sharedLimiter chan struct{}
+}
+
+// toolPolicies resolves the policies of the tools by name.
+type toolPolicies struct {
+	defaultPolicy *ToolPolicy
+	byName        map[string]*ToolPolicy
+
+	mu       sync.Mutex
+	resolved map[string]*toolPolicy
+}
+
+func newToolPolicies(defaultPolicy *ToolPolicy, byName map[string]*ToolPolicy) (*toolPolicies, error) {
+	check := func(name string, p *ToolPolicy) error {
+		if p == nil {
+			return nil
+		}
+		if p.Timeout < 0 || p.MaxConcurrency < 0 || p.MaxRetries < 0 {
+			return fmt.Errorf("tool policy of %s has negative value", name)
+		}
+		switch p.OnTimeout {
+		case "", ToolTimeoutActionFail, ToolTimeoutActionReturnMessage:
+		default:
+			return fmt.Errorf("tool policy of %s has unknown timeout action: %s", name, p.OnTimeout)
+		}
+		return nil
+	}
+
+	if err := check("default", defaultPolicy); err != nil {
+		return nil, err
+	}
+	for name, p := range byName {
+		if err := check(name, p); err != nil {
+			return nil, err
+		}
+	}
+	if defaultPolicy == nil && len(byName) == 0 {
+		return nil, nil
+	}
+
+	return &toolPolicies{
+		defaultPolicy: defaultPolicy,
+		byName:        byName,
+		resolved:      make(map[string]*toolPolicy),
+	}, nil
+}
+
+// get returns nil if the tool has no policy.
+func (tp *toolPolicies) get(name string) *toolPolicy {
+	if tp == nil {
+		return nil
+	}
+
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	if p, ok := tp.resolved[name]; ok {
+		return p
+	}
+
+	var p *toolPolicy
+	policy, ok := tp.byName[name]
+	if !ok {
+		policy = tp.defaultPolicy
+	}
+	if policy != nil {
+		p = &toolPolicy{ToolPolicy: policy, name: name}
+		if policy.SharedAcrossRuns && policy.MaxConcurrency > 0 {
+			p.sharedLimiter = make(chan struct{}, policy.MaxConcurrency)
+		}
+	}
+	tp.resolved[name] = p
+	return p
+}
+
+// limiters creates the limiters of the tools for a tool call message.
+func (tp *toolPolicies) limiters(tasks []toolCallTask) {
+	local := make(map[string]chan struct{})
+	for i := range tasks {
+		p := tasks[i].policy
+		if p == nil || p.MaxConcurrency <= 0 {
+			continue
+		}
+		if p.sharedLimiter != nil {
+			tasks[i].limiter = p.sharedLimiter
+			continue
+		}
+		if _, ok := local[p.name]; !ok {
+			local[p.name] = make(chan struct{}, p.MaxConcurrency)
+		}
+		tasks[i].limiter = local[p.name]
+	}
+}
+
+func acquireToolLimiter(ctx context.Context, limiter chan struct{}) (release func(), err error) {
+	if limiter == nil {
+		return func() {}, nil
+	}
+	select {
+	case limiter <- struct{}{}:
+		return func() { <-limiter }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *toolPolicy) retryable(err error) bool {
+	if _, ok := IsInterruptRerunError(err); ok {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return tool.IsRetryableError(err)
+}
+
+func (p *toolPolicy) backoff(attempt int) time.Duration {
+	if p.Backoff != nil {
+		return p.Backoff(attempt)
+	}
+	d := defaultToolRetryBackoff
+	for i := 1; i < attempt && d < maxDefaultToolRetryBackoff; i++ {
+		d *= 2
+	}
+	if d > maxDefaultToolRetryBackoff {
+		d = maxDefaultToolRetryBackoff
+	}
+	return d
+}
+
+func (p *toolPolicy) timeoutMessage() string {
+	if p.TimeoutMessage != "" {
+		return p.TimeoutMessage
+	}
+	return fmt.Sprintf("tool %s timed out after %s, try again later or use another way", p.name, p.Timeout)
+}
+
+// runToolWithPolicy runs call with the concurrency limit, timeout and retries of the task's policy.
+// bind is called with the successful output and the cancel func of the timeout context,
+// it's for outputs still being consumed after return, e.g. streams, which should cancel when they are done.
+// If bind is nil, the timeout context is canceled on return.
+func runToolWithPolicy[T any](ctx context.Context, task *toolCallTask, call func(ctx context.Context) (T, error),
+	bind func(output T, cancel context.CancelFunc) T) (output T, err error) {
+	p := task.policy
+	if p == nil {
+		return call(ctx)
+	}
+
+	release, err := acquireToolLimiter(ctx, task.limiter)
+	if err != nil {
+		return output, err
+	}
+	defer release()
+
+	for attempt := 0; ; attempt++ {
+		output, err = runToolWithTimeout(ctx, p.Timeout, call, bind)
+		if err == nil || attempt >= p.MaxRetries || !p.retryable(err) {
+			return output, err
+		}
+
+		timer := time.NewTimer(p.backoff(attempt + 1))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return output, err
+		}
+	}
+}
+
+func runToolWithTimeout[T any](ctx context.Context, timeout time.Duration, call func(ctx context.Context) (T, error),
+	bind func(output T, cancel context.CancelFunc) T) (output T, err error) {
+	if timeout <= 0 {
+		return call(ctx)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+
+	type result struct {
+		output T
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if panicErr := recover(); panicErr != nil {
+				done <- result{err: safe.NewPanicErr(panicErr, debug.Stack())}
+			}
+		}()
+		o, e := call(callCtx)
+		done <- result{output: o, err: e}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil || bind == nil {
+			cancel()
+			if r.err != nil && ctx.Err() == nil && errors.Is(r.err, context.DeadlineExceeded) {
+				r.err = fmt.Errorf("%w after %s: %v", ErrToolTimeout, timeout, r.err)
+			}
+			return r.output, r.err
+		}
+		return bind(r.output, cancel), nil
+	case <-callCtx.Done():
+		cancel()
+		go func() {
+			// nobody receives the output returned after the timeout, close it if it's a stream
+			r := <-done
+			if sr, ok := any(r.output).(*schema.StreamReader[string]); ok && r.err == nil && sr != nil {
+				sr.Close()
+			}
+		}()
+		if ctx.Err() != nil {
+			return output, ctx.Err()
+		}
+		return output, fmt.Errorf("%w after %s", ErrToolTimeout, timeout)
+	}
+}
+
+// streamWithCloseHook returns a StreamReader forwarding sr, hook is called once sr reaches io.EOF or the returned StreamReader is closed.
+// If the returned StreamReader is closed while sr is blocked in Recv, hook is called once the pending Recv returns.
+func streamWithCloseHook[T any](sr *schema.StreamReader[T], hook func()) *schema.StreamReader[T] {
+	out, sw := schema.Pipe[T](0)
+	go func() {
+		defer func() {
+			if panicErr := recover(); panicErr != nil {
+				sw.Send(*new(T), safe.NewPanicErr(panicErr, debug.Stack()))
+			}
+			sw.Close()
+			sr.Close()
+			hook()
+		}()
+
+		for {
+			chunk, err := sr.Recv()
+			if err == io.EOF {
+				return
+			}
+			if sw.Send(chunk, err) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func invokeToolWithPolicy(ctx context.Context, task *toolCallTask, arg string, opts ...tool.Option) (string, error) {
+	output, err := runToolWithPolicy(ctx, task, func(ctx context.Context) (string, error) {
+		return task.r.Invoke(ctx, arg, opts...)
+	}, nil)
+	if err != nil && task.policy != nil && task.policy.OnTimeout == ToolTimeoutActionReturnMessage && errors.Is(err, ErrToolTimeout) {
+		return task.policy.timeoutMessage(), nil
+	}
+	return output, err
+}
+
+func streamToolWithPolicy(ctx context.Context, task *toolCallTask, arg string, opts ...tool.Option) (*schema.StreamReader[string], error) {
+	var start time.Time
+	output, err := runToolWithPolicy(ctx, task, func(ctx context.Context) (*schema.StreamReader[string], error) {
+		start = time.Now()
+		return task.r.Stream(ctx, arg, opts...)
+	}, func(output *schema.StreamReader[string], cancel context.CancelFunc) *schema.StreamReader[string] {
+		return streamWithCloseHook(output, cancel)
+	})
+	if err != nil {
+		if task.policy != nil && task.policy.OnTimeout == ToolTimeoutActionReturnMessage && errors.Is(err, ErrToolTimeout) {
+			return schema.StreamReaderFromArray([]string{task.policy.timeoutMessage()}), nil
+		}
+		return nil, err
+	}
+
+	if task.policy != nil && task.policy.Timeout > 0 {
+		remaining := task.policy.Timeout - time.Since(start)
+		if remaining <= 0 {
+			remaining = time.Nanosecond
+		}
+		output = schema.StreamReaderWithTimeout(ctx, output, 0, remaining)
+	}
+	return output, nil
+}
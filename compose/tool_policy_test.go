@@ -0,0 +1,280 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/schema"
+)
+
+type policyToolInput struct {
+	Value string `json:"value"`
+}
+
+func policyToolCalls(name string, n int) *schema.Message {
+	msg := schema.AssistantMessage("", nil)
+	for i := 0; i < n; i++ {
+		msg.ToolCalls = append(msg.ToolCalls, schema.ToolCall{
+			ID:       fmt.Sprintf("call_%d", i),
+			Function: schema.FunctionCall{Name: name, Arguments: fmt.Sprintf(`{"value": "%d"}`, i)},
+		})
+	}
+	return msg
+}
+
+func TestToolPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("timeout", func(t *testing.T) {
+		slow, err := utils.InferTool("slow", "slow tool", func(ctx context.Context, in *policyToolInput) (string, error) {
+			time.Sleep(200 * time.Millisecond) // ignores ctx
+			return "done", nil
+		})
+		assert.NoError(t, err)
+
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools:             []tool.BaseTool{slow},
+			DefaultToolPolicy: &ToolPolicy{Timeout: 20 * time.Millisecond},
+		})
+		assert.NoError(t, err)
+		start := time.Now()
+		_, err = tn.Invoke(ctx, policyToolCalls("slow", 1))
+		assert.True(t, errors.Is(err, ErrToolTimeout))
+		assert.Less(t, time.Since(start), 150*time.Millisecond)
+
+		tn, err = NewToolNode(ctx, &ToolsNodeConfig{
+			Tools: []tool.BaseTool{slow},
+			ToolPolicies: map[string]*ToolPolicy{
+				"slow": {Timeout: 20 * time.Millisecond, OnTimeout: ToolTimeoutActionReturnMessage},
+			},
+		})
+		assert.NoError(t, err)
+		output, err := tn.Invoke(ctx, policyToolCalls("slow", 1))
+		assert.NoError(t, err)
+		assert.Equal(t, "tool slow timed out after 20ms, try again later or use another way", output[0].Content)
+
+		sr, err := tn.Stream(ctx, policyToolCalls("slow", 1))
+		assert.NoError(t, err)
+		msgs, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Contains(t, msgs[0].Content, "timed out")
+	})
+
+	t.Run("stream timeout", func(t *testing.T) {
+		streaming, err := utils.InferStreamTool("streaming", "streaming tool", func(ctx context.Context, in *policyToolInput) (*schema.StreamReader[string], error) {
+			sr, sw := schema.Pipe[string](1)
+			go func() {
+				defer sw.Close()
+				sw.Send("a", nil)
+				<-ctx.Done()
+			}()
+			return sr, nil
+		})
+		assert.NoError(t, err)
+
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools:             []tool.BaseTool{streaming},
+			DefaultToolPolicy: &ToolPolicy{Timeout: 30 * time.Millisecond},
+		})
+		assert.NoError(t, err)
+		sr, err := tn.Stream(ctx, policyToolCalls("streaming", 1))
+		assert.NoError(t, err)
+		_, err = concatStreamReader(sr)
+		assert.True(t, errors.Is(err, schema.ErrStreamTimeout))
+	})
+
+	t.Run("timeout context released", func(t *testing.T) {
+		invokeCtx := make(chan context.Context, 1)
+		fast, err := utils.InferTool("fast", "fast tool", func(ctx context.Context, in *policyToolInput) (string, error) {
+			invokeCtx <- ctx
+			return "done", nil
+		})
+		assert.NoError(t, err)
+		streamCtx := make(chan context.Context, 1)
+		streaming, err := utils.InferStreamTool("streaming", "streaming tool", func(ctx context.Context, in *policyToolInput) (*schema.StreamReader[string], error) {
+			streamCtx <- ctx
+			return schema.StreamReaderFromArray([]string{"a", "b"}), nil
+		})
+		assert.NoError(t, err)
+
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools:             []tool.BaseTool{fast, streaming},
+			DefaultToolPolicy: &ToolPolicy{Timeout: time.Hour},
+		})
+		assert.NoError(t, err)
+
+		_, err = tn.Invoke(ctx, policyToolCalls("fast", 1))
+		assert.NoError(t, err)
+		assert.ErrorIs(t, (<-invokeCtx).Err(), context.Canceled)
+
+		sr, err := tn.Stream(ctx, policyToolCalls("streaming", 1))
+		assert.NoError(t, err)
+		sCtx := <-streamCtx
+		msgs, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, "ab", msgs[0].Content)
+		select {
+		case <-sCtx.Done():
+			assert.ErrorIs(t, sCtx.Err(), context.Canceled)
+		case <-time.After(time.Second):
+			t.Fatal("stream context is not canceled after io.EOF")
+		}
+	})
+
+	t.Run("late stream closed", func(t *testing.T) {
+		closed := make(chan struct{})
+		late, err := utils.InferStreamTool("late", "late tool", func(ctx context.Context, in *policyToolInput) (*schema.StreamReader[string], error) {
+			<-ctx.Done()
+			sr, sw := schema.Pipe[string](0)
+			go func() {
+				defer sw.Close()
+				// blocks until the reader is closed, since nobody receives
+				if sw.Send("chunk", nil) {
+					close(closed)
+				}
+			}()
+			return sr, nil
+		})
+		assert.NoError(t, err)
+
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools:             []tool.BaseTool{late},
+			DefaultToolPolicy: &ToolPolicy{Timeout: 10 * time.Millisecond},
+		})
+		assert.NoError(t, err)
+
+		_, err = tn.Stream(ctx, policyToolCalls("late", 1))
+		assert.ErrorIs(t, err, ErrToolTimeout)
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			t.Fatal("stream returned after the timeout is not closed")
+		}
+	})
+
+	t.Run("concurrency", func(t *testing.T) {
+		var running, maxRunning int32
+		limited, err := utils.InferTool("limited", "limited tool", func(ctx context.Context, in *policyToolInput) (string, error) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return in.Value, nil
+		})
+		assert.NoError(t, err)
+
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools:        []tool.BaseTool{limited},
+			ToolPolicies: map[string]*ToolPolicy{"limited": {MaxConcurrency: 2}},
+		})
+		assert.NoError(t, err)
+		output, err := tn.Invoke(ctx, policyToolCalls("limited", 6))
+		assert.NoError(t, err)
+		assert.Len(t, output, 6)
+		assert.Equal(t, "5", output[5].Content)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&maxRunning))
+
+		atomic.StoreInt32(&maxRunning, 0)
+		tn, err = NewToolNode(ctx, &ToolsNodeConfig{
+			Tools:        []tool.BaseTool{limited},
+			ToolPolicies: map[string]*ToolPolicy{"limited": {MaxConcurrency: 1, SharedAcrossRuns: true}},
+		})
+		assert.NoError(t, err)
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := tn.Invoke(ctx, policyToolCalls("limited", 2))
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), atomic.LoadInt32(&maxRunning))
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		var calls int32
+		flaky, err := utils.InferTool("flaky", "flaky tool", func(ctx context.Context, in *policyToolInput) (string, error) {
+			n := atomic.AddInt32(&calls, 1)
+			if in.Value == "fatal" {
+				return "", errors.New("fatal")
+			}
+			if n < 3 {
+				return "", tool.NewRetryableError(errors.New("unavailable"))
+			}
+			return "ok", nil
+		})
+		assert.NoError(t, err)
+
+		var backoffs []int
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools: []tool.BaseTool{flaky},
+			DefaultToolPolicy: &ToolPolicy{MaxRetries: 2, Backoff: func(attempt int) time.Duration {
+				backoffs = append(backoffs, attempt)
+				return time.Millisecond
+			}},
+		})
+		assert.NoError(t, err)
+		output, err := tn.Invoke(ctx, policyToolCalls("flaky", 1))
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", output[0].Content)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+		assert.Equal(t, []int{1, 2}, backoffs)
+
+		atomic.StoreInt32(&calls, 0)
+		_, err = tn.Invoke(ctx, &schema.Message{Role: schema.Assistant, ToolCalls: []schema.ToolCall{
+			{ID: "1", Function: schema.FunctionCall{Name: "flaky", Arguments: `{"value": "fatal"}`}},
+		}})
+		assert.ErrorContains(t, err, "fatal")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+		atomic.StoreInt32(&calls, -10)
+		_, err = tn.Invoke(ctx, policyToolCalls("flaky", 1))
+		assert.True(t, tool.IsRetryableError(err))
+	})
+
+	t.Run("default backoff", func(t *testing.T) {
+		p := &toolPolicy{ToolPolicy: &ToolPolicy{}}
+		assert.Equal(t, 100*time.Millisecond, p.backoff(1))
+		assert.Equal(t, 400*time.Millisecond, p.backoff(3))
+		assert.Equal(t, 10*time.Second, p.backoff(20))
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := NewToolNode(ctx, &ToolsNodeConfig{DefaultToolPolicy: &ToolPolicy{MaxRetries: -1}})
+		assert.Error(t, err)
+		_, err = NewToolNode(ctx, &ToolsNodeConfig{ToolPolicies: map[string]*ToolPolicy{"a": {OnTimeout: "ignore"}}})
+		assert.Error(t, err)
+	})
+}
@@ -19,6 +19,7 @@ package compose
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/cloudwego/eino/schema"
 )
@@ -263,3 +264,49 @@ func MessageParser[T any](p schema.MessageParser[T], opts ...LambdaOpt) *Lambda
 
 	return anyLambda(i, nil, nil, nil, opts...)
 }
+
+// MessageStreamParser creates a transform lambda that parses the streamed message chunks into progressively completed objects T,
+// usually used after a chatmodel to show the structured output before it's fully generated.
+// usage:
+//
+//	parser := schema.NewMessageJSONStreamParser[MyStruct](&schema.MessageJSONParseConfig{
+//		ParseFrom: schema.MessageParseFromToolCall,
+//	})
+//
+//	chain := NewChain[[]*schema.Message, *schema.PartialResult[MyStruct]]()
+//	chain.AppendChatModel(chatModel)
+//	chain.AppendLambda(MessageStreamParser(parser))
+//
+//	r, err := chain.Compile(context.Background())
+//
+//	// each chunk is a partial MyStruct, the last one has Final set, Invoke returns the last one only
+//	sr, err := r.Stream(context.Background(), messages)
+func MessageStreamParser[T any](p schema.MessageStreamParser[T], opts ...LambdaOpt) *Lambda {
+	i := func(ctx context.Context, input *schema.Message, opts_ ...unreachableOption) (output *schema.PartialResult[T], err error) {
+		sr, err := p.ParseStream(ctx, schema.StreamReaderFromArray([]*schema.Message{input}))
+		if err != nil {
+			return nil, err
+		}
+		defer sr.Close()
+
+		for {
+			result, err := sr.Recv()
+			if err == io.EOF {
+				return output, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			output = result
+		}
+	}
+
+	t := func(ctx context.Context, input *schema.StreamReader[*schema.Message], opts_ ...unreachableOption) (
+		output *schema.StreamReader[*schema.PartialResult[T]], err error) {
+		return p.ParseStream(ctx, input)
+	}
+
+	opts = append([]LambdaOpt{WithLambdaType("MessageStreamParse")}, opts...)
+
+	return anyLambda(i, nil, nil, t, opts...)
+}
@@ -18,6 +18,8 @@ package compose
 
 import (
 	"context"
+	"errors"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -209,4 +211,64 @@ func TestMessageParser(t *testing.T) {
 		assert.Nil(t, err)
 		assert.Equal(t, 1, parsed.ID)
 	})
+
+	t.Run("parse with text parser", func(t *testing.T) {
+		parser := schema.NewMessageXMLTagParser[int](&schema.MessageXMLTagParseConfig{Tag: "answer"})
+
+		chain := NewChain[*schema.Message, int]()
+		chain.AppendLambda(MessageParser(parser))
+
+		r, err := chain.Compile(context.Background())
+		assert.Nil(t, err)
+
+		parsed, err := r.Invoke(context.Background(), &schema.Message{
+			Content: `<answer>42</answer>`,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 42, parsed)
+
+		_, err = r.Invoke(context.Background(), &schema.Message{Content: `42`})
+		var parseErr *schema.MessageParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "42", parseErr.Raw)
+	})
+}
+
+func TestMessageStreamParser(t *testing.T) {
+	parser := schema.NewMessageJSONStreamParser[TestStructForParse](&schema.MessageJSONParseConfig{
+		ParseFrom: schema.MessageParseFromContent,
+	})
+
+	chain := NewChain[*schema.Message, *schema.PartialResult[TestStructForParse]]()
+	chain.AppendLambda(MessageStreamParser(parser))
+
+	r, err := chain.Compile(context.Background())
+	assert.Nil(t, err)
+
+	sr, err := r.Transform(context.Background(), schema.StreamReaderFromArray([]*schema.Message{
+		{Content: `{"id": 1`},
+		{Content: `2}`},
+	}))
+	assert.Nil(t, err)
+
+	var results []*schema.PartialResult[TestStructForParse]
+	for {
+		result, err := sr.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		results = append(results, result)
+	}
+	assert.Len(t, results, 3)
+	assert.Equal(t, 1, results[0].Value.ID)
+	assert.False(t, results[0].Final)
+	assert.Equal(t, 12, results[2].Value.ID)
+	assert.True(t, results[2].Final)
+	assert.Equal(t, []string{"id"}, results[2].FinalPaths)
+
+	parsed, err := r.Invoke(context.Background(), &schema.Message{Content: `{"id": 3}`})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, parsed.Value.ID)
+	assert.True(t, parsed.Final)
 }
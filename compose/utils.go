@@ -282,6 +282,9 @@ func extractOption(nodes map[string]*chanCall, opts ...Option) (map[string][]any
 			curNodeKey := path.path[0]
 
 			if len(path.path) == 1 {
+				if len(opt.interceptors) > 0 {
+					optMap[curNodeKey] = append(optMap[curNodeKey], &nodeCallInterceptors{interceptors: opt.interceptors})
+				}
 				if len(opt.options) == 0 {
 					// sub graph common callbacks has been added to ctx in initNodeCallback and won't be passed to subgraph only pass options
 					// node callback also won't be passed
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -674,8 +675,8 @@ func TestWorkflowCompile(t *testing.T) {
 		assert.ErrorContains(t, err, " mismatch")
 	})
 
-	t.Run("predecessor's output not struct/struct ptr/map, mapping has FromField", func(t *testing.T) {
-		w := NewWorkflow[[]*schema.Document, []string]()
+	t.Run("predecessor's output not struct/struct ptr/map/slice, mapping has FromField", func(t *testing.T) {
+		w := NewWorkflow[string, []string]()
 
 		w.AddIndexerNode("indexer", indexer.NewMockIndexer(ctrl)).AddInput(START, FromField("F1"))
 		w.End().AddInput("indexer")
@@ -683,6 +684,15 @@ func TestWorkflowCompile(t *testing.T) {
 		assert.ErrorContains(t, err, "predecessor output type should be struct")
 	})
 
+	t.Run("predecessor's output is slice, mapping source is not an index", func(t *testing.T) {
+		w := NewWorkflow[[]*schema.Document, []string]()
+
+		w.AddIndexerNode("indexer", indexer.NewMockIndexer(ctrl)).AddInput(START, FromField("F1"))
+		w.End().AddInput("indexer")
+		_, err := w.Compile(ctx)
+		assert.ErrorContains(t, err, "type[[]*schema.Document] is a slice or array, but path element[F1] is not an index")
+	})
+
 	t.Run("successor's input not struct/struct ptr/map, mapping has ToField", func(t *testing.T) {
 		w := NewWorkflow[[]string, [][]float64]()
 		w.AddEmbeddingNode("embedder", embedding.NewMockEmbedder(ctrl)).AddInput(START, ToField("F1"))
@@ -1396,6 +1406,178 @@ func TestIntermediateMappingSource(t *testing.T) {
 	})
 }
 
+func TestSliceFieldMapping(t *testing.T) {
+	ctx := context.Background()
+
+	type result struct {
+		Score float64
+	}
+
+	type retrieved struct {
+		Docs    []*schema.Document
+		Results []result
+		Fixed   [2]string
+	}
+
+	type promptInput struct {
+		Context string
+		Last    string
+		Scores  []float64
+	}
+
+	t.Run("index, negative index and wildcard", func(t *testing.T) {
+		wf := NewWorkflow[*retrieved, *promptInput]()
+		wf.End().AddInput(START,
+			MapFieldPaths(FieldPath{"Docs", FieldPathIndex(0), "Content"}, FieldPath{"Context"}),
+			MapFieldPaths(FieldPath{"Docs", FieldPathIndex(-1), "Content"}, FieldPath{"Last"}),
+			MapFieldPaths(FieldPath{"Results", FieldPathWildcard, "Score"}, FieldPath{"Scores"}),
+		)
+		r, err := wf.Compile(ctx)
+		assert.NoError(t, err)
+
+		in := &retrieved{
+			Docs:    []*schema.Document{{Content: "first"}, {Content: "second"}},
+			Results: []result{{Score: 0.5}, {Score: 0.8}},
+		}
+		out, err := r.Invoke(ctx, in)
+		assert.NoError(t, err)
+		assert.Equal(t, &promptInput{Context: "first", Last: "second", Scores: []float64{0.5, 0.8}}, out)
+
+		outS, err := r.Stream(ctx, in)
+		assert.NoError(t, err)
+		out, err = concatStreamReader(outS)
+		assert.NoError(t, err)
+		assert.Equal(t, &promptInput{Context: "first", Last: "second", Scores: []float64{0.5, 0.8}}, out)
+
+		_, err = r.Invoke(ctx, &retrieved{})
+		assert.ErrorContains(t, err, "index out of range. index=0, length=0")
+	})
+
+	t.Run("slice predecessor", func(t *testing.T) {
+		wf := NewWorkflow[[]*schema.Document, string]()
+		wf.End().AddInput(START, FromFieldPath(FieldPath{FieldPathIndex(1), "Content"}))
+		r, err := wf.Compile(ctx)
+		assert.NoError(t, err)
+		out, err := r.Invoke(ctx, []*schema.Document{{Content: "a"}, {Content: "b"}})
+		assert.NoError(t, err)
+		assert.Equal(t, "b", out)
+	})
+
+	t.Run("compile time check", func(t *testing.T) {
+		wf := NewWorkflow[*retrieved, *promptInput]()
+		wf.End().AddInput(START, MapFieldPaths(FieldPath{"Results", FieldPathWildcard, "Score"}, FieldPath{"Context"}))
+		_, err := wf.Compile(ctx)
+		assert.ErrorContains(t, err, "field[[]float64]-[string] is absolutely not assignable")
+
+		wf = NewWorkflow[*retrieved, *promptInput]()
+		wf.End().AddInput(START, MapFieldPaths(FieldPath{"Fixed", FieldPathIndex(2)}, FieldPath{"Context"}))
+		_, err = wf.Compile(ctx)
+		assert.ErrorContains(t, err, "type[[2]string] has no index[2]")
+
+		wfMap := NewWorkflow[*retrieved, map[string][]*schema.Document]()
+		wfMap.End().AddInput(START, MapFieldPaths(FieldPath{"Docs"}, FieldPath{"docs", FieldPathIndex(0)}))
+		_, err = wfMap.Compile(ctx)
+		assert.ErrorContains(t, err, "intermediate type[[]*schema.Document] is not valid")
+	})
+
+	t.Run("wildcard through interface with type coercion", func(t *testing.T) {
+		wf := NewWorkflow[map[string]any, *promptInput]()
+		wf.End().AddInput(START, MapFieldPaths(FieldPath{"results", FieldPathWildcard, "score"}, FieldPath{"Scores"}, WithTypeCoercion()))
+		r, err := wf.Compile(ctx)
+		assert.NoError(t, err)
+
+		out, err := r.Invoke(ctx, map[string]any{
+			"results": []any{
+				map[string]any{"score": 1},
+				map[string]any{"score": 0.5},
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, &promptInput{Scores: []float64{1, 0.5}}, out)
+
+		_, err = r.Invoke(ctx, map[string]any{
+			"results": []any{
+				map[string]any{"score": "high"},
+			},
+		})
+		assert.ErrorContains(t, err, "field[string]-[float64] is not coercible")
+	})
+
+	t.Run("type coercion", func(t *testing.T) {
+		type in struct {
+			Count int
+			Query string
+		}
+		type out struct {
+			Count   float64
+			Queries []string
+		}
+
+		wf := NewWorkflow[in, out]()
+		wf.End().AddInput(START, MapFields("Count", "Count", WithTypeCoercion()), MapFields("Query", "Queries", WithTypeCoercion()))
+		r, err := wf.Compile(ctx)
+		assert.NoError(t, err)
+		result, err := r.Invoke(ctx, in{Count: 3, Query: "q"})
+		assert.NoError(t, err)
+		assert.Equal(t, out{Count: 3, Queries: []string{"q"}}, result)
+
+		wf = NewWorkflow[in, out]()
+		wf.End().AddInput(START, MapFields("Count", "Count"))
+		_, err = wf.Compile(ctx)
+		assert.ErrorContains(t, err, "field[int]-[float64] is absolutely not assignable")
+
+		wf = NewWorkflow[in, out]()
+		wf.End().AddInput(START, MapFields("Query", "Count", WithTypeCoercion()))
+		_, err = wf.Compile(ctx)
+		assert.ErrorContains(t, err, "field[string]-[float64] is absolutely not assignable")
+	})
+
+	t.Run("type coercion out of range", func(t *testing.T) {
+		type in struct {
+			Big   int64
+			Neg   int
+			Float float64
+		}
+		type out struct {
+			Small    int8
+			Unsigned uint
+			Single   float32
+		}
+
+		wf := NewWorkflow[in, out]()
+		wf.End().AddInput(START, MapFields("Big", "Small", WithTypeCoercion()),
+			MapFields("Neg", "Unsigned", WithTypeCoercion()), MapFields("Float", "Single", WithTypeCoercion()))
+		r, err := wf.Compile(ctx)
+		assert.NoError(t, err)
+
+		result, err := r.Invoke(ctx, in{Big: 100, Neg: 1, Float: 1.5})
+		assert.NoError(t, err)
+		assert.Equal(t, out{Small: 100, Unsigned: 1, Single: 1.5}, result)
+
+		_, err = r.Invoke(ctx, in{Big: 300, Neg: 1})
+		assert.ErrorContains(t, err, "field[int64]-[int8] is not coercible, value 300 out of range")
+
+		_, err = r.Invoke(ctx, in{Neg: -1})
+		assert.ErrorContains(t, err, "field[int]-[uint] is not coercible, value -1 out of range")
+
+		_, err = r.Invoke(ctx, in{Float: math.MaxFloat64})
+		assert.ErrorContains(t, err, "field[float64]-[float32] is not coercible")
+	})
+
+	t.Run("float to int coercion through interface", func(t *testing.T) {
+		type out struct {
+			Count int
+		}
+
+		wf := NewWorkflow[map[string]any, out]()
+		wf.End().AddInput(START, MapFields("count", "Count", WithTypeCoercion()))
+		r, err := wf.Compile(ctx)
+		assert.NoError(t, err)
+		_, err = r.Invoke(ctx, map[string]any{"count": 2.5})
+		assert.ErrorContains(t, err, "field[float64]-[int] is not coercible")
+	})
+}
+
 type goodStruct2 struct {
 	A string
 	c string
@@ -18,9 +18,12 @@ package react
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 
 	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/flow/agent"
 	"github.com/cloudwego/eino/schema"
@@ -53,8 +56,14 @@ type AgentConfig struct {
 	Model model.ChatModel
 
 	// ToolsConfig is the config for tools node.
+	// The tools of ToolsConfig.Toolkits are listed on each Generate or Stream, and bound to the model along with ToolsConfig.Tools.
 	ToolsConfig compose.ToolsNodeConfig
 
+	// ToolSelector selects the tools relevant to the conversation from its catalog on each turn, optional.
+	// The selected tools are bound to ToolCallingModel in addition to the tools of ToolsConfig, which are always bound,
+	// and the tools of the catalog are added to the tools node. Requires ToolCallingModel.
+	ToolSelector tool.ToolSelector
+
 	// MessageModifier.
 	// modify the input messages before the model is called, it's useful when you want to add some system prompt or other messages.
 	MessageModifier MessageModifier
@@ -177,6 +186,10 @@ type Agent struct {
 	runnable         compose.Runnable[[]*schema.Message, *schema.Message]
 	graph            *compose.Graph[[]*schema.Message, *schema.Message]
 	graphAddNodeOpts []compose.GraphAddNodeOpt
+
+	// toolsConfig and selectorTools are kept to list the tools of the toolkits on each run.
+	toolsConfig   compose.ToolsNodeConfig
+	selectorTools []tool.BaseTool
 }
 
 // NewAgent creates a ReAct agent that feeds tool response into next round of Chat Model generation.
@@ -216,11 +229,20 @@ func NewAgent(ctx context.Context, config *AgentConfig) (_ *Agent, err error) {
 		return nil, err
 	}
 
-	if chatModel, err = agent.ChatModelWithTools(config.Model, config.ToolCallingModel, toolInfos); err != nil {
+	toolCallingModel, toolsConfig := config.ToolCallingModel, config.ToolsConfig
+	if config.ToolSelector != nil {
+		if toolCallingModel == nil {
+			return nil, errors.New("ToolSelector requires ToolCallingModel")
+		}
+		toolCallingModel = config.ToolSelector.Model(toolCallingModel)
+		toolsConfig.Tools = append(toolsConfig.Tools[:len(toolsConfig.Tools):len(toolsConfig.Tools)], config.ToolSelector.Tools()...)
+	}
+
+	if chatModel, err = agent.ChatModelWithTools(config.Model, toolCallingModel, toolInfos); err != nil {
 		return nil, err
 	}
 
-	if toolsNode, err = compose.NewToolNode(ctx, &config.ToolsConfig); err != nil {
+	if toolsNode, err = compose.NewToolNode(ctx, &toolsConfig); err != nil {
 		return nil, err
 	}
 
@@ -283,11 +305,16 @@ func NewAgent(ctx context.Context, config *AgentConfig) (_ *Agent, err error) {
 		return nil, err
 	}
 
-	return &Agent{
+	ret := &Agent{
 		runnable:         runnable,
 		graph:            graph,
 		graphAddNodeOpts: []compose.GraphAddNodeOpt{compose.WithGraphCompileOptions(compileOpts...)},
-	}, nil
+		toolsConfig:      config.ToolsConfig,
+	}
+	if config.ToolSelector != nil {
+		ret.selectorTools = config.ToolSelector.Tools()
+	}
+	return ret, nil
 }
 
 func buildReturnDirectly(graph *compose.Graph[[]*schema.Message, *schema.Message]) (err error) {
@@ -352,6 +379,9 @@ func genToolInfos(ctx context.Context, config compose.ToolsNodeConfig) ([]*schem
 
 		toolInfos = append(toolInfos, tl)
 	}
+	if info := compose.ReadMoreToolInfo(&config); info != nil {
+		toolInfos = append(toolInfos, info)
+	}
 
 	return toolInfos, nil
 }
@@ -372,14 +402,47 @@ func getReturnDirectlyToolCallID(input *schema.Message, toolReturnDirectly map[s
 
 // Generate generates a response from the agent.
 func (r *Agent) Generate(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+	opts, err := r.withToolkitOptions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
 	return r.runnable.Invoke(ctx, input, agent.GetComposeOptions(opts...)...)
 }
 
 // Stream calls the agent and returns a stream response.
 func (r *Agent) Stream(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (output *schema.StreamReader[*schema.Message], err error) {
+	opts, err = r.withToolkitOptions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
 	return r.runnable.Stream(ctx, input, agent.GetComposeOptions(opts...)...)
 }
 
+// withToolkitOptions lists the tools of ToolsConfig.Toolkits for the run, and binds them to the model and the tools node
+// along with ToolsConfig.Tools. The options given by the caller, e.g. WithTools, take precedence.
+func (r *Agent) withToolkitOptions(ctx context.Context, opts []agent.AgentOption) ([]agent.AgentOption, error) {
+	if len(r.toolsConfig.Toolkits) == 0 {
+		return opts, nil
+	}
+
+	tools, err := tool.MergeToolkits(append([]tool.Toolkit{tool.NewToolkit(r.toolsConfig.Tools...)}, r.toolsConfig.Toolkits...)...).Tools(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools from toolkits: %w", err)
+	}
+	conf := r.toolsConfig
+	conf.Tools = tools
+	toolInfos, err := genToolInfos(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	toolkitOpts := []agent.AgentOption{agent.WithComposeOptions(
+		compose.WithChatModelOption(model.WithTools(toolInfos)),
+		compose.WithToolsNodeOption(compose.WithToolList(append(tools[:len(tools):len(tools)], r.selectorTools...)...)),
+	)}
+	return append(toolkitOpts, opts...), nil
+}
+
 // ExportGraph exports the underlying graph from Agent, along with the []compose.GraphAddNodeOpt to be used when adding this graph to another graph.
 func (r *Agent) ExportGraph() (compose.AnyGraph, []compose.GraphAddNodeOpt) {
 	return r.graph, r.graphAddNodeOpts
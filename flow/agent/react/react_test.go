@@ -30,8 +30,11 @@ import (
 
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/flow/tool/selector"
+	mockEmbedding "github.com/cloudwego/eino/internal/mock/components/embedding"
 	mockModel "github.com/cloudwego/eino/internal/mock/components/model"
 	"github.com/cloudwego/eino/schema"
 	template "github.com/cloudwego/eino/utils/callbacks"
@@ -758,3 +761,201 @@ func randStr() string {
 }
 
 var callbackForTest = BuildAgentCallback(&template.ModelCallbackHandler{}, &template.ToolCallbackHandler{})
+
+func TestReactWithToolSelector(t *testing.T) {
+	ctx := context.Background()
+
+	type weatherInput struct {
+		City string `json:"city"`
+	}
+	weather, err := utils.InferTool("weather", "query the weather", func(ctx context.Context, in *weatherInput) (string, error) {
+		return "sunny in " + in.City, nil
+	})
+	assert.NoError(t, err)
+	stock, err := utils.InferTool("stock", "query the stock price", func(ctx context.Context, in *weatherInput) (string, error) {
+		return "100", nil
+	})
+	assert.NoError(t, err)
+
+	sel, err := selector.NewSelector(ctx, &selector.Config{
+		Embedder: &mockEmbedding.KeywordEmbedder{Keywords: []string{"weather", "stock"}},
+		Tools:    []tool.BaseTool{weather, stock},
+		TopK:     1,
+		MinScore: 0.5,
+	})
+	assert.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockToolCallingChatModel(ctrl)
+	var bound [][]string
+	cm.EXPECT().WithTools(gomock.Any()).DoAndReturn(func(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+		var names []string
+		for _, info := range tools {
+			names = append(names, info.Name)
+		}
+		bound = append(bound, names)
+		return cm, nil
+	}).AnyTimes()
+	times := 0
+	cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+			times++
+			if times == 1 {
+				return schema.AssistantMessage("", []schema.ToolCall{
+					{ID: "1", Function: schema.FunctionCall{Name: "weather", Arguments: `{"city": "Paris"}`}},
+				}), nil
+			}
+			assert.Equal(t, "sunny in Paris", input[len(input)-1].Content)
+			return schema.AssistantMessage("it's sunny", nil), nil
+		}).Times(2)
+
+	a, err := NewAgent(ctx, &AgentConfig{
+		ToolCallingModel: cm,
+		ToolSelector:     sel,
+	})
+	assert.NoError(t, err)
+
+	out, err := a.Generate(ctx, []*schema.Message{schema.UserMessage("what's the weather in Paris")})
+	assert.NoError(t, err)
+	assert.Equal(t, "it's sunny", out.Content)
+	assert.Equal(t, [][]string{{"weather"}, {"weather"}}, bound)
+
+	_, err = NewAgent(ctx, &AgentConfig{Model: mockModel.NewMockChatModel(ctrl), ToolSelector: sel})
+	assert.Error(t, err)
+}
+
+func TestReactWithToolkits(t *testing.T) {
+	ctx := context.Background()
+
+	type stockInput struct {
+		Code string `json:"code"`
+	}
+	weather, err := utils.InferTool("weather", "query the weather", func(ctx context.Context, in *stockInput) (string, error) {
+		return "sunny", nil
+	})
+	assert.NoError(t, err)
+	stock, err := utils.InferTool("price", "query the stock price", func(ctx context.Context, in *stockInput) (string, error) {
+		return "100", nil
+	})
+	assert.NoError(t, err)
+
+	type adminKey struct{}
+	stockToolkit := tool.PrefixToolkit(tool.ToolkitFunc(func(ctx context.Context) ([]tool.BaseTool, error) {
+		if ctx.Value(adminKey{}) == nil {
+			return nil, nil
+		}
+		return []tool.BaseTool{stock}, nil
+	}), "stock_")
+
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockToolCallingChatModel(ctrl)
+	cm.EXPECT().WithTools(gomock.Any()).Return(cm, nil).AnyTimes()
+	var bound [][]string
+	cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+			var names []string
+			for _, info := range model.GetCommonOptions(nil, opts...).Tools {
+				names = append(names, info.Name)
+			}
+			bound = append(bound, names)
+			if len(names) == 2 && input[len(input)-1].Role == schema.User {
+				return schema.AssistantMessage("", []schema.ToolCall{
+					{ID: "1", Function: schema.FunctionCall{Name: "stock_price", Arguments: `{"code": "X"}`}},
+				}), nil
+			}
+			return schema.AssistantMessage("done: "+input[len(input)-1].Content, nil), nil
+		}).AnyTimes()
+
+	a, err := NewAgent(ctx, &AgentConfig{
+		ToolCallingModel: cm,
+		ToolsConfig: compose.ToolsNodeConfig{
+			Tools:    []tool.BaseTool{weather},
+			Toolkits: []tool.Toolkit{stockToolkit},
+		},
+	})
+	assert.NoError(t, err)
+
+	out, err := a.Generate(context.WithValue(ctx, adminKey{}, true), []*schema.Message{schema.UserMessage("price of X")})
+	assert.NoError(t, err)
+	assert.Equal(t, "done: 100", out.Content)
+	assert.Equal(t, [][]string{{"weather", "stock_price"}, {"weather", "stock_price"}}, bound)
+
+	// the toolkits are listed again on the next run
+	bound = nil
+	out, err = a.Generate(ctx, []*schema.Message{schema.UserMessage("price of X")})
+	assert.NoError(t, err)
+	assert.Equal(t, "done: price of X", out.Content)
+	assert.Equal(t, [][]string{{"weather"}}, bound)
+
+	a, err = NewAgent(ctx, &AgentConfig{
+		ToolCallingModel: cm,
+		ToolsConfig: compose.ToolsNodeConfig{
+			Tools:    []tool.BaseTool{weather},
+			Toolkits: []tool.Toolkit{tool.NewToolkit(weather)},
+		},
+	})
+	assert.NoError(t, err)
+	_, err = a.Generate(ctx, []*schema.Message{schema.UserMessage("hi")})
+	assert.ErrorContains(t, err, "duplicate tool name in toolkits: weather")
+}
+
+func TestReactWithToolSelectorAndToolkits(t *testing.T) {
+	ctx := context.Background()
+
+	type cityInput struct {
+		City string `json:"city"`
+	}
+	weather, err := utils.InferTool("weather", "query the weather", func(ctx context.Context, in *cityInput) (string, error) {
+		return "sunny in " + in.City, nil
+	})
+	assert.NoError(t, err)
+	stock, err := utils.InferTool("stock", "query the stock price", func(ctx context.Context, in *cityInput) (string, error) {
+		return "100", nil
+	})
+	assert.NoError(t, err)
+	calendar, err := utils.InferTool("calendar", "create a calendar event", func(ctx context.Context, in *cityInput) (string, error) {
+		return "created", nil
+	})
+	assert.NoError(t, err)
+
+	sel, err := selector.NewSelector(ctx, &selector.Config{
+		Embedder: &mockEmbedding.KeywordEmbedder{Keywords: []string{"weather", "stock"}},
+		Tools:    []tool.BaseTool{weather, stock},
+		TopK:     1,
+		MinScore: 0.5,
+	})
+	assert.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockToolCallingChatModel(ctrl)
+	cm.EXPECT().WithTools(gomock.Any()).Return(cm, nil).AnyTimes()
+	var bound [][]string
+	cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+			var names []string
+			for _, info := range model.GetCommonOptions(nil, opts...).Tools {
+				names = append(names, info.Name)
+			}
+			bound = append(bound, names)
+			if input[len(input)-1].Role == schema.User {
+				return schema.AssistantMessage("", []schema.ToolCall{
+					{ID: "1", Function: schema.FunctionCall{Name: "calendar", Arguments: `{"city": "Paris"}`}},
+					{ID: "2", Function: schema.FunctionCall{Name: "weather", Arguments: `{"city": "Paris"}`}},
+				}), nil
+			}
+			return schema.AssistantMessage("done", nil), nil
+		}).Times(2)
+
+	a, err := NewAgent(ctx, &AgentConfig{
+		ToolCallingModel: cm,
+		ToolsConfig:      compose.ToolsNodeConfig{Toolkits: []tool.Toolkit{tool.NewToolkit(calendar)}},
+		ToolSelector:     sel,
+	})
+	assert.NoError(t, err)
+
+	out, err := a.Generate(ctx, []*schema.Message{schema.UserMessage("plan by the weather in Paris")})
+	assert.NoError(t, err)
+	assert.Equal(t, "done", out.Content)
+	// the tools listed from the toolkits are bound along with the selected tools
+	assert.Equal(t, [][]string{{"calendar", "weather"}, {"calendar", "weather"}}, bound)
+}
@@ -0,0 +1,441 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	diffContextLines = 3
+	// maxDiffCells bounds the lcs table of the changed lines, beyond which they are diffed as replaced entirely.
+	maxDiffCells = 4 << 20
+	devNull      = "/dev/null"
+)
+
+// splitLines splits the content into lines without the line breaks, reporting whether it ends with a line break.
+func splitLines(content string) ([]string, bool) {
+	if content == "" {
+		return nil, false
+	}
+	trailing := strings.HasSuffix(content, "\n")
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	return lines, trailing
+}
+
+func joinLines(lines []string, trailing bool) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	content := strings.Join(lines, "\n")
+	if trailing {
+		content += "\n"
+	}
+	return content
+}
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// diffLines returns the edit script turning a into b, by the lcs of the lines between the common prefix and suffix.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, l := range a[:prefix] {
+		ops = append(ops, diffOp{' ', l})
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if (len(ma)+1)*(len(mb)+1) > maxDiffCells {
+		for _, l := range ma {
+			ops = append(ops, diffOp{'-', l})
+		}
+		for _, l := range mb {
+			ops = append(ops, diffOp{'+', l})
+		}
+	} else {
+		// lcs[i][j] is the length of the lcs of ma[i:] and mb[j:]
+		lcs := make([][]int, len(ma)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(mb)+1)
+		}
+		for i := len(ma) - 1; i >= 0; i-- {
+			for j := len(mb) - 1; j >= 0; j-- {
+				if ma[i] == mb[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else if lcs[i+1][j] >= lcs[i][j+1] {
+					lcs[i][j] = lcs[i+1][j]
+				} else {
+					lcs[i][j] = lcs[i][j+1]
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(ma) || j < len(mb) {
+			switch {
+			case i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+				ops = append(ops, diffOp{' ', ma[i]})
+				i++
+				j++
+			case j == len(mb) || i < len(ma) && lcs[i+1][j] >= lcs[i][j+1]:
+				ops = append(ops, diffOp{'-', ma[i]})
+				i++
+			default:
+				ops = append(ops, diffOp{'+', mb[j]})
+				j++
+			}
+		}
+	}
+
+	for _, l := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', l})
+	}
+	return ops
+}
+
+// unifiedDiff returns the unified diff from oldContent to newContent, empty if they are the same.
+// oldName or newName is "/dev/null" when the file is created or deleted.
+func unifiedDiff(oldName, newName, oldContent, newContent string) string {
+	if oldContent == newContent {
+		return ""
+	}
+	a, aTrailing := splitLines(oldContent)
+	b, bTrailing := splitLines(newContent)
+	ops := diffLines(a, b)
+
+	ops = splitNoNewlineContext(ops, aTrailing, bTrailing)
+
+	sb := &strings.Builder{}
+	if oldName != devNull {
+		oldName = "a/" + oldName
+	}
+	if newName != devNull {
+		newName = "b/" + newName
+	}
+	fmt.Fprintf(sb, "--- %s\n+++ %s\n", oldName, newName)
+
+	// group the changes with the context lines into hunks
+	for start := 0; start < len(ops); {
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		hunkStart := first - diffContextLines
+		if hunkStart < start {
+			hunkStart = start
+		}
+		end := first
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			// the hunk ends if the following unchanged lines are more than the context of two hunks
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContextLines {
+				end += diffContextLines
+				if end > next {
+					end = next
+				}
+				break
+			}
+			end = next
+		}
+
+		oldLine, newLine := 1, 1
+		for _, op := range ops[:hunkStart] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, op := range ops[hunkStart:end] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount))
+
+		oldSeen, newSeen := oldLine-1, newLine-1
+		for _, op := range ops[hunkStart:end] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+			sb.WriteByte('\n')
+			if op.kind != '+' {
+				oldSeen++
+			}
+			if op.kind != '-' {
+				newSeen++
+			}
+			if op.kind == '+' && newSeen == len(b) && !bTrailing || op.kind != '+' && oldSeen == len(a) && !aTrailing {
+				sb.WriteString("\\ No newline at end of file\n")
+			}
+		}
+		start = end
+	}
+	return sb.String()
+}
+
+// splitNoNewlineContext replaces the context line without the line break by the removed and the added line,
+// unless it's the last line of both the files and neither ends with the line break.
+func splitNoNewlineContext(ops []diffOp, aTrailing, bTrailing bool) []diffOp {
+	lastOld, lastNew := -1, -1
+	for i, op := range ops {
+		if op.kind != '+' {
+			lastOld = i
+		}
+		if op.kind != '-' {
+			lastNew = i
+		}
+	}
+
+	// split the later one first to keep the index of the other
+	for _, i := range []int{lastNew, lastOld} {
+		if i < 0 || ops[i].kind != ' ' {
+			continue
+		}
+		noNewlineOld, noNewlineNew := i == lastOld && !aTrailing, i == lastNew && !bTrailing
+		if noNewlineOld == noNewlineNew {
+			continue
+		}
+		line := ops[i].line
+		ops = append(ops[:i], append([]diffOp{{'-', line}, {'+', line}}, ops[i+1:]...)...)
+	}
+	return ops
+}
+
+func hunkRange(line, count int) string {
+	if count == 0 {
+		// the line before the empty range
+		line--
+	}
+	if count == 1 {
+		return strconv.Itoa(line)
+	}
+	return fmt.Sprintf("%d,%d", line, count)
+}
+
+// filePatch is the changes of a file in the patch.
+type filePatch struct {
+	oldName string
+	newName string
+	hunks   []*hunk
+}
+
+type hunk struct {
+	oldStart int
+	ops      []diffOp
+	// noNewline reports "\ No newline at end of file" after the last new line of the hunk.
+	noNewline bool
+	// bareBlanks is the number of the trailing blank lines without the leading space,
+	// which may separate the files rather than be the context.
+	bareBlanks int
+}
+
+// parsePatch parses the unified diff of one or more files.
+// It's lenient with the line counts of the hunk headers, which are often wrong in the patches written by the models.
+func parsePatch(patch string) ([]*filePatch, error) {
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var files []*filePatch
+	var cur *filePatch
+	var h *hunk
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			cur = &filePatch{oldName: patchFileName(line[4:]), newName: patchFileName(lines[i+1][4:])}
+			files = append(files, cur)
+			h = nil
+			i++
+		case strings.HasPrefix(line, "@@"):
+			if cur == nil {
+				return nil, errors.New("hunk without the file header, the patch should start with \"--- a/path\" and \"+++ b/path\"")
+			}
+			oldStart, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			h = &hunk{oldStart: oldStart}
+			cur.hunks = append(cur.hunks, h)
+		case h != nil && strings.HasPrefix(line, "\\"):
+			if len(h.ops) > 0 && h.ops[len(h.ops)-1].kind != '-' {
+				h.noNewline = true
+			}
+		case h != nil && line == "":
+			// the blank context line without the leading space
+			h.ops = append(h.ops, diffOp{' ', ""})
+			h.bareBlanks++
+		case h != nil && (line[0] == ' ' || line[0] == '-' || line[0] == '+'):
+			h.ops = append(h.ops, diffOp{line[0], line[1:]})
+			h.bareBlanks = 0
+		case h == nil && (strings.HasPrefix(line, "diff ") || strings.HasPrefix(line, "index ") || strings.TrimSpace(line) == ""):
+			// the git headers
+		default:
+			return nil, fmt.Errorf("invalid patch line %d: %q", i+1, line)
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no file in the patch, the patch should be in the unified diff format")
+	}
+	for _, f := range files {
+		for _, fh := range f.hunks {
+			fh.ops = fh.ops[:len(fh.ops)-fh.bareBlanks]
+		}
+		if f.oldName == devNull && f.newName == devNull {
+			return nil, errors.New("invalid file header: both files are /dev/null")
+		}
+		if len(f.hunks) == 0 && f.newName != devNull {
+			return nil, fmt.Errorf("no hunk for file %s", f.newName)
+		}
+	}
+	return files, nil
+}
+
+// patchFileName strips the timestamp and the a/ or b/ prefix from the file name in the header.
+func patchFileName(name string) string {
+	if i := strings.IndexByte(name, '\t'); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.TrimSpace(name)
+	if name == devNull {
+		return name
+	}
+	if strings.HasPrefix(name, "a/") || strings.HasPrefix(name, "b/") {
+		name = name[2:]
+	}
+	return name
+}
+
+// parseHunkHeader returns the old start line of "@@ -l,s +l,s @@", 0 if absent.
+func parseHunkHeader(line string) (int, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || !strings.HasPrefix(fields[1], "-") {
+		// "@@" without the ranges, the hunk is located by the context
+		return 0, nil
+	}
+	r := strings.TrimPrefix(fields[1], "-")
+	if i := strings.IndexByte(r, ','); i >= 0 {
+		r = r[:i]
+	}
+	start, err := strconv.Atoi(r)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hunk header: %q", line)
+	}
+	return start, nil
+}
+
+// apply applies the hunks to the lines in order, locating each hunk by its lines nearest to the line in the header.
+func (f *filePatch) apply(lines []string, trailing bool) ([]string, bool, error) {
+	result := append([]string(nil), lines...)
+	offset := 0
+	minPos := 0
+	for idx, h := range f.hunks {
+		var old, repl []string
+		for _, op := range h.ops {
+			if op.kind != '+' {
+				old = append(old, op.line)
+			}
+			if op.kind != '-' {
+				repl = append(repl, op.line)
+			}
+		}
+
+		expected := h.oldStart - 1 + offset
+		if len(old) == 0 && h.oldStart > 0 {
+			// inserting after the line oldStart
+			expected = h.oldStart + offset
+		}
+		pos := locate(result, old, expected, minPos, func(a, b string) bool { return a == b })
+		if pos < 0 {
+			pos = locate(result, old, expected, minPos, func(a, b string) bool {
+				return strings.TrimRight(a, " \t") == strings.TrimRight(b, " \t")
+			})
+		}
+		if pos < 0 {
+			return nil, false, fmt.Errorf("hunk %d doesn't apply: its context and removed lines are not found in the file, "+
+				"read the file again and retry with the exact lines", idx+1)
+		}
+
+		result = append(result[:pos], append(repl, result[pos+len(old):]...)...)
+		offset += len(repl) - len(old)
+		minPos = pos + len(repl)
+		if pos+len(repl) == len(result) && len(repl) > 0 {
+			trailing = !h.noNewline
+		}
+	}
+	return result, trailing, nil
+}
+
+// locate returns the position of old in lines at or after minPos nearest to expected, -1 if not found.
+func locate(lines, old []string, expected, minPos int, eq func(a, b string) bool) int {
+	last := len(lines) - len(old)
+	if last < minPos {
+		return -1
+	}
+	if expected < minPos {
+		expected = minPos
+	}
+	if expected > last {
+		expected = last
+	}
+	matchAt := func(pos int) bool {
+		for i, l := range old {
+			if !eq(lines[pos+i], l) {
+				return false
+			}
+		}
+		return true
+	}
+	for d := 0; expected-d >= minPos || expected+d <= last; d++ {
+		if p := expected - d; p >= minPos && matchAt(p) {
+			return p
+		}
+		if p := expected + d; d > 0 && p <= last && matchAt(p) {
+			return p
+		}
+	}
+	return -1
+}
@@ -0,0 +1,243 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package filesystem provides the tools for the agents to read and edit the files under a root directory,
+// i.e. read_file, write_file, list_dir, grep and apply_patch.
+// The paths given by the model are resolved in the root directory, and the paths escaping it,
+// by ".." or by symlinks, are rejected.
+// The failures caused by the calls, e.g. a missing file or a patch not matching the file,
+// are returned to the model as the tool results starting with "error: ", so that it can correct the call.
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+// The names of the tools.
+const (
+	ReadFileToolName   = "read_file"
+	WriteFileToolName  = "write_file"
+	ListDirToolName    = "list_dir"
+	GrepToolName       = "grep"
+	ApplyPatchToolName = "apply_patch"
+)
+
+const (
+	defaultMaxFileSize    = 1 << 20
+	defaultMaxReadLines   = 2000
+	defaultMaxListEntries = 1000
+	defaultMaxGrepMatches = 100
+)
+
+// Config is the config for the filesystem tools.
+type Config struct {
+	// Root is the directory the tools are scoped to, required.
+	// The relative paths given by the model are relative to it.
+	Root string
+
+	// ReadOnly only creates read_file, list_dir and grep.
+	ReadOnly bool
+	// DryRun makes write_file and apply_patch report the diffs of the intended writes without writing the files.
+	DryRun bool
+
+	// MaxFileSize is the max size in bytes of the files read, searched or written, default 1MB.
+	// The larger files are refused by read_file, write_file and apply_patch, and skipped by grep.
+	MaxFileSize int64
+	// MaxReadLines is the max lines returned by each call of read_file, default 2000.
+	// The model reads the rest of the file by the offset.
+	MaxReadLines int
+	// MaxListEntries is the max entries returned by list_dir, default 1000.
+	MaxListEntries int
+	// MaxGrepMatches is the max matched lines returned by grep, default 100.
+	MaxGrepMatches int
+}
+
+// NewTools creates the filesystem tools scoped to config.Root.
+// e.g.
+//
+//	tools, err := filesystem.NewTools(ctx, &filesystem.Config{Root: "/workspace/repo", DryRun: true})
+//	agent, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
+//		ToolsConfig: adk.ToolsConfig{ToolsNodeConfig: compose.ToolsNodeConfig{Tools: tools}},
+//		// ...
+//	})
+func NewTools(_ context.Context, config *Config) ([]tool.BaseTool, error) {
+	if config.Root == "" {
+		return nil, errors.New("root directory is required")
+	}
+	root, err := filepath.Abs(config.Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path of root directory: %w", err)
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return nil, fmt.Errorf("failed to resolve root directory: %w", err)
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat root directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("root %s is not a directory", config.Root)
+	}
+
+	s := &sandbox{
+		root:           root,
+		dryRun:         config.DryRun,
+		maxFileSize:    config.MaxFileSize,
+		maxReadLines:   config.MaxReadLines,
+		maxListEntries: config.MaxListEntries,
+		maxGrepMatches: config.MaxGrepMatches,
+	}
+	if s.maxFileSize <= 0 {
+		s.maxFileSize = defaultMaxFileSize
+	}
+	if s.maxReadLines <= 0 {
+		s.maxReadLines = defaultMaxReadLines
+	}
+	if s.maxListEntries <= 0 {
+		s.maxListEntries = defaultMaxListEntries
+	}
+	if s.maxGrepMatches <= 0 {
+		s.maxGrepMatches = defaultMaxGrepMatches
+	}
+
+	tools, err := s.readTools()
+	if err != nil {
+		return nil, err
+	}
+	if config.ReadOnly {
+		return tools, nil
+	}
+	writeTools, err := s.writeTools()
+	if err != nil {
+		return nil, err
+	}
+	return append(tools, writeTools...), nil
+}
+
+// NewToolkit creates the filesystem tools as a tool.Toolkit, e.g. to be namespaced by tool.PrefixToolkit.
+func NewToolkit(ctx context.Context, config *Config) (tool.Toolkit, error) {
+	tools, err := NewTools(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return tool.NewToolkit(tools...), nil
+}
+
+type sandbox struct {
+	// root is absolute with the symlinks resolved.
+	root   string
+	dryRun bool
+
+	maxFileSize    int64
+	maxReadLines   int
+	maxListEntries int
+	maxGrepMatches int
+}
+
+// resolve returns the real path of p in the root directory and the path relative to the root for display.
+// p may not exist, in which case its existing ancestors are resolved.
+func (s *sandbox) resolve(p string) (string, string, error) {
+	if strings.TrimSpace(p) == "" {
+		p = "."
+	}
+	full := filepath.Clean(p)
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(s.root, full)
+	}
+	if !s.within(full) {
+		return "", "", fmt.Errorf("path %s is outside the root directory, use the paths relative to the root", p)
+	}
+
+	// resolve the symlinks of the existing part of the path
+	existing := full
+	var rest []string
+	for {
+		real, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			full = filepath.Join(append([]string{real}, rest...)...)
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", "", fmt.Errorf("failed to resolve path %s: %w", p, err)
+		}
+		if _, lErr := os.Lstat(existing); lErr == nil {
+			// a dangling symlink, whose target could be created outside the root by writing
+			return "", "", fmt.Errorf("path %s is a broken symlink", p)
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = append([]string{filepath.Base(existing)}, rest...)
+		existing = parent
+	}
+	if !s.within(full) {
+		return "", "", fmt.Errorf("path %s is outside the root directory through a symlink", p)
+	}
+
+	return full, s.display(full), nil
+}
+
+func (s *sandbox) within(p string) bool {
+	rel, err := filepath.Rel(s.root, p)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// display returns the path relative to the root in the slash separated form.
+func (s *sandbox) display(full string) string {
+	rel, err := filepath.Rel(s.root, full)
+	if err != nil {
+		return filepath.ToSlash(full)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// readRegularFile reads the regular file within the size limit.
+func (s *sandbox) readRegularFile(full, display string) ([]byte, error) {
+	info, err := os.Stat(full)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("file %s doesn't exist", display)
+		}
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory, use %s to list it", display, ListDirToolName)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", display)
+	}
+	if info.Size() > s.maxFileSize {
+		return nil, fmt.Errorf("file %s is too large: %d bytes, the limit is %d bytes", display, info.Size(), s.maxFileSize)
+	}
+	return os.ReadFile(full)
+}
+
+// errorResult reports the failure of the call to the model.
+func errorResult(err error) string {
+	return "error: " + err.Error()
+}
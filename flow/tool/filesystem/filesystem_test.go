@@ -0,0 +1,278 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filesystem
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+type testFS struct {
+	t     *testing.T
+	root  string
+	tools map[string]tool.InvokableTool
+}
+
+func newTestFS(t *testing.T, config *Config, files map[string]string) *testFS {
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		assert.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+	if config == nil {
+		config = &Config{}
+	}
+	config.Root = root
+
+	tools, err := NewTools(context.Background(), config)
+	assert.NoError(t, err)
+	tfs := &testFS{t: t, root: root, tools: make(map[string]tool.InvokableTool)}
+	for _, tl := range tools {
+		info, err := tl.Info(context.Background())
+		assert.NoError(t, err)
+		tfs.tools[info.Name] = tl.(tool.InvokableTool)
+	}
+	return tfs
+}
+
+func (f *testFS) call(name string, args map[string]any) string {
+	data, err := json.Marshal(args)
+	assert.NoError(f.t, err)
+	out, err := f.tools[name].InvokableRun(context.Background(), string(data))
+	assert.NoError(f.t, err)
+	return out
+}
+
+func (f *testFS) read(name string) string {
+	data, err := os.ReadFile(filepath.Join(f.root, filepath.FromSlash(name)))
+	if err != nil {
+		return "<missing>"
+	}
+	return string(data)
+}
+
+func TestReadFile(t *testing.T) {
+	f := newTestFS(t, &Config{MaxReadLines: 2, MaxFileSize: 64}, map[string]string{
+		"a.txt":     "one\ntwo\nthree\n",
+		"empty.txt": "",
+		"bin":       "a\x00b",
+		"large.txt": strings.Repeat("x", 65),
+	})
+
+	assert.Equal(t, "     1\tone\n     2\ttwo\n... (showing lines 1-2 of 3, read from offset 3 for more)\n",
+		f.call(ReadFileToolName, map[string]any{"path": "a.txt"}))
+	assert.Equal(t, "     3\tthree\n", f.call(ReadFileToolName, map[string]any{"path": "a.txt", "offset": 3}))
+	assert.Equal(t, "     2\ttwo\n... (showing lines 2-2 of 3, read from offset 3 for more)\n",
+		f.call(ReadFileToolName, map[string]any{"path": "a.txt", "offset": 2, "limit": 1}))
+	assert.Equal(t, "(file empty.txt is empty)", f.call(ReadFileToolName, map[string]any{"path": "empty.txt"}))
+
+	assert.Equal(t, "error: offset 4 is beyond the end of file a.txt, which has 3 lines",
+		f.call(ReadFileToolName, map[string]any{"path": "a.txt", "offset": 4}))
+	assert.Equal(t, "error: file missing.txt doesn't exist", f.call(ReadFileToolName, map[string]any{"path": "missing.txt"}))
+	assert.Equal(t, "error: file bin is binary", f.call(ReadFileToolName, map[string]any{"path": "bin"}))
+	assert.Equal(t, "error: file large.txt is too large: 65 bytes, the limit is 64 bytes",
+		f.call(ReadFileToolName, map[string]any{"path": "large.txt"}))
+	assert.Equal(t, "error: . is a directory, use list_dir to list it", f.call(ReadFileToolName, map[string]any{"path": "."}))
+}
+
+func TestSandbox(t *testing.T) {
+	outside := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(outside, "secret"), []byte("secret"), 0o644))
+
+	f := newTestFS(t, nil, map[string]string{"data/a.txt": "a\n"})
+	assert.NoError(t, os.Symlink(filepath.Join(outside, "secret"), filepath.Join(f.root, "secret_link")))
+	assert.NoError(t, os.Symlink(outside, filepath.Join(f.root, "outside_dir")))
+	assert.NoError(t, os.Symlink(filepath.Join(outside, "missing"), filepath.Join(f.root, "dangling")))
+	assert.NoError(t, os.Symlink(filepath.Join(f.root, "data"), filepath.Join(f.root, "data_link")))
+
+	for _, p := range []string{"../secret", "data/../../secret", filepath.Join(outside, "secret")} {
+		assert.Contains(t, f.call(ReadFileToolName, map[string]any{"path": p}), "is outside the root directory, use the paths relative to the root")
+	}
+	assert.Equal(t, "error: path secret_link is outside the root directory through a symlink",
+		f.call(ReadFileToolName, map[string]any{"path": "secret_link"}))
+	assert.Equal(t, "error: path outside_dir/new.txt is outside the root directory through a symlink",
+		f.call(WriteFileToolName, map[string]any{"path": "outside_dir/new.txt", "content": "x"}))
+	assert.Equal(t, "error: path dangling is a broken symlink",
+		f.call(WriteFileToolName, map[string]any{"path": "dangling", "content": "x"}))
+	assert.Contains(t, f.call(ApplyPatchToolName, map[string]any{"patch": "--- /dev/null\n+++ b/outside_dir/x\n@@ -0,0 +1 @@\n+x\n"}),
+		"outside the root directory through a symlink")
+	_, err := os.Stat(filepath.Join(outside, "new.txt"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(outside, "x"))
+	assert.True(t, os.IsNotExist(err))
+
+	// the symlinks within the root are followed
+	assert.Equal(t, "     1\ta\n", f.call(ReadFileToolName, map[string]any{"path": "data_link/a.txt"}))
+	assert.Equal(t, "     1\ta\n", f.call(ReadFileToolName, map[string]any{"path": filepath.Join(f.root, "data", "a.txt")}))
+
+	// grep doesn't follow the symlinks
+	assert.Equal(t, "data/a.txt:1: a\n", f.call(GrepToolName, map[string]any{"pattern": "a|secret"}))
+
+	_, err = NewTools(context.Background(), &Config{Root: filepath.Join(f.root, "data", "a.txt")})
+	assert.Error(t, err)
+	_, err = NewTools(context.Background(), &Config{})
+	assert.Error(t, err)
+}
+
+func TestListDirAndGrep(t *testing.T) {
+	f := newTestFS(t, &Config{MaxGrepMatches: 2, MaxListEntries: 4}, map[string]string{
+		"main.go":          "package main\n\nfunc main() {}\n",
+		"pkg/util.go":      "package pkg\n\nfunc Util() {}\n",
+		"pkg/util_test.go": "package pkg\n",
+		"README.md":        "# Main\n",
+		".git/config":      "package\n",
+	})
+
+	assert.Equal(t, ".git/\nREADME.md (7 bytes)\nmain.go (29 bytes)\npkg/\n", f.call(ListDirToolName, map[string]any{}))
+	assert.Equal(t, "util.go (28 bytes)\nutil_test.go (12 bytes)\n", f.call(ListDirToolName, map[string]any{"path": "pkg"}))
+	assert.Equal(t, ".git/\n.git/config (8 bytes)\nREADME.md (7 bytes)\nmain.go (29 bytes)\n... (stopped at 4 entries, list the subdirectories for more)\n",
+		f.call(ListDirToolName, map[string]any{"recursive": true}))
+	assert.Equal(t, "error: main.go is not a directory", f.call(ListDirToolName, map[string]any{"path": "main.go"}))
+
+	assert.Equal(t, "main.go:3: func main() {}\npkg/util.go:3: func Util() {}\n",
+		f.call(GrepToolName, map[string]any{"pattern": `^func \w+\(`}))
+	assert.Equal(t, "README.md:1: # Main\nmain.go:1: package main\n... (stopped at 2 matches, narrow the search for more)\n",
+		f.call(GrepToolName, map[string]any{"pattern": "main", "ignore_case": true}))
+	assert.Equal(t, "pkg/util.go:1: package pkg\npkg/util_test.go:1: package pkg\n",
+		f.call(GrepToolName, map[string]any{"pattern": "package", "path": "pkg"}))
+	assert.Equal(t, "pkg/util_test.go:1: package pkg\n",
+		f.call(GrepToolName, map[string]any{"pattern": "package", "include": "*_test.go"}))
+	assert.Equal(t, "no matches", f.call(GrepToolName, map[string]any{"pattern": "nothing"}))
+	assert.Contains(t, f.call(GrepToolName, map[string]any{"pattern": "("}), "error: invalid pattern")
+}
+
+func TestWriteFile(t *testing.T) {
+	f := newTestFS(t, nil, map[string]string{"a.txt": "one\ntwo\nthree\n"})
+
+	assert.Equal(t, "applied the changes:\n--- /dev/null\n+++ b/dir/new.txt\n@@ -0,0 +1,2 @@\n+hello\n+world\n",
+		f.call(WriteFileToolName, map[string]any{"path": "dir/new.txt", "content": "hello\nworld\n"}))
+	assert.Equal(t, "hello\nworld\n", f.read("dir/new.txt"))
+
+	assert.Equal(t, "applied the changes:\n--- a/a.txt\n+++ b/a.txt\n@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n",
+		f.call(WriteFileToolName, map[string]any{"path": "a.txt", "content": "one\n2\nthree\n"}))
+	assert.Equal(t, "one\n2\nthree\n", f.read("a.txt"))
+	assert.Equal(t, "no changes", f.call(WriteFileToolName, map[string]any{"path": "a.txt", "content": "one\n2\nthree\n"}))
+
+	dry := newTestFS(t, &Config{DryRun: true}, map[string]string{"a.txt": "one\n"})
+	assert.Equal(t, "dry run, no file is written. The intended changes:\n--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-one\n+two\n\\ No newline at end of file\n",
+		dry.call(WriteFileToolName, map[string]any{"path": "a.txt", "content": "two"}))
+	assert.Equal(t, "one\n", dry.read("a.txt"))
+
+	limited := newTestFS(t, &Config{MaxFileSize: 4}, nil)
+	assert.Equal(t, "error: content is too large: 5 bytes, the limit is 4 bytes",
+		limited.call(WriteFileToolName, map[string]any{"path": "a.txt", "content": "12345"}))
+
+	readOnly := newTestFS(t, &Config{ReadOnly: true}, nil)
+	assert.Len(t, readOnly.tools, 3)
+	assert.Nil(t, readOnly.tools[WriteFileToolName])
+	assert.Nil(t, readOnly.tools[ApplyPatchToolName])
+}
+
+func TestApplyPatch(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	original := strings.Join(lines, "\n") + "\n"
+
+	t.Run("edit create and delete", func(t *testing.T) {
+		f := newTestFS(t, nil, map[string]string{"a.txt": original, "old.txt": "bye\n"})
+		// the line numbers of the hunks are wrong, and the hunks are located by the context
+		patch := "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n" +
+			"@@ -1,3 +1,3 @@\n line 2\n-line 3\n+line three\n line 4\n" +
+			"@@ -30,3 +30,4 @@\n line 17\n line 18\n+line 18.5\n line 19\n" +
+			"\n" +
+			"--- /dev/null\n+++ b/new/b.txt\n@@ -0,0 +1,2 @@\n+hello\n+world\n" +
+			"--- a/old.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-bye\n"
+		out := f.call(ApplyPatchToolName, map[string]any{"patch": patch})
+		assert.Equal(t, "applied the changes:\n"+
+			"--- a/a.txt\n+++ b/a.txt\n@@ -1,6 +1,6 @@\n line 1\n line 2\n-line 3\n+line three\n line 4\n line 5\n line 6\n"+
+			"@@ -16,5 +16,6 @@\n line 16\n line 17\n line 18\n+line 18.5\n line 19\n line 20\n"+
+			"--- /dev/null\n+++ b/new/b.txt\n@@ -0,0 +1,2 @@\n+hello\n+world\n"+
+			"--- a/old.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-bye\n", out)
+
+		expected := strings.Replace(strings.Replace(original, "line 3\n", "line three\n", 1), "line 19\n", "line 18.5\nline 19\n", 1)
+		assert.Equal(t, expected, f.read("a.txt"))
+		assert.Equal(t, "hello\nworld\n", f.read("new/b.txt"))
+		assert.Equal(t, "<missing>", f.read("old.txt"))
+	})
+
+	t.Run("all or nothing", func(t *testing.T) {
+		f := newTestFS(t, nil, map[string]string{"a.txt": original, "b.txt": "b\n"})
+		patch := "--- a/b.txt\n+++ b/b.txt\n@@ -1 +1 @@\n-b\n+B\n" +
+			"--- a/a.txt\n+++ b/a.txt\n@@ -1,2 +1,2 @@\n line 1\n-line 3\n+line three\n"
+		assert.Equal(t, "error: failed to patch a.txt: hunk 1 doesn't apply: its context and removed lines are not found in the file, "+
+			"read the file again and retry with the exact lines", f.call(ApplyPatchToolName, map[string]any{"patch": patch}))
+		assert.Equal(t, "b\n", f.read("b.txt"))
+
+		assert.Equal(t, "error: file b.txt already exists, patch it instead of creating it",
+			f.call(ApplyPatchToolName, map[string]any{"patch": "--- /dev/null\n+++ b/b.txt\n@@ -0,0 +1 @@\n+x\n"}))
+		assert.Equal(t, "error: file missing.txt doesn't exist",
+			f.call(ApplyPatchToolName, map[string]any{"patch": "--- a/missing.txt\n+++ b/missing.txt\n@@ -1 +1 @@\n-x\n+y\n"}))
+		assert.Contains(t, f.call(ApplyPatchToolName, map[string]any{"patch": "just edit it"}), "error: invalid patch")
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		f := newTestFS(t, &Config{DryRun: true}, map[string]string{"a.txt": "a\nb\n"})
+		out := f.call(ApplyPatchToolName, map[string]any{"patch": "--- a/a.txt\n+++ b/a.txt\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n"})
+		assert.Equal(t, "dry run, no file is written. The intended changes:\n--- a/a.txt\n+++ b/a.txt\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n", out)
+		assert.Equal(t, "a\nb\n", f.read("a.txt"))
+	})
+}
+
+func TestUnifiedDiffRoundTrip(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	base := strings.Join(lines, "\n") + "\n"
+
+	for _, c := range []struct{ old, new string }{
+		{base, strings.Replace(base, "line 5\n", "", 1)},
+		{base, strings.Replace(base, "line 10\n", "line 10\nline 10.5\n", 1) + "line 31\n"},
+		{base, strings.Replace(strings.Replace(base, "line 1\n", "first\n", 1), "line 30\n", "last\n", 1)},
+		{base, strings.TrimSuffix(base, "\n")},
+		{"a\nb", "a\nc"},
+		{"a\nb", "a\nb\nc\n"},
+		{"", "a\n"},
+		{"x\n", ""},
+	} {
+		d := unifiedDiff("f", "f", c.old, c.new)
+		patches, err := parsePatch(d)
+		assert.NoError(t, err, d)
+		if !assert.Len(t, patches, 1) {
+			continue
+		}
+		oldLines, trailing := splitLines(c.old)
+		if len(oldLines) == 0 {
+			trailing = true
+		}
+		result, resultTrailing, err := patches[0].apply(oldLines, trailing)
+		assert.NoError(t, err, d)
+		assert.Equal(t, c.new, joinLines(result, resultTrailing), d)
+	}
+}
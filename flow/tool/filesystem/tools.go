@@ -0,0 +1,536 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+const (
+	maxLineLength = 2000
+	// binarySniffLen is the length of the head of the file looked for NUL to detect the binary files.
+	binarySniffLen = 8000
+)
+
+// errStopWalk stops walking the directory when the limit is reached.
+var errStopWalk = errors.New("stop walk")
+
+type readFileInput struct {
+	Path   string `json:"path" jsonschema:"description=the path of the file relative to the root directory"`
+	Offset int    `json:"offset,omitempty" jsonschema:"description=the line number to start reading from. Starts from 1 and defaults to 1"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"description=the max number of lines to read"`
+}
+
+type writeFileInput struct {
+	Path    string `json:"path" jsonschema:"description=the path of the file relative to the root directory"`
+	Content string `json:"content" jsonschema:"description=the full content of the file"`
+}
+
+type listDirInput struct {
+	Path      string `json:"path,omitempty" jsonschema:"description=the path of the directory relative to the root directory. Defaults to the root directory"`
+	Recursive bool   `json:"recursive,omitempty" jsonschema:"description=whether to list the entries of the subdirectories as well"`
+}
+
+type grepInput struct {
+	Pattern    string `json:"pattern" jsonschema:"description=the regular expression in the RE2 syntax to search for"`
+	Path       string `json:"path,omitempty" jsonschema:"description=the file or the directory to search in relative to the root directory. Defaults to the root directory"`
+	Include    string `json:"include,omitempty" jsonschema:"description=the glob of the file names to search in. e.g. *.go"`
+	IgnoreCase bool   `json:"ignore_case,omitempty" jsonschema:"description=whether to match case insensitively"`
+}
+
+type applyPatchInput struct {
+	Patch string `json:"patch" jsonschema:"description=the patch in the unified diff format. Use /dev/null as the old file to create a file and as the new file to delete a file"`
+}
+
+func (s *sandbox) readTools() ([]tool.BaseTool, error) {
+	readFile, err := newTool(ReadFileToolName,
+		"Read a text file. The lines are returned with the line numbers. Read large files in parts by offset and limit.",
+		s.readFile)
+	if err != nil {
+		return nil, err
+	}
+	listDir, err := newTool(ListDirToolName,
+		"List the entries of a directory. Directories end with / and symlinks end with @.",
+		s.listDir)
+	if err != nil {
+		return nil, err
+	}
+	grep, err := newTool(GrepToolName,
+		"Search the files for the lines matching a regular expression. The matched lines are returned as path:line: text.",
+		s.grep)
+	if err != nil {
+		return nil, err
+	}
+	return []tool.BaseTool{readFile, listDir, grep}, nil
+}
+
+func (s *sandbox) writeTools() ([]tool.BaseTool, error) {
+	writeFile, err := newTool(WriteFileToolName,
+		"Create a file or overwrite it with the content, creating the missing directories. Returns the diff of the changes. "+
+			"Prefer apply_patch to change a part of an existing file.",
+		s.writeFile)
+	if err != nil {
+		return nil, err
+	}
+	applyPatch, err := newTool(ApplyPatchToolName,
+		"Apply a patch in the unified diff format to one or more files. The context and removed lines must match the files exactly. "+
+			"Returns the diff of the changes.",
+		s.applyPatch)
+	if err != nil {
+		return nil, err
+	}
+	return []tool.BaseTool{writeFile, applyPatch}, nil
+}
+
+// newTool infers the tool from fn, returning the errors of fn to the model except the errors of ctx.
+func newTool[T any](name, desc string, fn func(ctx context.Context, input T) (string, error)) (tool.InvokableTool, error) {
+	t, err := utils.InferTool(name, desc, func(ctx context.Context, input T) (string, error) {
+		out, err := fn(ctx, input)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", err
+			}
+			return errorResult(err), nil
+		}
+		return out, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tool %s: %w", name, err)
+	}
+	return t, nil
+}
+
+func (s *sandbox) readFile(_ context.Context, input readFileInput) (string, error) {
+	full, display, err := s.resolve(input.Path)
+	if err != nil {
+		return "", err
+	}
+	data, err := s.readRegularFile(full, display)
+	if err != nil {
+		return "", err
+	}
+	if isBinary(data) {
+		return "", fmt.Errorf("file %s is binary", display)
+	}
+	lines, _ := splitLines(string(data))
+	if len(lines) == 0 {
+		return fmt.Sprintf("(file %s is empty)", display), nil
+	}
+
+	offset := input.Offset
+	if offset <= 0 {
+		offset = 1
+	}
+	if offset > len(lines) {
+		return "", fmt.Errorf("offset %d is beyond the end of file %s, which has %d lines", offset, display, len(lines))
+	}
+	limit := input.Limit
+	if limit <= 0 || limit > s.maxReadLines {
+		limit = s.maxReadLines
+	}
+	end := offset - 1 + limit
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	sb := &strings.Builder{}
+	for i := offset - 1; i < end; i++ {
+		fmt.Fprintf(sb, "%6d\t%s\n", i+1, truncateLine(lines[i]))
+	}
+	if end < len(lines) {
+		fmt.Fprintf(sb, "... (showing lines %d-%d of %d, read from offset %d for more)\n", offset, end, len(lines), end+1)
+	}
+	return sb.String(), nil
+}
+
+func (s *sandbox) listDir(ctx context.Context, input listDirInput) (string, error) {
+	full, display, err := s.resolve(input.Path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(full)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("directory %s doesn't exist", display)
+		}
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", display)
+	}
+
+	var entries []string
+	truncated := false
+	add := func(rel string, d fs.DirEntry) bool {
+		if len(entries) >= s.maxListEntries {
+			truncated = true
+			return false
+		}
+		entries = append(entries, formatEntry(rel, d))
+		return true
+	}
+
+	if !input.Recursive {
+		des, err := os.ReadDir(full)
+		if err != nil {
+			return "", err
+		}
+		for _, d := range des {
+			if !add(d.Name(), d) {
+				break
+			}
+		}
+	} else {
+		// WalkDir doesn't follow the symlinks, so the walk stays in the root
+		err = filepath.WalkDir(full, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if p == full {
+				return nil
+			}
+			rel, _ := filepath.Rel(full, p)
+			if !add(filepath.ToSlash(rel), d) {
+				return errStopWalk
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStopWalk) {
+			return "", err
+		}
+	}
+
+	if len(entries) == 0 {
+		return fmt.Sprintf("(directory %s is empty)", display), nil
+	}
+	out := strings.Join(entries, "\n") + "\n"
+	if truncated {
+		out += fmt.Sprintf("... (stopped at %d entries, list the subdirectories for more)\n", s.maxListEntries)
+	}
+	return out, nil
+}
+
+func formatEntry(rel string, d fs.DirEntry) string {
+	switch {
+	case d.Type()&fs.ModeSymlink != 0:
+		return rel + "@"
+	case d.IsDir():
+		return rel + "/"
+	}
+	if info, err := d.Info(); err == nil {
+		return fmt.Sprintf("%s (%d bytes)", rel, info.Size())
+	}
+	return rel
+}
+
+func (s *sandbox) grep(ctx context.Context, input grepInput) (string, error) {
+	pattern := input.Pattern
+	if input.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern: %w", err)
+	}
+	if input.Include != "" {
+		if _, err = filepath.Match(input.Include, ""); err != nil {
+			return "", fmt.Errorf("invalid include glob: %w", err)
+		}
+	}
+
+	full, display, err := s.resolve(input.Path)
+	if err != nil {
+		return "", err
+	}
+	if _, err = os.Stat(full); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("path %s doesn't exist", display)
+		}
+		return "", err
+	}
+
+	var matches []string
+	truncated := false
+	err = filepath.WalkDir(full, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if d.IsDir() {
+			if p != full && isVCSDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// the symlinks are skipped as they may point out of the root
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if input.Include != "" {
+			if ok, _ := filepath.Match(input.Include, d.Name()); !ok {
+				return nil
+			}
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > s.maxFileSize {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil || isBinary(data) {
+			return nil
+		}
+
+		lines, _ := splitLines(string(data))
+		for i, line := range lines {
+			if !re.MatchString(line) {
+				continue
+			}
+			if len(matches) >= s.maxGrepMatches {
+				truncated = true
+				return errStopWalk
+			}
+			matches = append(matches, fmt.Sprintf("%s:%d: %s", s.display(p), i+1, truncateLine(line)))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return "", err
+	}
+
+	if len(matches) == 0 {
+		return "no matches", nil
+	}
+	out := strings.Join(matches, "\n") + "\n"
+	if truncated {
+		out += fmt.Sprintf("... (stopped at %d matches, narrow the search for more)\n", s.maxGrepMatches)
+	}
+	return out, nil
+}
+
+func isVCSDir(name string) bool {
+	return name == ".git" || name == ".hg" || name == ".svn"
+}
+
+func (s *sandbox) writeFile(_ context.Context, input writeFileInput) (string, error) {
+	if int64(len(input.Content)) > s.maxFileSize {
+		return "", fmt.Errorf("content is too large: %d bytes, the limit is %d bytes", len(input.Content), s.maxFileSize)
+	}
+	full, display, err := s.resolve(input.Path)
+	if err != nil {
+		return "", err
+	}
+
+	f := &fileState{full: full, display: display}
+	if err = s.load(f); err != nil {
+		return "", err
+	}
+	f.content, f.exists = input.Content, true
+
+	return s.commit([]*fileState{f})
+}
+
+func (s *sandbox) applyPatch(_ context.Context, input applyPatchInput) (string, error) {
+	patches, err := parsePatch(input.Patch)
+	if err != nil {
+		return "", fmt.Errorf("invalid patch: %w", err)
+	}
+
+	// the patches are applied in memory, and the files are written only if all of them apply
+	var files []*fileState
+	byPath := make(map[string]*fileState)
+	get := func(name string) (*fileState, error) {
+		full, display, err := s.resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		if f, ok := byPath[full]; ok {
+			return f, nil
+		}
+		f := &fileState{full: full, display: display}
+		if err = s.load(f); err != nil {
+			return nil, err
+		}
+		byPath[full] = f
+		files = append(files, f)
+		return f, nil
+	}
+
+	for _, p := range patches {
+		var src, dst *fileState
+		if p.oldName != devNull {
+			if src, err = get(p.oldName); err != nil {
+				return "", err
+			}
+			if !src.exists {
+				return "", fmt.Errorf("file %s doesn't exist", src.display)
+			}
+		}
+		if p.newName != devNull {
+			if dst, err = get(p.newName); err != nil {
+				return "", err
+			}
+			if src == nil && dst.exists {
+				return "", fmt.Errorf("file %s already exists, patch it instead of creating it", dst.display)
+			}
+		}
+
+		var lines []string
+		trailing := true
+		if src != nil {
+			lines, trailing = splitLines(src.content)
+			if len(lines) == 0 {
+				trailing = true
+			}
+		}
+		name := p.newName
+		if dst == nil {
+			name = p.oldName
+		}
+		result, resultTrailing, err := p.apply(lines, trailing)
+		if err != nil {
+			return "", fmt.Errorf("failed to patch %s: %w", name, err)
+		}
+
+		if dst == nil {
+			src.content, src.exists = "", false
+			continue
+		}
+		if src != nil && src != dst {
+			// renamed
+			src.content, src.exists = "", false
+		}
+		dst.content, dst.exists = joinLines(result, resultTrailing), true
+		if int64(len(dst.content)) > s.maxFileSize {
+			return "", fmt.Errorf("file %s would be too large: %d bytes, the limit is %d bytes", dst.display, len(dst.content), s.maxFileSize)
+		}
+	}
+
+	return s.commit(files)
+}
+
+// fileState is the original and the changed content of a file to write.
+type fileState struct {
+	full    string
+	display string
+
+	origExists  bool
+	origContent string
+	mode        fs.FileMode
+
+	exists  bool
+	content string
+}
+
+func (s *sandbox) load(f *fileState) error {
+	info, err := os.Stat(f.full)
+	if errors.Is(err, fs.ErrNotExist) {
+		f.mode = 0o644
+		return nil
+	}
+	data, err := s.readRegularFile(f.full, f.display)
+	if err != nil {
+		return err
+	}
+	if isBinary(data) {
+		return fmt.Errorf("file %s is binary", f.display)
+	}
+	f.origExists, f.origContent, f.mode = true, string(data), info.Mode().Perm()
+	f.exists, f.content = f.origExists, f.origContent
+	return nil
+}
+
+// commit writes the changed files, or reports the intended changes in the dry run mode, returning the diffs.
+func (s *sandbox) commit(files []*fileState) (string, error) {
+	var diffs []string
+	var changed []*fileState
+	for _, f := range files {
+		if f.exists == f.origExists && f.content == f.origContent {
+			continue
+		}
+		oldName, newName := f.display, f.display
+		if !f.origExists {
+			oldName = devNull
+		}
+		if !f.exists {
+			newName = devNull
+		}
+		d := unifiedDiff(oldName, newName, f.origContent, f.content)
+		if d == "" {
+			// an empty file created or deleted
+			d = fmt.Sprintf("--- %s\n+++ %s\n", oldName, newName)
+		}
+		diffs = append(diffs, d)
+		changed = append(changed, f)
+	}
+	if len(changed) == 0 {
+		return "no changes", nil
+	}
+	if s.dryRun {
+		return "dry run, no file is written. The intended changes:\n" + strings.Join(diffs, ""), nil
+	}
+
+	for _, f := range changed {
+		if !f.exists {
+			if err := os.Remove(f.full); err != nil {
+				return "", fmt.Errorf("failed to delete %s: %w", f.display, err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(f.full), 0o755); err != nil {
+			return "", fmt.Errorf("failed to create directory of %s: %w", f.display, err)
+		}
+		if err := os.WriteFile(f.full, []byte(f.content), f.mode); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", f.display, err)
+		}
+	}
+	return "applied the changes:\n" + strings.Join(diffs, ""), nil
+}
+
+func isBinary(data []byte) bool {
+	if len(data) > binarySniffLen {
+		data = data[:binarySniffLen]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+func truncateLine(line string) string {
+	if len(line) <= maxLineLength {
+		return line
+	}
+	end := maxLineLength
+	for end > 0 && !utf8.RuneStart(line[end]) {
+		end--
+	}
+	return line[:end] + "... (line truncated)"
+}
@@ -0,0 +1,421 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package mcp provides the Model Context Protocol (MCP) client,
+// which exposes the tools served by an MCP server as eino tools.
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+// ErrConnectionClosed is returned by the requests in flight when the connection to the server is lost.
+var ErrConnectionClosed = errors.New("mcp connection closed")
+
+const defaultMaxReconnects = 1
+
+// ClientConfig is the config for Client.
+// Set Command to connect to a local server process over stdio, or URL to connect to a server over streamable HTTP.
+type ClientConfig struct {
+	// Command is the command spawning the local server process.
+	Command string
+	// Args are the arguments of Command.
+	Args []string
+	// Env is the environment of the server process, optional. By default, it's the environment of the current process.
+	Env []string
+	// Dir is the working directory of the server process, optional.
+	Dir string
+	// Stderr receives the stderr of the server process, optional.
+	Stderr io.Writer
+
+	// URL is the endpoint of the server serving streamable HTTP.
+	URL string
+	// HTTPClient sends the http requests, optional. By default, it's http.DefaultClient.
+	HTTPClient *http.Client
+	// Headers are set on each http request, e.g. the Authorization header, optional.
+	Headers map[string]string
+
+	// NewTransport creates the Transport to the server, optional, overriding Command and URL.
+	// It's called for each (re)connection.
+	NewTransport func(ctx context.Context) (Transport, error)
+
+	// ClientInfo is the name and the version reported to the server, optional.
+	ClientInfo Implementation
+	// MaxReconnects is the max number of reconnections for one request when the connection is lost, default 1.
+	// Negative means never reconnecting.
+	MaxReconnects int
+	// OnToolsChanged is called in a new goroutine when the server notifies the changes of the tool list, optional.
+	// Tools lists the tools from the server again after the notification, so it can be called in OnToolsChanged.
+	OnToolsChanged func()
+}
+
+// Client is the MCP client, which lists the tools of the server and calls them.
+// The connection is established lazily, and re-established when it's lost, e.g. the server process exits,
+// or the http session expires.
+// Client is a tool.Toolkit, so it can be given to ToolsNodeConfig.Toolkits to refresh the tools on each run.
+// e.g.
+//
+//	cli, err := mcp.NewClient(ctx, &mcp.ClientConfig{Command: "npx", Args: []string{"-y", "some-mcp-server"}})
+//	tools, err := cli.Tools(ctx)
+//	agent, err := react.NewAgent(ctx, &react.AgentConfig{
+//		ToolCallingModel: cm,
+//		ToolsConfig:      compose.ToolsNodeConfig{Tools: tools},
+//	})
+type Client struct {
+	config       *ClientConfig
+	newTransport func(ctx context.Context) (Transport, error)
+
+	mu         sync.Mutex
+	conn       *connection
+	serverInfo *InitializeResult
+
+	nextID int64
+
+	toolsMu sync.Mutex
+	tools   []tool.BaseTool
+	// toolsGen is increased when the tool list may have changed, and toolsGenListed is the one when tools are listed
+	toolsGen       int64
+	toolsGenListed int64
+}
+
+var _ tool.Toolkit = &Client{}
+
+// NewClient creates a Client and connects to the server.
+func NewClient(ctx context.Context, config *ClientConfig) (*Client, error) {
+	c := &Client{config: config}
+
+	switch {
+	case config.NewTransport != nil:
+		c.newTransport = config.NewTransport
+	case config.Command != "":
+		c.newTransport = func(_ context.Context) (Transport, error) {
+			cmd := exec.Command(config.Command, config.Args...)
+			cmd.Env = config.Env
+			cmd.Dir = config.Dir
+			cmd.Stderr = config.Stderr
+			return NewStdioTransport(cmd), nil
+		}
+	case config.URL != "":
+		c.newTransport = func(_ context.Context) (Transport, error) {
+			return NewStreamableHTTPTransport(config.URL, config.HTTPClient, config.Headers), nil
+		}
+	default:
+		return nil, errors.New("command or url is required")
+	}
+
+	if _, err := c.connect(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ServerInfo returns the result of the initialization with the server.
+func (c *Client) ServerInfo() *InitializeResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.serverInfo
+}
+
+// Tools returns the tools of the server, each of which is a tool.InvokableTool calling the server.
+// The list is cached, and listed from the server again after the server notifies the changes.
+func (c *Client) Tools(ctx context.Context) ([]tool.BaseTool, error) {
+	c.toolsMu.Lock()
+	defer c.toolsMu.Unlock()
+	gen := atomic.LoadInt64(&c.toolsGen)
+	if c.tools != nil && gen == c.toolsGenListed {
+		return c.tools, nil
+	}
+
+	var listed []*Tool
+	params := &ListToolsParams{}
+	for {
+		result := &ListToolsResult{}
+		if err := c.request(ctx, MethodToolsList, params, result); err != nil {
+			return nil, fmt.Errorf("failed to list mcp tools: %w", err)
+		}
+		listed = append(listed, result.Tools...)
+		if result.NextCursor == "" {
+			break
+		}
+		params = &ListToolsParams{Cursor: result.NextCursor}
+	}
+
+	tools := make([]tool.BaseTool, 0, len(listed))
+	for _, t := range listed {
+		mt, err := newMCPTool(c, t)
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, mt)
+	}
+	c.tools = tools
+	c.toolsGenListed = gen
+	return tools, nil
+}
+
+// CallTool calls the tool of the server with the arguments in JSON format.
+func (c *Client) CallTool(ctx context.Context, name string, argumentsInJSON string) (*CallToolResult, error) {
+	params := &CallToolParams{Name: name}
+	if argumentsInJSON != "" {
+		params.Arguments = []byte(argumentsInJSON)
+	}
+	result := &CallToolResult{}
+	if err := c.request(ctx, MethodToolsCall, params, result); err != nil {
+		return nil, fmt.Errorf("failed to call mcp tool %s: %w", name, err)
+	}
+	return result, nil
+}
+
+// Close closes the connection to the server.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	conn := c.conn
+	c.conn = nil
+	c.mu.Unlock()
+	if conn == nil {
+		return nil
+	}
+	return conn.transport.Close()
+}
+
+func (c *Client) request(ctx context.Context, method string, params, result any) error {
+	maxReconnects := c.config.MaxReconnects
+	if maxReconnects == 0 {
+		maxReconnects = defaultMaxReconnects
+	}
+
+	for attempt := 0; ; attempt++ {
+		conn, err := c.connect(ctx)
+		if err != nil {
+			return err
+		}
+		err = conn.call(ctx, c.newID(), method, params, result)
+		if (errors.Is(err, ErrConnectionClosed) || errors.Is(err, ErrSessionExpired)) && attempt < maxReconnects {
+			c.reset(conn)
+			continue
+		}
+		return err
+	}
+}
+
+func (c *Client) connect(ctx context.Context) (*connection, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil && !c.conn.isClosed() {
+		return c.conn, nil
+	}
+	if c.conn != nil {
+		_ = c.conn.transport.Close()
+		c.conn = nil
+	}
+
+	transport, err := c.newTransport(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mcp transport: %w", err)
+	}
+	conn := newConnection(transport, c.onNotification)
+	if err = transport.Start(ctx, conn.handle, conn.close); err != nil {
+		return nil, fmt.Errorf("failed to start mcp transport: %w", err)
+	}
+
+	clientInfo := c.config.ClientInfo
+	if clientInfo.Name == "" {
+		clientInfo = Implementation{Name: "eino", Version: "1.0.0"}
+	}
+	info := &InitializeResult{}
+	err = conn.call(ctx, c.newID(), MethodInitialize, &InitializeParams{
+		ProtocolVersion: ProtocolVersion,
+		Capabilities:    map[string]any{},
+		ClientInfo:      clientInfo,
+	}, info)
+	if err != nil {
+		_ = transport.Close()
+		return nil, fmt.Errorf("failed to initialize mcp session: %w", err)
+	}
+	if err = transport.Send(ctx, &Message{JSONRPC: jsonrpcVersion, Method: MethodInitialized}); err != nil {
+		_ = transport.Close()
+		return nil, fmt.Errorf("failed to initialize mcp session: %w", err)
+	}
+
+	c.conn = conn
+	c.serverInfo = info
+
+	// the tools may change during the reconnection
+	c.markToolsStale()
+	return conn, nil
+}
+
+func (c *Client) reset(conn *connection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == conn {
+		_ = conn.transport.Close()
+		c.conn = nil
+	}
+}
+
+func (c *Client) newID() int64 {
+	return atomic.AddInt64(&c.nextID, 1)
+}
+
+func (c *Client) onNotification(msg *Message) {
+	if msg.Method != MethodToolsListChanged {
+		return
+	}
+	c.markToolsStale()
+	if c.config.OnToolsChanged != nil {
+		// not called on the goroutine delivering the messages, which would block the responses the callback may wait for
+		go c.config.OnToolsChanged()
+	}
+}
+
+func (c *Client) markToolsStale() {
+	atomic.AddInt64(&c.toolsGen, 1)
+}
+
+type connection struct {
+	transport      Transport
+	onNotification func(*Message)
+
+	mu      sync.Mutex
+	pending map[string]chan *Message
+	closed  bool
+	err     error
+}
+
+func newConnection(transport Transport, onNotification func(*Message)) *connection {
+	return &connection{
+		transport:      transport,
+		onNotification: onNotification,
+		pending:        make(map[string]chan *Message),
+	}
+}
+
+func (c *connection) call(ctx context.Context, id int64, method string, params, result any) error {
+	rawParams, err := sonic.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to marshal params: %w", err)
+	}
+
+	key := strconv.FormatInt(id, 10)
+	ch := make(chan *Message, 1)
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return fmt.Errorf("%w: %v", ErrConnectionClosed, c.err)
+	}
+	c.pending[key] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, key)
+		c.mu.Unlock()
+	}()
+
+	err = c.transport.Send(ctx, &Message{
+		JSONRPC: jsonrpcVersion,
+		ID:      []byte(key),
+		Method:  method,
+		Params:  rawParams,
+	})
+	if err != nil {
+		if errors.Is(err, ErrSessionExpired) {
+			return err
+		}
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case resp, ok := <-ch:
+		if !ok {
+			c.mu.Lock()
+			cause := c.err
+			c.mu.Unlock()
+			return fmt.Errorf("%w: %v", ErrConnectionClosed, cause)
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil || len(resp.Result) == 0 {
+			return nil
+		}
+		if err = sonic.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("failed to unmarshal result: %w", err)
+		}
+		return nil
+	}
+}
+
+func (c *connection) handle(msg *Message) {
+	switch {
+	case msg.IsResponse():
+		c.mu.Lock()
+		ch, ok := c.pending[string(msg.ID)]
+		if ok {
+			delete(c.pending, string(msg.ID))
+		}
+		c.mu.Unlock()
+		if ok {
+			ch <- msg
+		}
+	case msg.IsNotification():
+		c.onNotification(msg)
+	case msg.IsRequest():
+		resp := &Message{JSONRPC: jsonrpcVersion, ID: msg.ID}
+		if msg.Method == MethodPing {
+			resp.Result = []byte("{}")
+		} else {
+			resp.Error = &Error{Code: CodeMethodNotFound, Message: "method not found: " + msg.Method}
+		}
+		go func() {
+			_ = c.transport.Send(context.Background(), resp)
+		}()
+	}
+}
+
+func (c *connection) close(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.err = err
+	for key, ch := range c.pending {
+		close(ch)
+		delete(c.pending, key)
+	}
+}
+
+func (c *connection) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
@@ -0,0 +1,439 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+const stubEnv = "EINO_MCP_STUB"
+
+func TestMain(m *testing.M) {
+	// the test binary serves as the stub server process of the stdio transport
+	if os.Getenv(stubEnv) == "stdio" {
+		serveStubStdio()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+type stubServer struct {
+	mu    sync.Mutex
+	tools []*Tool
+}
+
+func newStubServer() *stubServer {
+	return &stubServer{tools: []*Tool{
+		{
+			Name:        "echo",
+			Description: "echo the text",
+			InputSchema: []byte(`{"type":"object","properties":{"text":{"type":"string"}},"required":["text"]}`),
+		},
+		{Name: "image", Description: "draw an image", InputSchema: []byte(`{"type":"object"}`)},
+		{Name: "fail", Description: "always fail", InputSchema: []byte(`{"type":"object"}`)},
+		{Name: "add_tool", Description: "add a tool", InputSchema: []byte(`{"type":"object"}`)},
+		{Name: "crash", Description: "crash the server", InputSchema: []byte(`{"type":"object"}`)},
+	}}
+}
+
+// handle returns the notifications to send before the response, and the response.
+func (s *stubServer) handle(msg *Message) ([]*Message, *Message) {
+	if !msg.IsRequest() {
+		return nil, nil
+	}
+
+	var result any
+	var notes []*Message
+	switch msg.Method {
+	case MethodInitialize:
+		result = &InitializeResult{
+			ProtocolVersion: ProtocolVersion,
+			Capabilities:    ServerCapabilities{Tools: &ToolsCapability{ListChanged: true}},
+			ServerInfo:      Implementation{Name: "stub", Version: "1.0.0"},
+		}
+	case MethodToolsList:
+		var params ListToolsParams
+		_ = sonic.Unmarshal(msg.Params, &params)
+		s.mu.Lock()
+		// two tools a page to test the pagination
+		start := 0
+		if params.Cursor != "" {
+			_, _ = fmt.Sscanf(params.Cursor, "%d", &start)
+		}
+		end := start + 2
+		ret := &ListToolsResult{}
+		if end < len(s.tools) {
+			ret.NextCursor = fmt.Sprint(end)
+		} else {
+			end = len(s.tools)
+		}
+		ret.Tools = s.tools[start:end]
+		s.mu.Unlock()
+		result = ret
+	case MethodToolsCall:
+		var params CallToolParams
+		_ = sonic.Unmarshal(msg.Params, &params)
+		switch params.Name {
+		case "echo":
+			var args struct {
+				Text string `json:"text"`
+			}
+			_ = sonic.Unmarshal(params.Arguments, &args)
+			result = &CallToolResult{Content: []*Content{{Type: ContentTypeText, Text: args.Text}}}
+		case "image":
+			result = &CallToolResult{Content: []*Content{
+				{Type: ContentTypeText, Text: "an image"},
+				{Type: ContentTypeImage, Data: "aGVsbG8=", MimeType: "image/png"},
+			}}
+		case "fail":
+			result = &CallToolResult{Content: []*Content{{Type: ContentTypeText, Text: "boom"}}, IsError: true}
+		case "add_tool":
+			s.mu.Lock()
+			s.tools = append(s.tools, &Tool{Name: "new_tool", InputSchema: []byte(`{"type":"object"}`)})
+			s.mu.Unlock()
+			notes = append(notes, &Message{JSONRPC: jsonrpcVersion, Method: MethodToolsListChanged})
+			result = &CallToolResult{Content: []*Content{{Type: ContentTypeText, Text: "added"}}}
+		default:
+			return nil, &Message{JSONRPC: jsonrpcVersion, ID: msg.ID, Error: &Error{Code: CodeInvalidParams, Message: "unknown tool"}}
+		}
+	default:
+		return nil, &Message{JSONRPC: jsonrpcVersion, ID: msg.ID, Error: &Error{Code: CodeMethodNotFound, Message: "method not found"}}
+	}
+
+	raw, _ := sonic.Marshal(result)
+	return notes, &Message{JSONRPC: jsonrpcVersion, ID: msg.ID, Result: raw}
+}
+
+func serveStubStdio() {
+	s := newStubServer()
+	r := bufio.NewReader(os.Stdin)
+	write := func(msg *Message) {
+		data, _ := sonic.Marshal(msg)
+		_, _ = os.Stdout.Write(append(data, '\n'))
+	}
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		msg := &Message{}
+		if sonic.Unmarshal(line, msg) != nil {
+			continue
+		}
+		var params CallToolParams
+		if msg.Method == MethodToolsCall && sonic.Unmarshal(msg.Params, &params) == nil && params.Name == "crash" {
+			os.Exit(1)
+		}
+		notes, resp := s.handle(msg)
+		for _, n := range notes {
+			write(n)
+		}
+		if resp != nil {
+			write(resp)
+		}
+	}
+}
+
+func assertStubTools(ctx context.Context, t *testing.T, cli *Client, n int) map[string]tool.RichInvokableTool {
+	tools, err := cli.Tools(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, tools, n)
+
+	ret := make(map[string]tool.RichInvokableTool)
+	for _, tl := range tools {
+		info, err := tl.Info(ctx)
+		assert.NoError(t, err)
+		ret[info.Name] = tl.(tool.RichInvokableTool)
+	}
+	return ret
+}
+
+func TestStdioClient(t *testing.T) {
+	ctx := context.Background()
+
+	var changed int32
+	cli, err := NewClient(ctx, &ClientConfig{
+		Command:        os.Args[0],
+		Env:            append(os.Environ(), stubEnv+"=stdio"),
+		OnToolsChanged: func() { atomic.AddInt32(&changed, 1) },
+	})
+	assert.NoError(t, err)
+	defer cli.Close()
+	assert.Equal(t, "stub", cli.ServerInfo().ServerInfo.Name)
+
+	tools := assertStubTools(ctx, t, cli, 5)
+
+	t.Run("schema", func(t *testing.T) {
+		info, err := tools["echo"].Info(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "echo the text", info.Desc)
+		js, err := info.ParamsOneOf.ToJSONSchema()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"text"}, js.Required)
+		_, ok := js.Properties.Get("text")
+		assert.True(t, ok)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		out, err := tools["echo"].(tool.InvokableTool).InvokableRun(ctx, `{"text":"hello"}`)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", out)
+	})
+
+	t.Run("image", func(t *testing.T) {
+		result, err := tools["image"].RichInvokableRun(ctx, `{}`)
+		assert.NoError(t, err)
+		assert.Equal(t, "an image", result.Content)
+		assert.Len(t, result.Parts, 1)
+		assert.Equal(t, "aGVsbG8=", *result.Parts[0].Image.Base64Data)
+		assert.Equal(t, "image/png", result.Parts[0].Image.MIMEType)
+	})
+
+	t.Run("tool error", func(t *testing.T) {
+		result, err := tools["fail"].RichInvokableRun(ctx, `{}`)
+		assert.NoError(t, err)
+		assert.Equal(t, "boom", result.Content)
+		assert.True(t, result.Artifact.(*CallToolResult).IsError)
+	})
+
+	t.Run("list changed", func(t *testing.T) {
+		_, err := tools["add_tool"].RichInvokableRun(ctx, `{}`)
+		assert.NoError(t, err)
+		assert.Eventually(t, func() bool {
+			return atomic.LoadInt32(&changed) == 1
+		}, time.Second, 10*time.Millisecond)
+		assertStubTools(ctx, t, cli, 6)
+	})
+
+	t.Run("reconnect", func(t *testing.T) {
+		_, err := tools["crash"].RichInvokableRun(ctx, `{}`)
+		assert.ErrorIs(t, err, ErrConnectionClosed)
+
+		// a new server process is spawned, with the initial tools
+		out, err := tools["echo"].(tool.InvokableTool).InvokableRun(ctx, `{"text":"again"}`)
+		assert.NoError(t, err)
+		assert.Equal(t, "again", out)
+		assertStubTools(ctx, t, cli, 5)
+	})
+}
+
+type stubHTTPServer struct {
+	stub *stubServer
+
+	mu       sync.Mutex
+	nextID   int
+	sessions map[string]chan *Message
+}
+
+func (s *stubHTTPServer) expireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = make(map[string]chan *Message)
+}
+
+func (s *stubHTTPServer) session(r *http.Request) (chan *Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch, ok := s.sessions[r.Header.Get(HeaderSessionID)]
+	return ch, ok
+}
+
+func (s *stubHTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeEvent := func(msg *Message) {
+		data, _ := sonic.Marshal(msg)
+		_, _ = fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+		w.(http.Flusher).Flush()
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		notes, ok := s.session(r)
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case n := <-notes:
+				writeEvent(n)
+			}
+		}
+	case http.MethodDelete:
+		s.mu.Lock()
+		delete(s.sessions, r.Header.Get(HeaderSessionID))
+		s.mu.Unlock()
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	msg := &Message{}
+	if err := sonic.Unmarshal(body, msg); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if msg.Method == MethodInitialize {
+		s.mu.Lock()
+		s.nextID++
+		sid := fmt.Sprintf("session-%d", s.nextID)
+		s.sessions[sid] = make(chan *Message, 10)
+		s.mu.Unlock()
+		w.Header().Set(HeaderSessionID, sid)
+	} else if _, ok := s.session(r); !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if !msg.IsRequest() {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	notes, resp := s.stub.handle(msg)
+	if ch, ok := s.session(r); ok {
+		for _, n := range notes {
+			ch <- n
+		}
+	}
+
+	if msg.Method != MethodToolsCall {
+		w.Header().Set("Content-Type", "application/json")
+		data, _ := sonic.Marshal(resp)
+		_, _ = w.Write(data)
+		return
+	}
+
+	// replies the tool calls with event streams
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	writeEvent(&Message{JSONRPC: jsonrpcVersion, Method: MethodProgress, Params: []byte(`{"progressToken":1,"progress":1}`)})
+	writeEvent(resp)
+}
+
+func TestStreamableHTTPClient(t *testing.T) {
+	ctx := context.Background()
+
+	srv := &stubHTTPServer{stub: newStubServer(), sessions: make(map[string]chan *Message)}
+	hs := httptest.NewServer(srv)
+	defer hs.Close()
+
+	var changed int32
+	cli, err := NewClient(ctx, &ClientConfig{
+		URL:            hs.URL,
+		Headers:        map[string]string{"Authorization": "Bearer token"},
+		OnToolsChanged: func() { atomic.AddInt32(&changed, 1) },
+	})
+	assert.NoError(t, err)
+
+	tools := assertStubTools(ctx, t, cli, 5)
+
+	out, err := tools["echo"].(tool.InvokableTool).InvokableRun(ctx, `{"text":"hello"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", out)
+
+	// the notification is sent by the stream opened with GET
+	_, err = tools["add_tool"].RichInvokableRun(ctx, `{}`)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&changed) == 1
+	}, time.Second, 10*time.Millisecond)
+	assertStubTools(ctx, t, cli, 6)
+
+	// a new session is initialized after the session expires
+	srv.expireSessions()
+	out, err = tools["echo"].(tool.InvokableTool).InvokableRun(ctx, `{"text":"again"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "again", out)
+
+	assert.NoError(t, cli.Close())
+	assert.Eventually(t, func() bool {
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		return len(srv.sessions) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestNewClientConfig(t *testing.T) {
+	_, err := NewClient(context.Background(), &ClientConfig{})
+	assert.ErrorContains(t, err, "command or url is required")
+
+	_, err = NewClient(context.Background(), &ClientConfig{Command: "/path/not/exists"})
+	assert.Error(t, err)
+}
+
+func TestListToolsOnToolsChanged(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, transport := range []string{"stdio", "http"} {
+		t.Run(transport, func(t *testing.T) {
+			var cli *Client
+			listed := make(chan int, 1)
+			onChanged := func() {
+				// the tools are listed from the server in the callback
+				tools, err := cli.Tools(ctx)
+				assert.NoError(t, err)
+				listed <- len(tools)
+			}
+
+			config := &ClientConfig{OnToolsChanged: onChanged}
+			if transport == "stdio" {
+				config.Command = os.Args[0]
+				config.Env = append(os.Environ(), stubEnv+"=stdio")
+			} else {
+				srv := &stubHTTPServer{stub: newStubServer(), sessions: make(map[string]chan *Message)}
+				hs := httptest.NewServer(srv)
+				defer hs.Close()
+				config.URL = hs.URL
+			}
+
+			var err error
+			cli, err = NewClient(ctx, config)
+			assert.NoError(t, err)
+			defer cli.Close()
+
+			tools := assertStubTools(ctx, t, cli, 5)
+			_, err = tools["add_tool"].RichInvokableRun(ctx, `{}`)
+			assert.NoError(t, err)
+			select {
+			case n := <-listed:
+				assert.Equal(t, 6, n)
+			case <-ctx.Done():
+				t.Fatal("tools are not listed in OnToolsChanged")
+			}
+		})
+	}
+}
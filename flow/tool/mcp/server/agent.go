@@ -0,0 +1,109 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/flow/tool/mcp"
+	"github.com/cloudwego/eino/schema"
+)
+
+const maxProgressContentLen = 200
+
+var agentToolParams = schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+	"request": {
+		Desc:     "request to be processed",
+		Required: true,
+		Type:     schema.String,
+	},
+})
+
+// newAgentHandler runs the agent with the request as the user message, and returns the content of
+// the last assistant message not calling tools, sending each message output of the agent as a progress.
+func newAgentHandler(a adk.Agent) toolHandler {
+	return func(ctx context.Context, argumentsInJSON string, progress progressFunc) (*mcp.CallToolResult, error) {
+		var req struct {
+			Request string `json:"request"`
+		}
+		if err := sonic.UnmarshalString(argumentsInJSON, &req); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		iter := adk.NewRunner(ctx, adk.RunnerConfig{Agent: a}).Query(ctx, req.Request)
+		var answer string
+		for {
+			event, ok := iter.Next()
+			if !ok {
+				break
+			}
+			if event.Err != nil {
+				return nil, event.Err
+			}
+			if event.Action != nil && event.Action.Interrupted != nil {
+				return errorResult(fmt.Sprintf("agent %s is interrupted, which can't be resumed over mcp", event.AgentName)), nil
+			}
+			if event.Output == nil || event.Output.MessageOutput == nil {
+				continue
+			}
+
+			msg, err := event.Output.MessageOutput.GetMessage()
+			if err != nil {
+				return nil, err
+			}
+			if msg == nil {
+				continue
+			}
+			if progress != nil {
+				progress(describeMessage(event.AgentName, msg))
+			}
+			if msg.Role == schema.Assistant && len(msg.ToolCalls) == 0 {
+				answer = msg.Content
+			}
+		}
+
+		return textResult(answer), nil
+	}
+}
+
+func describeMessage(agentName string, msg *schema.Message) string {
+	switch {
+	case len(msg.ToolCalls) > 0:
+		names := make([]string, 0, len(msg.ToolCalls))
+		for _, tc := range msg.ToolCalls {
+			names = append(names, tc.Function.Name)
+		}
+		return fmt.Sprintf("[%s] calling tools: %s", agentName, strings.Join(names, ", "))
+	case msg.Role == schema.Tool:
+		return fmt.Sprintf("[%s] tool %s returns: %s", agentName, msg.ToolName, truncate(msg.Content))
+	default:
+		return fmt.Sprintf("[%s] %s", agentName, truncate(msg.Content))
+	}
+}
+
+func truncate(s string) string {
+	r := []rune(s)
+	if len(r) <= maxProgressContentLen {
+		return s
+	}
+	return string(r[:maxProgressContentLen]) + "..."
+}
@@ -0,0 +1,203 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/cloudwego/eino/flow/tool/mcp"
+)
+
+const maxRequestBodyBytes = 4 << 20
+
+// ServeHTTP serves the client by the streamable HTTP transport.
+// Each message is POSTed to the handler, and the tool calls are replied with event streams
+// carrying the progress notifications and the response, while the other requests are replied with JSON.
+// The server doesn't send notifications out of the requests, so GET isn't supported.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+	case http.MethodDelete:
+		if sid := r.Header.Get(mcp.HeaderSessionID); s.removeSession(sid) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		return
+	default:
+		w.Header().Set("Allow", "POST, DELETE")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodyBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	msg := &mcp.Message{}
+	if err = sonic.Unmarshal(body, msg); err != nil {
+		writeJSON(w, &mcp.Message{
+			JSONRPC: jsonrpcVersion,
+			ID:      []byte("null"),
+			Error:   &mcp.Error{Code: mcp.CodeParseError, Message: err.Error()},
+		}, http.StatusBadRequest)
+		return
+	}
+
+	if msg.Method == mcp.MethodInitialize {
+		sid, err := newSessionID()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.addSession(sid)
+		w.Header().Set(mcp.HeaderSessionID, sid)
+	} else {
+		sid := r.Header.Get(mcp.HeaderSessionID)
+		if sid == "" {
+			http.Error(w, "missing session id", http.StatusBadRequest)
+			return
+		}
+		if !s.touchSession(sid) {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	if !msg.IsRequest() {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if msg.Method != mcp.MethodToolsCall {
+		writeJSON(w, s.HandleMessage(r.Context(), msg, nil), http.StatusOK)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, s.HandleMessage(r.Context(), msg, nil), http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var mu sync.Mutex
+	writeEvent := func(m *mcp.Message) {
+		data, err := sonic.Marshal(m)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+		flusher.Flush()
+	}
+	writeEvent(s.HandleMessage(r.Context(), msg, writeEvent))
+}
+
+// addSession adds the session, removing the expired sessions,
+// and the session idle for the longest if the number of sessions exceeds the limit.
+func (s *Server) addSession(sid string) {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	now := s.now()
+	for id, active := range s.sessions {
+		if s.expired(active, now) {
+			delete(s.sessions, id)
+		}
+	}
+	if s.maxSessions > 0 {
+		for len(s.sessions) >= s.maxSessions {
+			oldest, oldestActive := "", now
+			for id, active := range s.sessions {
+				if oldest == "" || active.Before(oldestActive) {
+					oldest, oldestActive = id, active
+				}
+			}
+			delete(s.sessions, oldest)
+		}
+	}
+	s.sessions[sid] = now
+}
+
+// touchSession refreshes the last active time of the session, and returns false if the session doesn't exist or has expired.
+func (s *Server) touchSession(sid string) bool {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	active, ok := s.sessions[sid]
+	if !ok {
+		return false
+	}
+	now := s.now()
+	if s.expired(active, now) {
+		delete(s.sessions, sid)
+		return false
+	}
+	s.sessions[sid] = now
+	return true
+}
+
+// removeSession returns false if the session doesn't exist or has expired.
+func (s *Server) removeSession(sid string) bool {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	active, ok := s.sessions[sid]
+	if !ok {
+		return false
+	}
+	delete(s.sessions, sid)
+	return !s.expired(active, s.now())
+}
+
+func (s *Server) expired(active, now time.Time) bool {
+	return s.sessionIdleTimeout > 0 && now.Sub(active) > s.sessionIdleTimeout
+}
+
+func writeJSON(w http.ResponseWriter, msg *mcp.Message, status int) {
+	data, err := sonic.Marshal(msg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,348 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package server serves eino tools and agents over the Model Context Protocol (MCP),
+// by the stdio transport and the streamable HTTP transport.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/flow/tool/mcp"
+	"github.com/cloudwego/eino/schema"
+)
+
+const jsonrpcVersion = "2.0"
+
+// Config is the config for Server.
+type Config struct {
+	// Name is the name of the server reported to the clients, default "eino".
+	Name string
+	// Version is the version of the server reported to the clients, default "1.0.0".
+	Version string
+	// Instructions tells the clients how to use the server, optional.
+	Instructions string
+
+	// Tools are the tools served.
+	// A tool.StreamableTool sends each chunk as a progress notification when the client asks for the progress.
+	Tools []tool.BaseTool
+	// Agents are served as the tools named after the agents, each of which takes the request of the user,
+	// and sends the events of the agent as the progress notifications when the client asks for the progress.
+	Agents []adk.Agent
+
+	// SessionIdleTimeout is how long a session of the streamable HTTP transport is kept without requests, default 30 minutes.
+	// The requests of an expired session are replied with 404, so that the client initializes a new session.
+	// Negative means never expiring.
+	SessionIdleTimeout time.Duration
+	// MaxSessions is the max number of sessions of the streamable HTTP transport, default 10000.
+	// When a new session exceeds it, the session idle for the longest is removed. Negative means unlimited.
+	MaxSessions int
+}
+
+const (
+	defaultSessionIdleTimeout = 30 * time.Minute
+	defaultMaxSessions        = 10000
+)
+
+// progressFunc sends the progress notification with the message.
+type progressFunc func(message string)
+
+type toolHandler func(ctx context.Context, argumentsInJSON string, progress progressFunc) (*mcp.CallToolResult, error)
+
+// Server serves the tools over MCP.
+// Use ServeStdio to serve a client spawning the process, or use the Server as an http.Handler
+// to serve the clients by the streamable HTTP transport.
+// e.g.
+//
+//	srv, err := server.NewServer(ctx, &server.Config{Tools: tools, Agents: []adk.Agent{agent}})
+//	err = http.ListenAndServe(":8080", srv)
+type Server struct {
+	info         mcp.Implementation
+	instructions string
+
+	tools    []*mcp.Tool
+	handlers map[string]toolHandler
+
+	sessionIdleTimeout time.Duration
+	maxSessions        int
+	now                func() time.Time
+
+	sessionsMu sync.Mutex
+	sessions   map[string]time.Time // the last active time by session id
+}
+
+// NewServer creates a Server, deriving the input schemas of the tools by ToolInfo.ParamsOneOf.ToJSONSchema.
+func NewServer(ctx context.Context, config *Config) (*Server, error) {
+	s := &Server{
+		info:         mcp.Implementation{Name: config.Name, Version: config.Version},
+		instructions: config.Instructions,
+		handlers:     make(map[string]toolHandler),
+
+		sessionIdleTimeout: config.SessionIdleTimeout,
+		maxSessions:        config.MaxSessions,
+		now:                time.Now,
+		sessions:           make(map[string]time.Time),
+	}
+	if s.info.Name == "" {
+		s.info.Name = "eino"
+	}
+	if s.info.Version == "" {
+		s.info.Version = "1.0.0"
+	}
+	if s.sessionIdleTimeout == 0 {
+		s.sessionIdleTimeout = defaultSessionIdleTimeout
+	}
+	if s.maxSessions == 0 {
+		s.maxSessions = defaultMaxSessions
+	}
+
+	for i, t := range config.Tools {
+		info, err := t.Info(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tool info at idx=%d: %w", i, err)
+		}
+		h, err := newToolHandler(t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serve tool %s: %w", info.Name, err)
+		}
+		if err = s.addTool(info, h); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, a := range config.Agents {
+		info := &schema.ToolInfo{
+			Name:        a.Name(ctx),
+			Desc:        a.Description(ctx),
+			ParamsOneOf: agentToolParams,
+		}
+		if err := s.addTool(info, newAgentHandler(a)); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (s *Server) addTool(info *schema.ToolInfo, h toolHandler) error {
+	if _, ok := s.handlers[info.Name]; ok {
+		return fmt.Errorf("duplicate tool name: %s", info.Name)
+	}
+
+	inputSchema := []byte(`{"type":"object"}`)
+	if info.ParamsOneOf != nil {
+		js, err := info.ParamsOneOf.ToJSONSchema()
+		if err != nil {
+			return fmt.Errorf("failed to convert params of tool %s to json schema: %w", info.Name, err)
+		}
+		if js != nil {
+			if js.Type == "" {
+				js.Type = "object"
+			}
+			inputSchema, err = sonic.Marshal(js)
+			if err != nil {
+				return fmt.Errorf("failed to marshal json schema of tool %s: %w", info.Name, err)
+			}
+		}
+	}
+
+	s.tools = append(s.tools, &mcp.Tool{
+		Name:        info.Name,
+		Description: info.Desc,
+		InputSchema: inputSchema,
+	})
+	s.handlers[info.Name] = h
+	return nil
+}
+
+// HandleMessage handles the message from the client, and returns the response, which is nil for the notifications.
+// The notifications to the client during the handling, e.g. the progress, are sent by notify.
+// It's used to serve by the transports other than stdio and streamable HTTP.
+func (s *Server) HandleMessage(ctx context.Context, msg *mcp.Message, notify func(*mcp.Message)) *mcp.Message {
+	if !msg.IsRequest() {
+		return nil
+	}
+
+	var result any
+	var err error
+	switch msg.Method {
+	case mcp.MethodInitialize:
+		result = &mcp.InitializeResult{
+			ProtocolVersion: mcp.ProtocolVersion,
+			Capabilities:    mcp.ServerCapabilities{Tools: &mcp.ToolsCapability{}},
+			ServerInfo:      s.info,
+			Instructions:    s.instructions,
+		}
+	case mcp.MethodPing:
+		result = struct{}{}
+	case mcp.MethodToolsList:
+		result = &mcp.ListToolsResult{Tools: s.tools}
+	case mcp.MethodToolsCall:
+		result, err = s.callTool(ctx, msg.Params, notify)
+	default:
+		err = &mcp.Error{Code: mcp.CodeMethodNotFound, Message: "method not found: " + msg.Method}
+	}
+
+	resp := &mcp.Message{JSONRPC: jsonrpcVersion, ID: msg.ID}
+	if err == nil {
+		resp.Result, err = sonic.Marshal(result)
+	}
+	if err != nil {
+		var rpcErr *mcp.Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &mcp.Error{Code: mcp.CodeInternalError, Message: err.Error()}
+		}
+		resp.Result = nil
+		resp.Error = rpcErr
+	}
+	return resp
+}
+
+func (s *Server) callTool(ctx context.Context, rawParams []byte, notify func(*mcp.Message)) (*mcp.CallToolResult, error) {
+	params := &mcp.CallToolParams{}
+	if err := sonic.Unmarshal(rawParams, params); err != nil {
+		return nil, &mcp.Error{Code: mcp.CodeInvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+	}
+	h, ok := s.handlers[params.Name]
+	if !ok {
+		return nil, &mcp.Error{Code: mcp.CodeInvalidParams, Message: "unknown tool: " + params.Name}
+	}
+
+	var progress progressFunc
+	if params.Meta != nil && params.Meta.ProgressToken != nil && notify != nil {
+		token := params.Meta.ProgressToken
+		var count float64
+		progress = func(message string) {
+			count++
+			raw, err := sonic.Marshal(&mcp.ProgressParams{ProgressToken: token, Progress: count, Message: message})
+			if err != nil {
+				return
+			}
+			notify(&mcp.Message{JSONRPC: jsonrpcVersion, Method: mcp.MethodProgress, Params: raw})
+		}
+	}
+
+	args := "{}"
+	if len(params.Arguments) > 0 && string(params.Arguments) != "null" {
+		args = string(params.Arguments)
+	}
+	result, err := h(ctx, args, progress)
+	if err != nil {
+		// the failures of the tools are reported to the model rather than the protocol errors
+		return errorResult(err.Error()), nil
+	}
+	return result, nil
+}
+
+func newToolHandler(t tool.BaseTool) (toolHandler, error) {
+	rt, _ := t.(tool.RichInvokableTool)
+	it, _ := t.(tool.InvokableTool)
+	st, _ := t.(tool.StreamableTool)
+
+	if rt == nil && it == nil && st == nil {
+		return nil, errors.New("tool is neither invokable nor streamable")
+	}
+
+	return func(ctx context.Context, argumentsInJSON string, progress progressFunc) (*mcp.CallToolResult, error) {
+		// stream the output when the client asks for the progress
+		if st != nil && (progress != nil || rt == nil && it == nil) {
+			return runStreamableTool(ctx, st, argumentsInJSON, progress)
+		}
+		if rt != nil {
+			result, err := rt.RichInvokableRun(ctx, argumentsInJSON)
+			if err != nil {
+				return nil, err
+			}
+			return convertResult(result), nil
+		}
+		out, err := it.InvokableRun(ctx, argumentsInJSON)
+		if err != nil {
+			return nil, err
+		}
+		return textResult(out), nil
+	}, nil
+}
+
+func runStreamableTool(ctx context.Context, st tool.StreamableTool, argumentsInJSON string, progress progressFunc) (*mcp.CallToolResult, error) {
+	sr, err := st.StreamableRun(ctx, argumentsInJSON)
+	if err != nil {
+		return nil, err
+	}
+	defer sr.Close()
+
+	sb := strings.Builder{}
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		sb.WriteString(chunk)
+		if progress != nil {
+			progress(chunk)
+		}
+	}
+	return textResult(sb.String()), nil
+}
+
+func convertResult(result *tool.Result) *mcp.CallToolResult {
+	ret := &mcp.CallToolResult{}
+	if result.Content != "" || len(result.Parts) == 0 {
+		ret.Content = append(ret.Content, &mcp.Content{Type: mcp.ContentTypeText, Text: result.Content})
+	}
+	for _, p := range result.Parts {
+		switch {
+		case p.Type == schema.ChatMessagePartTypeText:
+			ret.Content = append(ret.Content, &mcp.Content{Type: mcp.ContentTypeText, Text: p.Text})
+		case p.Image != nil:
+			ret.Content = append(ret.Content, mediaContent(mcp.ContentTypeImage, &p.Image.MessagePartCommon))
+		case p.Audio != nil:
+			ret.Content = append(ret.Content, mediaContent(mcp.ContentTypeAudio, &p.Audio.MessagePartCommon))
+		}
+	}
+	return ret
+}
+
+// mediaContent converts the image or the audio part, which is referred by the url if no data is inlined.
+func mediaContent(typ string, p *schema.MessagePartCommon) *mcp.Content {
+	if p.Base64Data != nil {
+		return &mcp.Content{Type: typ, Data: *p.Base64Data, MimeType: p.MIMEType}
+	}
+	if p.URL != nil {
+		return &mcp.Content{Type: mcp.ContentTypeText, Text: fmt.Sprintf("[%s %s]", typ, *p.URL)}
+	}
+	return &mcp.Content{Type: mcp.ContentTypeText, Text: fmt.Sprintf("[%s]", typ)}
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{Content: []*mcp.Content{{Type: mcp.ContentTypeText, Text: text}}}
+}
+
+func errorResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{Content: []*mcp.Content{{Type: mcp.ContentTypeText, Text: text}}, IsError: true}
+}
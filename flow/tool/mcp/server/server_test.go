@@ -0,0 +1,365 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/flow/tool/mcp"
+	"github.com/cloudwego/eino/schema"
+)
+
+type echoTool struct{}
+
+func (t *echoTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "echo",
+		Desc: "echo the text",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"text": {Type: schema.String, Required: true},
+		}),
+	}, nil
+}
+
+func (t *echoTool) InvokableRun(_ context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	var args struct {
+		Text string `json:"text"`
+	}
+	if err := sonic.UnmarshalString(argumentsInJSON, &args); err != nil {
+		return "", err
+	}
+	if args.Text == "" {
+		return "", errors.New("text is empty")
+	}
+	return args.Text, nil
+}
+
+type chartTool struct{}
+
+func (t *chartTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: "chart", Desc: "draw a chart"}, nil
+}
+
+func (t *chartTool) RichInvokableRun(_ context.Context, _ string, _ ...tool.Option) (*tool.Result, error) {
+	data := "aGVsbG8="
+	return &tool.Result{
+		Content: "a chart",
+		Parts: []schema.MessageInputPart{{
+			Type:  schema.ChatMessagePartTypeImageURL,
+			Image: &schema.MessageInputImage{MessagePartCommon: schema.MessagePartCommon{Base64Data: &data, MIMEType: "image/png"}},
+		}},
+	}, nil
+}
+
+type countTool struct{}
+
+func (t *countTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: "count", Desc: "count to three"}, nil
+}
+
+func (t *countTool) StreamableRun(_ context.Context, _ string, _ ...tool.Option) (*schema.StreamReader[string], error) {
+	return schema.StreamReaderFromArray([]string{"1", "2", "3"}), nil
+}
+
+type fakeAgent struct{}
+
+func (a *fakeAgent) Name(_ context.Context) string {
+	return "weather_agent"
+}
+
+func (a *fakeAgent) Description(_ context.Context) string {
+	return "answers the questions about the weather"
+}
+
+func (a *fakeAgent) Run(_ context.Context, input *adk.AgentInput, _ ...adk.AgentRunOption) *adk.AsyncIterator[*adk.AgentEvent] {
+	iter, gen := adk.NewAsyncIteratorPair[*adk.AgentEvent]()
+	go func() {
+		defer gen.Close()
+		gen.Send(adk.EventFromMessage(schema.AssistantMessage("", []schema.ToolCall{
+			{ID: "1", Function: schema.FunctionCall{Name: "get_weather", Arguments: `{}`}},
+		}), nil, schema.Assistant, ""))
+		gen.Send(adk.EventFromMessage(schema.ToolMessage("sunny", "1", schema.WithToolName("get_weather")), nil, schema.Tool, "get_weather"))
+		gen.Send(adk.EventFromMessage(schema.AssistantMessage("it's sunny for "+input.Messages[0].Content, nil), nil, schema.Assistant, ""))
+	}()
+	return iter
+}
+
+func newTestServer(t *testing.T) *Server {
+	srv, err := NewServer(context.Background(), &Config{
+		Name:   "test",
+		Tools:  []tool.BaseTool{&echoTool{}, &chartTool{}, &countTool{}},
+		Agents: []adk.Agent{&fakeAgent{}},
+	})
+	assert.NoError(t, err)
+	return srv
+}
+
+func TestServeHTTP(t *testing.T) {
+	ctx := context.Background()
+	hs := httptest.NewServer(newTestServer(t))
+	defer hs.Close()
+
+	cli, err := mcp.NewClient(ctx, &mcp.ClientConfig{URL: hs.URL})
+	assert.NoError(t, err)
+	defer cli.Close()
+	assert.Equal(t, "test", cli.ServerInfo().ServerInfo.Name)
+
+	tools, err := cli.Tools(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, tools, 4)
+	byName := make(map[string]tool.RichInvokableTool)
+	for _, tl := range tools {
+		info, err := tl.Info(ctx)
+		assert.NoError(t, err)
+		byName[info.Name] = tl.(tool.RichInvokableTool)
+	}
+
+	info, err := byName["echo"].Info(ctx)
+	assert.NoError(t, err)
+	js, err := info.ParamsOneOf.ToJSONSchema()
+	assert.NoError(t, err)
+	assert.Equal(t, "object", js.Type)
+	assert.Equal(t, []string{"text"}, js.Required)
+
+	result, err := byName["echo"].RichInvokableRun(ctx, `{"text":"hello"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", result.Content)
+
+	result, err = byName["echo"].RichInvokableRun(ctx, `{}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "text is empty", result.Content)
+	assert.True(t, result.Artifact.(*mcp.CallToolResult).IsError)
+
+	result, err = byName["chart"].RichInvokableRun(ctx, `{}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "a chart", result.Content)
+	assert.Len(t, result.Parts, 1)
+	assert.Equal(t, "aGVsbG8=", *result.Parts[0].Image.Base64Data)
+
+	result, err = byName["count"].RichInvokableRun(ctx, `{}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "123", result.Content)
+
+	result, err = byName["weather_agent"].RichInvokableRun(ctx, `{"request":"Paris"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "it's sunny for Paris", result.Content)
+
+	_, err = cli.CallTool(ctx, "unknown", `{}`)
+	var rpcErr *mcp.Error
+	assert.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, mcp.CodeInvalidParams, rpcErr.Code)
+}
+
+func TestServeHTTPProgress(t *testing.T) {
+	hs := httptest.NewServer(newTestServer(t))
+	defer hs.Close()
+
+	post := func(sid, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, hs.URL, strings.NewReader(body))
+		assert.NoError(t, err)
+		if sid != "" {
+			req.Header.Set(mcp.HeaderSessionID, sid)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	resp := post("", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	_ = resp.Body.Close()
+	resp = post("unknown", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	resp = post("", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	sid := resp.Header.Get(mcp.HeaderSessionID)
+	assert.NotEmpty(t, sid)
+	_ = resp.Body.Close()
+
+	resp = post(sid, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	readEvents := func(resp *http.Response) []*mcp.Message {
+		defer resp.Body.Close()
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		var msgs []*mcp.Message
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if data := strings.TrimPrefix(scanner.Text(), "data: "); data != scanner.Text() {
+				msg := &mcp.Message{}
+				assert.NoError(t, sonic.UnmarshalString(data, msg))
+				msgs = append(msgs, msg)
+			}
+		}
+		return msgs
+	}
+	progressMessages := func(msgs []*mcp.Message) []string {
+		var ret []string
+		for _, m := range msgs {
+			if m.Method == mcp.MethodProgress {
+				p := &mcp.ProgressParams{}
+				assert.NoError(t, sonic.Unmarshal(m.Params, p))
+				assert.Equal(t, "tk", p.ProgressToken)
+				ret = append(ret, p.Message)
+			}
+		}
+		return ret
+	}
+
+	msgs := readEvents(post(sid, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"count","_meta":{"progressToken":"tk"}}}`))
+	assert.Len(t, msgs, 4)
+	assert.Equal(t, []string{"1", "2", "3"}, progressMessages(msgs))
+	result := &mcp.CallToolResult{}
+	assert.NoError(t, sonic.Unmarshal(msgs[3].Result, result))
+	assert.Equal(t, "123", result.Content[0].Text)
+
+	msgs = readEvents(post(sid, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"weather_agent","arguments":{"request":"Paris"},"_meta":{"progressToken":"tk"}}}`))
+	assert.Equal(t, []string{
+		"[weather_agent] calling tools: get_weather",
+		"[weather_agent] tool get_weather returns: sunny",
+		"[weather_agent] it's sunny for Paris",
+	}, progressMessages(msgs))
+
+	req, _ := http.NewRequest(http.MethodGet, hs.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	req, _ = http.NewRequest(http.MethodDelete, hs.URL, nil)
+	req.Header.Set(mcp.HeaderSessionID, sid)
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	_ = resp.Body.Close()
+	resp = post(sid, `{"jsonrpc":"2.0","id":4,"method":"tools/list"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	_ = resp.Body.Close()
+}
+
+func TestServeHTTPSessionEviction(t *testing.T) {
+	srv, err := NewServer(context.Background(), &Config{SessionIdleTimeout: time.Minute, MaxSessions: 2})
+	assert.NoError(t, err)
+	now := time.Now()
+	srv.now = func() time.Time { return now }
+	hs := httptest.NewServer(srv)
+	defer hs.Close()
+
+	post := func(sid, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, hs.URL, strings.NewReader(body))
+		assert.NoError(t, err)
+		if sid != "" {
+			req.Header.Set(mcp.HeaderSessionID, sid)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp
+	}
+	initialize := func() string {
+		return post("", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`).Header.Get(mcp.HeaderSessionID)
+	}
+	list := func(sid string) int {
+		return post(sid, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`).StatusCode
+	}
+
+	first := initialize()
+	now = now.Add(time.Second)
+	second := initialize()
+	now = now.Add(time.Second)
+	assert.Equal(t, http.StatusOK, list(first))
+
+	// the session idle for the longest is removed when exceeding MaxSessions
+	now = now.Add(time.Second)
+	third := initialize()
+	assert.Len(t, srv.sessions, 2)
+	assert.Equal(t, http.StatusNotFound, list(second))
+	assert.Equal(t, http.StatusOK, list(first))
+	assert.Equal(t, http.StatusOK, list(third))
+
+	// the idle sessions expire
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, http.StatusNotFound, list(first))
+	initialize()
+	assert.Len(t, srv.sessions, 1)
+}
+
+func TestServeStdio(t *testing.T) {
+	srv := newTestServer(t)
+
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`not json`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"count","_meta":{"progressToken":1}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"unknown"}`,
+	}, "\n")
+	out := &bytes.Buffer{}
+	assert.NoError(t, srv.ServeStdio(context.Background(), strings.NewReader(in), out))
+
+	byID := make(map[string]*mcp.Message)
+	var progress int
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		msg := &mcp.Message{}
+		assert.NoError(t, sonic.UnmarshalString(line, msg))
+		if msg.Method == mcp.MethodProgress {
+			progress++
+			continue
+		}
+		byID[string(msg.ID)] = msg
+	}
+	assert.Equal(t, 3, progress)
+	assert.Len(t, byID, 4)
+
+	info := &mcp.InitializeResult{}
+	assert.NoError(t, sonic.Unmarshal(byID["1"].Result, info))
+	assert.Equal(t, "test", info.ServerInfo.Name)
+	assert.Equal(t, mcp.CodeParseError, byID["null"].Error.Code)
+	assert.Equal(t, mcp.CodeMethodNotFound, byID["3"].Error.Code)
+	result := &mcp.CallToolResult{}
+	assert.NoError(t, sonic.Unmarshal(byID["2"].Result, result))
+	assert.Equal(t, "123", result.Content[0].Text)
+
+	_, err := NewServer(context.Background(), &Config{Tools: []tool.BaseTool{&echoTool{}, &echoTool{}}})
+	assert.ErrorContains(t, err, "duplicate tool name")
+
+	r, w := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- srv.ServeStdio(ctx, r, io.Discard)
+	}()
+	cancel()
+	_ = w.Close()
+	<-done
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/cloudwego/eino/flow/tool/mcp"
+)
+
+// ServeStdio serves the client by newline delimited messages, read from r and written to w,
+// usually the stdin and the stdout of the process, until r is closed or ctx is done.
+// The requests are handled concurrently.
+func (s *Server) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	var mu sync.Mutex
+	write := func(msg *mcp.Message) {
+		data, err := sonic.Marshal(msg)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = w.Write(append(data, '\n'))
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	reader := bufio.NewReader(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			msg := &mcp.Message{}
+			if e := sonic.Unmarshal(line, msg); e != nil {
+				write(&mcp.Message{
+					JSONRPC: jsonrpcVersion,
+					ID:      []byte("null"),
+					Error:   &mcp.Error{Code: mcp.CodeParseError, Message: e.Error()},
+				})
+			} else if msg.IsRequest() {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if resp := s.HandleMessage(ctx, msg, write); resp != nil {
+						write(resp)
+					}
+				}()
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/eino-contrib/jsonschema"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// mcpTool calls the tool of the server, implementing tool.RichInvokableTool to deliver the images and the audios.
+type mcpTool struct {
+	cli  *Client
+	info *schema.ToolInfo
+}
+
+func newMCPTool(cli *Client, t *Tool) (*mcpTool, error) {
+	info := &schema.ToolInfo{
+		Name: t.Name,
+		Desc: t.Description,
+	}
+	if len(t.InputSchema) > 0 && string(t.InputSchema) != "null" {
+		js := &jsonschema.Schema{}
+		if err := sonic.Unmarshal(t.InputSchema, js); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal input schema of mcp tool %s: %w", t.Name, err)
+		}
+		info.ParamsOneOf = schema.NewParamsOneOfByJSONSchema(js)
+	}
+	return &mcpTool{cli: cli, info: info}, nil
+}
+
+func (t *mcpTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return t.info, nil
+}
+
+func (t *mcpTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	result, err := t.RichInvokableRun(ctx, argumentsInJSON, opts...)
+	if err != nil {
+		return "", err
+	}
+	return result.Content, nil
+}
+
+// RichInvokableRun calls the tool of the server, mapping the text contents to Content,
+// the image and the audio contents to Parts, and keeping the raw CallToolResult as Artifact.
+func (t *mcpTool) RichInvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (*tool.Result, error) {
+	result, err := t.cli.CallTool(ctx, t.info.Name, argumentsInJSON)
+	if err != nil {
+		return nil, err
+	}
+	return convertResult(result), nil
+}
+
+func convertResult(result *CallToolResult) *tool.Result {
+	var texts []string
+	var parts []schema.MessageInputPart
+	for _, c := range result.Content {
+		if c == nil {
+			continue
+		}
+		switch c.Type {
+		case ContentTypeText:
+			texts = append(texts, c.Text)
+		case ContentTypeImage:
+			data := c.Data
+			parts = append(parts, schema.MessageInputPart{
+				Type: schema.ChatMessagePartTypeImageURL,
+				Image: &schema.MessageInputImage{
+					MessagePartCommon: schema.MessagePartCommon{Base64Data: &data, MIMEType: c.MimeType},
+				},
+			})
+		case ContentTypeAudio:
+			data := c.Data
+			parts = append(parts, schema.MessageInputPart{
+				Type: schema.ChatMessagePartTypeAudioURL,
+				Audio: &schema.MessageInputAudio{
+					MessagePartCommon: schema.MessagePartCommon{Base64Data: &data, MIMEType: c.MimeType},
+				},
+			})
+		case ContentTypeResource:
+			if c.Resource == nil {
+				continue
+			}
+			if c.Resource.Text != "" {
+				texts = append(texts, c.Resource.Text)
+			} else {
+				texts = append(texts, fmt.Sprintf("[resource %s]", c.Resource.URI))
+			}
+		}
+	}
+
+	content := strings.Join(texts, "\n")
+	if result.IsError && content == "" {
+		content = "tool call failed"
+	}
+	return &tool.Result{
+		Content:  content,
+		Parts:    parts,
+		Artifact: result,
+	}
+}
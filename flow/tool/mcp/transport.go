@@ -0,0 +1,325 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/sonic"
+)
+
+// ErrSessionExpired is reported to the close handler when the server no longer knows the session,
+// after which the client reconnects with a new session.
+var ErrSessionExpired = errors.New("mcp session expired")
+
+// Transport carries the messages between the client and the server.
+type Transport interface {
+	// Start starts delivering the messages received to handler, one by one in the order received.
+	// onClose is called once the connection is lost, with the cause.
+	Start(ctx context.Context, handler func(*Message), onClose func(error)) error
+	// Send sends the message to the server.
+	Send(ctx context.Context, msg *Message) error
+	// Close closes the connection.
+	Close() error
+}
+
+// NewStdioTransport creates the Transport communicating with the local server process spawned by the command,
+// with newline delimited messages over the stdin and the stdout of the process.
+func NewStdioTransport(cmd *exec.Cmd) Transport {
+	return &stdioTransport{cmd: cmd}
+}
+
+type stdioTransport struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	mu sync.Mutex
+}
+
+func (t *stdioTransport) Start(_ context.Context, handler func(*Message), onClose func(error)) error {
+	stdin, err := t.cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := t.cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = t.cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start mcp server process: %w", err)
+	}
+	t.stdin = stdin
+
+	go func() {
+		r := bufio.NewReader(stdout)
+		var readErr error
+		for {
+			line, err := r.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				msg := &Message{}
+				if e := sonic.Unmarshal(line, msg); e == nil {
+					handler(msg)
+				}
+			}
+			if err != nil {
+				readErr = err
+				break
+			}
+		}
+		waitErr := t.cmd.Wait()
+		if waitErr != nil {
+			readErr = fmt.Errorf("mcp server process exits: %w", waitErr)
+		}
+		onClose(readErr)
+	}()
+	return nil
+}
+
+func (t *stdioTransport) Send(_ context.Context, msg *Message) error {
+	data, err := sonic.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, err = t.stdin.Write(append(data, '\n'))
+	return err
+}
+
+func (t *stdioTransport) Close() error {
+	if t.stdin != nil {
+		_ = t.stdin.Close()
+	}
+	if t.cmd.Process != nil {
+		_ = t.cmd.Process.Kill()
+	}
+	return nil
+}
+
+// NewStreamableHTTPTransport creates the Transport communicating with the server at the endpoint
+// by the streamable HTTP transport, in which each message is POSTed to the endpoint,
+// and the server replies with either a JSON response or an event stream.
+func NewStreamableHTTPTransport(endpoint string, client *http.Client, headers map[string]string) Transport {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpTransport{
+		endpoint: endpoint,
+		client:   client,
+		headers:  headers,
+		done:     make(chan struct{}),
+	}
+}
+
+type httpTransport struct {
+	endpoint string
+	client   *http.Client
+	headers  map[string]string
+
+	handler func(*Message)
+	onClose func(error)
+
+	mu        sync.Mutex
+	sessionID string
+	closeOnce sync.Once
+	done      chan struct{}
+	// handlerMu keeps the messages from the concurrent streams delivered one by one.
+	handlerMu sync.Mutex
+}
+
+func (t *httpTransport) Start(_ context.Context, handler func(*Message), onClose func(error)) error {
+	t.handler = handler
+	t.onClose = onClose
+	return nil
+}
+
+func (t *httpTransport) Send(ctx context.Context, msg *Message) error {
+	data, err := sonic.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	t.setHeaders(req)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	if sid := resp.Header.Get(HeaderSessionID); sid != "" {
+		t.mu.Lock()
+		t.sessionID = sid
+		t.mu.Unlock()
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && t.getSessionID() != "":
+		_ = resp.Body.Close()
+		t.close(ErrSessionExpired)
+		return ErrSessionExpired
+	case resp.StatusCode == http.StatusAccepted:
+		_ = resp.Body.Close()
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		_ = resp.Body.Close()
+		return fmt.Errorf("mcp server responds with status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	case strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream"):
+		go t.readEventStream(resp.Body)
+	default:
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(body)) > 0 {
+			respMsg := &Message{}
+			if err = sonic.Unmarshal(body, respMsg); err != nil {
+				return fmt.Errorf("failed to unmarshal mcp response: %w", err)
+			}
+			t.deliver(respMsg)
+		}
+	}
+
+	// the server may send the notifications, e.g. the tool list changes, by the stream opened with GET,
+	// which is opened once the session is initialized
+	if msg.Method == MethodInitialized {
+		go t.listen()
+	}
+	return nil
+}
+
+func (t *httpTransport) listen() {
+	req, err := http.NewRequest(http.MethodGet, t.endpoint, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	t.setHeaders(req)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-t.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	resp, err := t.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return
+	}
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		// the server doesn't support the stream
+		_ = resp.Body.Close()
+		return
+	}
+	t.readEventStream(resp.Body)
+}
+
+func (t *httpTransport) readEventStream(body io.ReadCloser) {
+	defer body.Close()
+
+	r := bufio.NewReader(body)
+	var data []string
+	for {
+		line, err := r.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				msg := &Message{}
+				if e := sonic.UnmarshalString(strings.Join(data, "\n"), msg); e == nil {
+					t.deliver(msg)
+				}
+				data = data[:0]
+			}
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (t *httpTransport) deliver(msg *Message) {
+	t.handlerMu.Lock()
+	defer t.handlerMu.Unlock()
+	t.handler(msg)
+}
+
+func (t *httpTransport) setHeaders(req *http.Request) {
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	if sid := t.getSessionID(); sid != "" {
+		req.Header.Set(HeaderSessionID, sid)
+	}
+}
+
+func (t *httpTransport) getSessionID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sessionID
+}
+
+func (t *httpTransport) close(err error) {
+	t.closeOnce.Do(func() {
+		close(t.done)
+		if t.onClose != nil {
+			t.onClose(err)
+		}
+	})
+}
+
+func (t *httpTransport) Close() error {
+	sid := t.getSessionID()
+	t.close(errors.New("mcp transport closed"))
+	if sid == "" {
+		return nil
+	}
+
+	// terminate the session as the protocol suggests, ignoring the failures
+	req, err := http.NewRequest(http.MethodDelete, t.endpoint, nil)
+	if err != nil {
+		return nil
+	}
+	t.setHeaders(req)
+	resp, err := t.client.Do(req)
+	if err == nil {
+		_ = resp.Body.Close()
+	}
+	return nil
+}
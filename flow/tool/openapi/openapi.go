@@ -0,0 +1,313 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package openapi generates tools from an OpenAPI 3 document, one tool for each operation,
+// which call the REST API described by the document.
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// BodyParamName is the name of the tool parameter carrying the request body of the operation.
+const BodyParamName = "body"
+
+const maxToolNameLen = 64
+
+// AuthInjector injects the credentials into the request before it's sent, e.g. setting the Authorization header.
+type AuthInjector func(ctx context.Context, req *http.Request) error
+
+// Config is the config for generating tools from an OpenAPI 3 document.
+type Config struct {
+	// Spec is the OpenAPI 3 document in JSON or YAML.
+	// Either Spec or Doc is required.
+	Spec []byte
+	// Doc is the loaded OpenAPI 3 document, whose refs must be resolved.
+	Doc *openapi3.T
+
+	// BaseURL is the url the operation paths are appended to, optional.
+	// By default, it's the url of the first server in the document.
+	BaseURL string
+	// HTTPClient sends the requests, optional. By default, it's http.DefaultClient.
+	HTTPClient *http.Client
+	// Auth injects the credentials into each request, optional.
+	Auth AuthInjector
+	// Headers are set on each request, optional.
+	Headers map[string]string
+
+	// MaxResponseBytes is the max length of the response body returned to the model, optional.
+	// The rest of the body is dropped and TruncateMarker is appended. 0 means no limit.
+	MaxResponseBytes int
+	// TruncateMarker is appended to the truncated response body, optional.
+	// By default, it's "\n...(truncated)".
+	TruncateMarker string
+
+	// Filter decides whether to generate the tool for the operation, optional.
+	// By default, tools are generated for all the operations.
+	Filter func(method, path string, op *openapi3.Operation) bool
+}
+
+// NewTools generates one tool.InvokableTool for each operation in the OpenAPI 3 document.
+// The tool is named after the operationId, or the method and the path if the operationId is absent.
+// The path, query, header and cookie parameters of the operation are the parameters of the tool,
+// and the request body, if any, is the BodyParamName parameter.
+// Responses with the status other than 2xx are returned to the model as well, prefixed with the status.
+// e.g.
+//
+//	tools, err := openapi.NewTools(ctx, &openapi.Config{
+//		Spec: spec,
+//		Auth: func(ctx context.Context, req *http.Request) error {
+//			req.Header.Set("Authorization", "Bearer "+token)
+//			return nil
+//		},
+//		MaxResponseBytes: 16 * 1024,
+//	})
+func NewTools(ctx context.Context, config *Config) ([]tool.BaseTool, error) {
+	doc := config.Doc
+	if doc == nil {
+		if len(config.Spec) == 0 {
+			return nil, errors.New("spec is required")
+		}
+		var err error
+		loader := openapi3.NewLoader()
+		loader.Context = ctx
+		doc, err = loader.LoadFromData(config.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load openapi spec: %w", err)
+		}
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = serverURL(doc)
+	}
+	if baseURL == "" {
+		return nil, errors.New("base url is required when no server is declared in the spec")
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	client := config.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	marker := config.TruncateMarker
+	if marker == "" {
+		marker = "\n...(truncated)"
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for p := range doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var tools []tool.BaseTool
+	names := make(map[string]string)
+	for _, p := range paths {
+		item := doc.Paths[p]
+		if item == nil {
+			continue
+		}
+		ops := item.Operations()
+		methods := make([]string, 0, len(ops))
+		for m := range ops {
+			methods = append(methods, m)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			op := ops[method]
+			if config.Filter != nil && !config.Filter(method, p, op) {
+				continue
+			}
+
+			t, err := newOperationTool(method, p, item, op)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate tool for %s %s: %w", method, p, err)
+			}
+			if prev, ok := names[t.info.Name]; ok {
+				return nil, fmt.Errorf("duplicate tool name %s for %s %s and %s", t.info.Name, method, p, prev)
+			}
+			names[t.info.Name] = method + " " + p
+
+			t.baseURL = baseURL
+			t.client = client
+			t.auth = config.Auth
+			t.headers = config.Headers
+			t.maxResponseBytes = config.MaxResponseBytes
+			t.truncateMarker = marker
+			tools = append(tools, t)
+		}
+	}
+
+	return tools, nil
+}
+
+func serverURL(doc *openapi3.T) string {
+	if len(doc.Servers) == 0 || doc.Servers[0] == nil {
+		return ""
+	}
+	server := doc.Servers[0]
+	u := server.URL
+	for name, v := range server.Variables {
+		if v != nil {
+			u = strings.ReplaceAll(u, "{"+name+"}", v.Default)
+		}
+	}
+	return u
+}
+
+func newOperationTool(method, path string, item *openapi3.PathItem, op *openapi3.Operation) (*operationTool, error) {
+	t := &operationTool{
+		method: method,
+		path:   path,
+	}
+
+	// operation level parameters override the path level ones with the same name and location
+	var params []*openapi3.Parameter
+	overridden := make(map[string]bool)
+	for _, ref := range op.Parameters {
+		if ref != nil && ref.Value != nil {
+			params = append(params, ref.Value)
+			overridden[ref.Value.In+":"+ref.Value.Name] = true
+		}
+	}
+	for _, ref := range item.Parameters {
+		if ref != nil && ref.Value != nil && !overridden[ref.Value.In+":"+ref.Value.Name] {
+			params = append(params, ref.Value)
+		}
+	}
+
+	props := make(openapi3.Schemas, len(params)+1)
+	var required []string
+	for _, p := range params {
+		switch p.In {
+		case openapi3.ParameterInPath, openapi3.ParameterInQuery, openapi3.ParameterInHeader, openapi3.ParameterInCookie:
+		default:
+			return nil, fmt.Errorf("unsupported location %s of parameter %s", p.In, p.Name)
+		}
+		if _, ok := props[p.Name]; ok || p.Name == BodyParamName && op.RequestBody != nil {
+			return nil, fmt.Errorf("duplicate parameter name: %s", p.Name)
+		}
+
+		props[p.Name] = withDescription(paramSchema(p), p.Description)
+		if p.Required || p.In == openapi3.ParameterInPath {
+			required = append(required, p.Name)
+		}
+		t.params = append(t.params, p)
+	}
+
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		body := op.RequestBody.Value
+		contentType, mediaType := pickMediaType(body.Content)
+		var s *openapi3.SchemaRef
+		if mediaType != nil && mediaType.Schema != nil {
+			s = mediaType.Schema
+		} else {
+			s = openapi3.NewStringSchema().NewRef()
+		}
+		props[BodyParamName] = withDescription(s, body.Description)
+		if body.Required {
+			required = append(required, BodyParamName)
+		}
+		t.contentType = contentType
+		t.hasBody = true
+	}
+
+	desc := strings.TrimSpace(op.Summary)
+	if op.Description != "" && op.Description != op.Summary {
+		desc = strings.TrimSpace(desc + "\n" + op.Description)
+	}
+	t.info = &schema.ToolInfo{
+		Name: toolName(method, path, op.OperationID),
+		Desc: desc,
+		ParamsOneOf: schema.NewParamsOneOfByOpenAPIV3(&openapi3.Schema{
+			Type:       openapi3.TypeObject,
+			Properties: props,
+			Required:   required,
+		}),
+	}
+
+	return t, nil
+}
+
+func paramSchema(p *openapi3.Parameter) *openapi3.SchemaRef {
+	if p.Schema != nil {
+		return p.Schema
+	}
+	if _, mediaType := pickMediaType(p.Content); mediaType != nil && mediaType.Schema != nil {
+		return mediaType.Schema
+	}
+	return openapi3.NewStringSchema().NewRef()
+}
+
+// withDescription returns the copy of the schema with the description set, leaving the schema in the doc untouched.
+func withDescription(s *openapi3.SchemaRef, desc string) *openapi3.SchemaRef {
+	if desc == "" || s.Value == nil || s.Value.Description != "" {
+		return s
+	}
+	v := *s.Value
+	v.Description = desc
+	return v.NewRef()
+}
+
+// pickMediaType prefers the json media type, then the form one, then the first one in alphabetical order.
+func pickMediaType(content openapi3.Content) (string, *openapi3.MediaType) {
+	if len(content) == 0 {
+		return "", nil
+	}
+	types := make([]string, 0, len(content))
+	for ct := range content {
+		types = append(types, ct)
+	}
+	sort.Strings(types)
+	for _, ct := range types {
+		if isJSONContentType(ct) {
+			return ct, content[ct]
+		}
+	}
+	for _, ct := range types {
+		if ct == formContentType {
+			return ct, content[ct]
+		}
+	}
+	return types[0], content[types[0]]
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+func toolName(method, path, operationID string) string {
+	name := operationID
+	if name == "" {
+		name = strings.ToLower(method) + path
+	}
+	name = strings.Trim(invalidNameChars.ReplaceAllString(name, "_"), "_")
+	if len(name) > maxToolNameLen {
+		name = name[:maxToolNameLen]
+	}
+	return name
+}
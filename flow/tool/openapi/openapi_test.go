@@ -0,0 +1,269 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+const petStoreSpec = `
+openapi: 3.0.0
+info:
+  title: pet store
+  version: 1.0.0
+servers:
+  - url: http://{host}/v1
+    variables:
+      host:
+        default: petstore.example.com
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List pets
+      parameters:
+        - name: tag
+          in: query
+          description: tags to filter by
+          schema:
+            type: array
+            items:
+              type: string
+        - name: limit
+          in: query
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: ok
+    post:
+      summary: Create a pet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        "201":
+          description: created
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        schema:
+          type: string
+    get:
+      operationId: getPet
+      description: Get a pet by id
+      parameters:
+        - name: X-Request-Id
+          in: header
+          schema:
+            type: string
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        age:
+          type: integer
+`
+
+func TestNewTools(t *testing.T) {
+	ctx := context.Background()
+
+	var lastReq *http.Request
+	var lastBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastReq = r
+		b, _ := io.ReadAll(r.Body)
+		lastBody = string(b)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte("unauthorized"))
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/pets":
+			_, _ = w.Write([]byte(`[{"name":"kitty"},{"name":"doggy"}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/pets":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(lastBody))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/pets/a b":
+			_, _ = w.Write([]byte(`{"name":"kitty"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("not found"))
+		}
+	}))
+	defer srv.Close()
+
+	auth := func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer token")
+		return nil
+	}
+
+	tools, err := NewTools(ctx, &Config{
+		Spec:    []byte(petStoreSpec),
+		BaseURL: srv.URL + "/v1/",
+		Auth:    auth,
+		Headers: map[string]string{"User-Agent": "eino"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, tools, 3)
+
+	byName := make(map[string]tool.InvokableTool)
+	for _, tl := range tools {
+		info, err := tl.Info(ctx)
+		assert.NoError(t, err)
+		byName[info.Name] = tl.(tool.InvokableTool)
+	}
+	assert.Contains(t, byName, "listPets")
+	assert.Contains(t, byName, "getPet")
+	assert.Contains(t, byName, "post_pets")
+
+	t.Run("info", func(t *testing.T) {
+		info, _ := byName["listPets"].Info(ctx)
+		assert.Equal(t, "List pets", info.Desc)
+		params, err := info.ParamsOneOf.ToOpenAPIV3()
+		assert.NoError(t, err)
+		assert.Equal(t, "array", params.Properties["tag"].Value.Type)
+		assert.Equal(t, "tags to filter by", params.Properties["tag"].Value.Description)
+		assert.Equal(t, "integer", params.Properties["limit"].Value.Type)
+		assert.Empty(t, params.Required)
+
+		info, _ = byName["getPet"].Info(ctx)
+		assert.Equal(t, "Get a pet by id", info.Desc)
+		params, err = info.ParamsOneOf.ToOpenAPIV3()
+		assert.NoError(t, err)
+		assert.Contains(t, params.Properties, "petId")
+		assert.Contains(t, params.Properties, "X-Request-Id")
+		assert.Equal(t, []string{"petId"}, params.Required)
+
+		info, _ = byName["post_pets"].Info(ctx)
+		params, err = info.ParamsOneOf.ToOpenAPIV3()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{BodyParamName}, params.Required)
+		body := params.Properties[BodyParamName].Value
+		assert.Equal(t, "object", body.Type)
+		assert.Contains(t, body.Properties, "name")
+	})
+
+	t.Run("query", func(t *testing.T) {
+		out, err := byName["listPets"].InvokableRun(ctx, `{"tag":["cat","dog"],"limit":10}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `[{"name":"kitty"},{"name":"doggy"}]`, out)
+		assert.Equal(t, []string{"cat", "dog"}, lastReq.URL.Query()["tag"])
+		assert.Equal(t, "10", lastReq.URL.Query().Get("limit"))
+		assert.Equal(t, "eino", lastReq.Header.Get("User-Agent"))
+	})
+
+	t.Run("path and header", func(t *testing.T) {
+		out, err := byName["getPet"].InvokableRun(ctx, `{"petId":"a b","X-Request-Id":"req-1"}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"name":"kitty"}`, out)
+		assert.Equal(t, "/v1/pets/a%20b", lastReq.URL.EscapedPath())
+		assert.Equal(t, "req-1", lastReq.Header.Get("X-Request-Id"))
+
+		_, err = byName["getPet"].InvokableRun(ctx, `{}`)
+		assert.ErrorContains(t, err, "path parameter petId is required")
+
+		for _, id := range []string{"", ".", ".."} {
+			_, err = byName["getPet"].InvokableRun(ctx, fmt.Sprintf(`{"petId":%q}`, id))
+			assert.ErrorContains(t, err, "path parameter petId has invalid value")
+		}
+		out, err = byName["getPet"].InvokableRun(ctx, `{"petId":"../admin"}`)
+		assert.NoError(t, err)
+		assert.Equal(t, "/v1/pets/..%2Fadmin", lastReq.URL.EscapedPath())
+	})
+
+	t.Run("body", func(t *testing.T) {
+		out, err := byName["post_pets"].InvokableRun(ctx, `{"body":{"name":"kitty","age":2}}`)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"name":"kitty","age":2}`, out)
+		assert.Equal(t, "application/json", lastReq.Header.Get("Content-Type"))
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		tools, err := NewTools(ctx, &Config{
+			Spec:    []byte(petStoreSpec),
+			BaseURL: srv.URL + "/v1",
+		})
+		assert.NoError(t, err)
+		out, err := tools[0].(tool.InvokableTool).InvokableRun(ctx, `{}`)
+		assert.NoError(t, err)
+		assert.Equal(t, "status: 401 Unauthorized\nunauthorized", out)
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		tools, err := NewTools(ctx, &Config{
+			Spec:             []byte(petStoreSpec),
+			BaseURL:          srv.URL + "/v1",
+			Auth:             auth,
+			MaxResponseBytes: 10,
+			TruncateMarker:   "...",
+			Filter: func(method, path string, op *openapi3.Operation) bool {
+				return op.OperationID == "listPets"
+			},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, tools, 1)
+		out, err := tools[0].(tool.InvokableTool).InvokableRun(ctx, `{}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `[{"name":"...`, out)
+	})
+}
+
+func TestNewToolsConfig(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewTools(ctx, &Config{})
+	assert.ErrorContains(t, err, "spec is required")
+
+	_, err = NewTools(ctx, &Config{Spec: []byte("not a spec")})
+	assert.Error(t, err)
+
+	tools, err := NewTools(ctx, &Config{Spec: []byte(petStoreSpec)})
+	assert.NoError(t, err)
+	assert.Equal(t, "http://petstore.example.com/v1", tools[0].(*operationTool).baseURL)
+
+	noServer := strings.Replace(petStoreSpec, "servers:", "x-servers:", 1)
+	_, err = NewTools(ctx, &Config{Spec: []byte(noServer)})
+	assert.ErrorContains(t, err, "base url is required")
+
+	assert.Equal(t, "get_pets_petId", toolName("GET", "/pets/{petId}", ""))
+	assert.Equal(t, "pets_get", toolName("GET", "/pets", "pets.get"))
+}
@@ -0,0 +1,235 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+const formContentType = "application/x-www-form-urlencoded"
+
+type operationTool struct {
+	info   *schema.ToolInfo
+	method string
+	path   string
+
+	params      []*openapi3.Parameter
+	hasBody     bool
+	contentType string
+
+	baseURL          string
+	client           *http.Client
+	auth             AuthInjector
+	headers          map[string]string
+	maxResponseBytes int
+	truncateMarker   string
+}
+
+func (t *operationTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return t.info, nil
+}
+
+func (t *operationTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	args := make(map[string]any)
+	if strings.TrimSpace(argumentsInJSON) != "" {
+		if err := sonic.UnmarshalString(argumentsInJSON, &args); err != nil {
+			return "", fmt.Errorf("failed to unmarshal arguments of tool %s: %w", t.info.Name, err)
+		}
+	}
+
+	req, err := t.newRequest(ctx, args)
+	if err != nil {
+		return "", fmt.Errorf("failed to build request of tool %s: %w", t.info.Name, err)
+	}
+	if t.auth != nil {
+		if err = t.auth(ctx, req); err != nil {
+			return "", fmt.Errorf("failed to inject auth of tool %s: %w", t.info.Name, err)
+		}
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call %s %s: %w", t.method, t.path, err)
+	}
+	defer resp.Body.Close()
+
+	body, truncated, err := t.readBody(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response of %s %s: %w", t.method, t.path, err)
+	}
+	if truncated {
+		body += t.truncateMarker
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Sprintf("status: %s\n%s", resp.Status, body), nil
+	}
+	return body, nil
+}
+
+func (t *operationTool) newRequest(ctx context.Context, args map[string]any) (*http.Request, error) {
+	path := t.path
+	query := url.Values{}
+	header := http.Header{}
+	var cookies []*http.Cookie
+
+	for _, p := range t.params {
+		v, ok := args[p.Name]
+		if !ok || v == nil {
+			if p.In == openapi3.ParameterInPath {
+				return nil, fmt.Errorf("path parameter %s is required", p.Name)
+			}
+			continue
+		}
+
+		switch p.In {
+		case openapi3.ParameterInPath:
+			value := strings.Join(formatValues(v), ",")
+			// a dot segment would move the request to another resource, as PathEscape keeps dots
+			if value == "" || value == "." || value == ".." {
+				return nil, fmt.Errorf("path parameter %s has invalid value %q", p.Name, value)
+			}
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(value))
+		case openapi3.ParameterInQuery:
+			for _, s := range formatValues(v) {
+				query.Add(p.Name, s)
+			}
+		case openapi3.ParameterInHeader:
+			header.Set(p.Name, strings.Join(formatValues(v), ","))
+		case openapi3.ParameterInCookie:
+			cookies = append(cookies, &http.Cookie{Name: p.Name, Value: strings.Join(formatValues(v), ",")})
+		}
+	}
+
+	u := t.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if b, ok := args[BodyParamName]; ok && t.hasBody && b != nil {
+		data, err := t.encodeBody(b)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, t.method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	if body != nil && t.contentType != "" {
+		req.Header.Set("Content-Type", t.contentType)
+	}
+	return req, nil
+}
+
+func (t *operationTool) encodeBody(b any) ([]byte, error) {
+	switch {
+	case t.contentType == "" || isJSONContentType(t.contentType):
+		return sonic.Marshal(b)
+	case t.contentType == formContentType:
+		fields, ok := b.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("form body should be an object, got %T", b)
+		}
+		form := url.Values{}
+		for k, v := range fields {
+			for _, s := range formatValues(v) {
+				form.Add(k, s)
+			}
+		}
+		return []byte(form.Encode()), nil
+	default:
+		if s, ok := b.(string); ok {
+			return []byte(s), nil
+		}
+		return sonic.Marshal(b)
+	}
+}
+
+func (t *operationTool) readBody(r io.Reader) (string, bool, error) {
+	if t.maxResponseBytes <= 0 {
+		data, err := io.ReadAll(r)
+		return string(data), false, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, int64(t.maxResponseBytes)+1))
+	if err != nil {
+		return "", false, err
+	}
+	if len(data) <= t.maxResponseBytes {
+		return string(data), false, nil
+	}
+	return string(data[:t.maxResponseBytes]), true, nil
+}
+
+// formatValues formats the argument as the values of a parameter, one value for each element if it's an array.
+func formatValues(v any) []string {
+	if arr, ok := v.([]any); ok {
+		ret := make([]string, 0, len(arr))
+		for _, e := range arr {
+			ret = append(ret, formatValue(e))
+		}
+		return ret
+	}
+	return []string{formatValue(v)}
+}
+
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		s, err := sonic.MarshalString(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return s
+	}
+}
+
+func isJSONContentType(ct string) bool {
+	ct = strings.ToLower(strings.TrimSpace(strings.Split(ct, ";")[0]))
+	return ct == "application/json" || strings.HasSuffix(ct, "+json")
+}
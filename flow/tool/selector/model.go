@@ -0,0 +1,104 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selector
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Model wraps the chat model to bind the tools selected by the Selector for the input messages on each call,
+// in addition to the tools bound by WithTools of the returned model, e.g. a few tools always needed.
+// The tools given by the model.WithTools option of a call replace the ones bound by WithTools for the call,
+// and are still bound in addition to the selected tools, e.g. the tools listed from the toolkits for the run.
+func (s *Selector) Model(cm model.ToolCallingChatModel) model.ToolCallingChatModel {
+	return &selectingModel{s: s, cm: cm}
+}
+
+type selectingModel struct {
+	s     *Selector
+	cm    model.ToolCallingChatModel
+	bound []*schema.ToolInfo
+}
+
+func (m *selectingModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	cm, opts, err := m.withSelectedTools(ctx, input, opts)
+	if err != nil {
+		return nil, err
+	}
+	return cm.Generate(ctx, input, opts...)
+}
+
+func (m *selectingModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	cm, opts, err := m.withSelectedTools(ctx, input, opts)
+	if err != nil {
+		return nil, err
+	}
+	return cm.Stream(ctx, input, opts...)
+}
+
+func (m *selectingModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return &selectingModel{s: m.s, cm: m.cm, bound: tools}, nil
+}
+
+func (m *selectingModel) GetType() string {
+	typ, _ := components.GetType(m.cm)
+	return typ
+}
+
+func (m *selectingModel) IsCallbacksEnabled() bool {
+	return components.IsCallbacksEnabled(m.cm)
+}
+
+// withSelectedTools returns the model binding the selected tools, and the options in which
+// the tools of the model.WithTools option, if given, are replaced with the ones along with the selected tools,
+// since the model implementations let the option override the tools bound by WithTools.
+func (m *selectingModel) withSelectedTools(ctx context.Context, input []*schema.Message, opts []model.Option) (
+	model.ToolCallingChatModel, []model.Option, error) {
+	selected, err := m.s.Select(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bound := m.bound
+	optTools := model.GetCommonOptions(&model.Options{}, opts...).Tools
+	if optTools != nil {
+		bound = optTools
+	}
+
+	tools := make([]*schema.ToolInfo, 0, len(bound)+len(selected))
+	names := make(map[string]bool, len(bound)+len(selected))
+	for _, infos := range [][]*schema.ToolInfo{bound, selected} {
+		for _, info := range infos {
+			if !names[info.Name] {
+				names[info.Name] = true
+				tools = append(tools, info)
+			}
+		}
+	}
+	cm, err := m.cm.WithTools(tools)
+	if err != nil {
+		return nil, nil, err
+	}
+	if optTools != nil {
+		opts = append(opts[:len(opts):len(opts)], model.WithTools(tools))
+	}
+	return cm, opts, nil
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+var searchToolInfo = &schema.ToolInfo{
+	Name: SearchToolName,
+	Desc: "Search for more tools by describing what you want to do, when none of the available tools fits the task. " +
+		"The tools found can be called afterwards.",
+	ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+		"query": {
+			Type:     schema.String,
+			Desc:     "what the tool should do",
+			Required: true,
+		},
+		"top_k": {
+			Type: schema.Integer,
+			Desc: "the max number of tools to find, default 5",
+		},
+	}),
+}
+
+type searchToolResult struct {
+	Tools   []searchToolResultItem `json:"tools"`
+	Message string                 `json:"message,omitempty"`
+}
+
+type searchToolResultItem struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type searchTool struct {
+	s *Selector
+}
+
+func (t *searchTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return searchToolInfo, nil
+}
+
+func (t *searchTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	var args struct {
+		Query string `json:"query"`
+		TopK  int    `json:"top_k"`
+	}
+	if err := sonic.UnmarshalString(argumentsInJSON, &args); err != nil {
+		return fmt.Sprintf("invalid arguments: %v", err), nil
+	}
+	if args.TopK <= 0 {
+		args.TopK = t.s.topK
+	}
+
+	infos, err := t.s.Search(ctx, args.Query, args.TopK)
+	if err != nil {
+		return "", err
+	}
+
+	result := &searchToolResult{Tools: make([]searchToolResultItem, len(infos))}
+	for i, info := range infos {
+		result.Tools[i] = searchToolResultItem{Name: info.Name, Description: info.Desc}
+	}
+	if len(infos) == 0 {
+		result.Message = "no tool found, try another query"
+	} else {
+		result.Message = "the tools found can be called now"
+	}
+	return sonic.MarshalString(result)
+}
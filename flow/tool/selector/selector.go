@@ -0,0 +1,352 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package selector provides the embedding-based tool selection for large tool catalogs,
+// which binds only the tools relevant to the conversation to the chat model on each turn.
+package selector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// SearchToolName is the name of the meta-tool letting the model search the catalog for more tools.
+const SearchToolName = "search_tools"
+
+const (
+	defaultTopK      = 5
+	defaultBatchSize = 64
+)
+
+// Config is the config for Selector.
+type Config struct {
+	// Embedder embeds the tool docs and the queries, required.
+	Embedder embedding.Embedder
+	// Tools is the catalog to select from, required.
+	Tools []tool.BaseTool
+
+	// TopK is the max number of tools selected on each turn, default 5.
+	TopK int
+	// MinScore is the min cosine similarity of the selected tools, optional.
+	MinScore float64
+	// BatchSize is the max number of tool docs embedded in one call when indexing, default 64.
+	BatchSize int
+
+	// QueryBuilder builds the query text from the conversation, optional.
+	// By default, it's the content of the last user message, and the last message if it's not from the user.
+	QueryBuilder func(ctx context.Context, messages []*schema.Message) string
+	// DocBuilder builds the text indexed for a tool, optional.
+	// By default, it's the name, the description and the parameter docs of the tool.
+	DocBuilder func(info *schema.ToolInfo) string
+
+	// EnableSearchTool adds the SearchToolName meta-tool, with which the model can request more tools.
+	// The tools found are bound on the following turns.
+	EnableSearchTool bool
+}
+
+// Selector selects the tools relevant to the conversation from a catalog by embedding similarity, implementing tool.ToolSelector.
+// Use Model to bind only the selected tools on each turn, and Tools to get the tools to be executed by ToolsNode.
+// e.g.
+//
+//	sel, err := selector.NewSelector(ctx, &selector.Config{Embedder: embedder, Tools: catalog, EnableSearchTool: true})
+//	agent, err := react.NewAgent(ctx, &react.AgentConfig{
+//		ToolCallingModel: cm,
+//		ToolSelector:     sel,
+//	})
+type Selector struct {
+	embedder     embedding.Embedder
+	topK         int
+	minScore     float64
+	queryBuilder func(ctx context.Context, messages []*schema.Message) string
+
+	tools   []tool.BaseTool
+	infos   []*schema.ToolInfo
+	vectors [][]float64
+	indexes map[string]int
+
+	searchTool tool.BaseTool
+}
+
+var _ tool.ToolSelector = &Selector{}
+
+// NewSelector creates a Selector, indexing the tools in the catalog with the Embedder.
+func NewSelector(ctx context.Context, config *Config) (*Selector, error) {
+	if config.Embedder == nil {
+		return nil, errors.New("embedder is required")
+	}
+	if len(config.Tools) == 0 {
+		return nil, errors.New("tools are required")
+	}
+
+	s := &Selector{
+		embedder:     config.Embedder,
+		topK:         config.TopK,
+		minScore:     config.MinScore,
+		queryBuilder: config.QueryBuilder,
+		tools:        config.Tools,
+		infos:        make([]*schema.ToolInfo, len(config.Tools)),
+		indexes:      make(map[string]int, len(config.Tools)),
+	}
+	if s.topK <= 0 {
+		s.topK = defaultTopK
+	}
+	if s.queryBuilder == nil {
+		s.queryBuilder = defaultQueryBuilder
+	}
+	docBuilder := config.DocBuilder
+	if docBuilder == nil {
+		docBuilder = defaultDocBuilder
+	}
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	docs := make([]string, len(config.Tools))
+	for i, t := range config.Tools {
+		info, err := t.Info(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tool info at idx=%d: %w", i, err)
+		}
+		if _, ok := s.indexes[info.Name]; ok {
+			return nil, fmt.Errorf("duplicate tool name: %s", info.Name)
+		}
+		s.infos[i] = info
+		s.indexes[info.Name] = i
+		docs[i] = docBuilder(info)
+	}
+
+	for start := 0; start < len(docs); start += batchSize {
+		end := start + batchSize
+		if end > len(docs) {
+			end = len(docs)
+		}
+		vectors, err := s.embedder.EmbedStrings(ctx, docs[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed tool docs: %w", err)
+		}
+		if len(vectors) != end-start {
+			return nil, fmt.Errorf("embedder returns %d vectors for %d tool docs", len(vectors), end-start)
+		}
+		s.vectors = append(s.vectors, vectors...)
+	}
+
+	if config.EnableSearchTool {
+		s.searchTool = &searchTool{s: s}
+	}
+	return s, nil
+}
+
+// Tools returns the tools of the catalog, and the SearchToolName tool if enabled, to be executed by ToolsNode.
+func (s *Selector) Tools() []tool.BaseTool {
+	if s.searchTool == nil {
+		return s.tools
+	}
+	return append(s.tools[:len(s.tools):len(s.tools)], s.searchTool)
+}
+
+// Select returns the infos of the tools to bind for the conversation, which are
+// the top k tools most relevant to the query built from the messages,
+// the tools called or found by the SearchToolName tool in the messages, and the SearchToolName tool if enabled.
+func (s *Selector) Select(ctx context.Context, messages []*schema.Message) ([]*schema.ToolInfo, error) {
+	selected := make(map[int]bool)
+	var ret []*schema.ToolInfo
+	add := func(idx int) {
+		if !selected[idx] {
+			selected[idx] = true
+			ret = append(ret, s.infos[idx])
+		}
+	}
+
+	if query := s.queryBuilder(ctx, messages); query != "" {
+		found, err := s.search(ctx, query, s.topK)
+		if err != nil {
+			return nil, err
+		}
+		for _, idx := range found {
+			add(idx)
+		}
+	}
+
+	for _, name := range usedToolNames(messages) {
+		if idx, ok := s.indexes[name]; ok {
+			add(idx)
+		}
+	}
+
+	if s.searchTool != nil {
+		ret = append(ret, searchToolInfo)
+	}
+	return ret, nil
+}
+
+// Search returns the infos of the k tools most relevant to the query.
+func (s *Selector) Search(ctx context.Context, query string, k int) ([]*schema.ToolInfo, error) {
+	found, err := s.search(ctx, query, k)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*schema.ToolInfo, len(found))
+	for i, idx := range found {
+		ret[i] = s.infos[idx]
+	}
+	return ret, nil
+}
+
+func (s *Selector) search(ctx context.Context, query string, k int) ([]int, error) {
+	vectors, err := s.embedder.EmbedStrings(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embedder returns %d vectors for 1 query", len(vectors))
+	}
+
+	type scored struct {
+		idx   int
+		score float64
+	}
+	candidates := make([]scored, 0, len(s.vectors))
+	for i, v := range s.vectors {
+		score := cosineSimilarity(vectors[0], v)
+		if score >= s.minScore {
+			candidates = append(candidates, scored{idx: i, score: score})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+	if k > len(candidates) {
+		k = len(candidates)
+	}
+
+	ret := make([]int, k)
+	for i := range ret {
+		ret[i] = candidates[i].idx
+	}
+	return ret, nil
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func defaultQueryBuilder(_ context.Context, messages []*schema.Message) string {
+	var parts []string
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i] == nil {
+			continue
+		}
+		if messages[i].Role == schema.User {
+			parts = append([]string{messages[i].Content}, parts...)
+			break
+		}
+		if len(parts) == 0 && messages[i].Content != "" {
+			parts = append(parts, messages[i].Content)
+		}
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n"))
+}
+
+func defaultDocBuilder(info *schema.ToolInfo) string {
+	sb := strings.Builder{}
+	sb.WriteString(info.Name)
+	if info.Desc != "" {
+		sb.WriteString(": ")
+		sb.WriteString(info.Desc)
+	}
+	if info.ParamsOneOf == nil {
+		return sb.String()
+	}
+
+	params, err := info.ParamsOneOf.ToOpenAPIV3()
+	if err != nil || params == nil {
+		return sb.String()
+	}
+	names := make([]string, 0, len(params.Properties))
+	for name := range params.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sb.WriteString("\n- ")
+		sb.WriteString(name)
+		if p := params.Properties[name]; p != nil && p.Value != nil {
+			writeParamDoc(&sb, p.Value)
+		}
+	}
+	return sb.String()
+}
+
+func writeParamDoc(sb *strings.Builder, p *openapi3.Schema) {
+	if p.Description != "" {
+		sb.WriteString(": ")
+		sb.WriteString(p.Description)
+	}
+	for _, e := range p.Enum {
+		sb.WriteString(fmt.Sprintf(" %v", e))
+	}
+}
+
+// usedToolNames returns the names of the tools called, and found by the SearchToolName tool in the messages.
+func usedToolNames(messages []*schema.Message) []string {
+	var names []string
+	searchCalls := make(map[string]bool)
+	for _, m := range messages {
+		if m == nil {
+			continue
+		}
+		for _, tc := range m.ToolCalls {
+			if tc.Function.Name == SearchToolName {
+				searchCalls[tc.ID] = true
+				continue
+			}
+			names = append(names, tc.Function.Name)
+		}
+		if m.Role == schema.Tool && (searchCalls[m.ToolCallID] || m.ToolName == SearchToolName) {
+			var result searchToolResult
+			if err := sonic.UnmarshalString(m.Content, &result); err == nil {
+				for _, t := range result.Tools {
+					names = append(names, t.Name)
+				}
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selector
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	mockEmbedding "github.com/cloudwego/eino/internal/mock/components/embedding"
+	"github.com/cloudwego/eino/schema"
+)
+
+type toolsRecordingModel struct {
+	model.ToolCallingChatModel
+	tools [][]string
+	// optTools are the tools of the model.WithTools option of each call
+	optTools [][]string
+}
+
+func (m *toolsRecordingModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	names := make([]string, len(tools))
+	for i, t := range tools {
+		names[i] = t.Name
+	}
+	m.tools = append(m.tools, names)
+	return m, nil
+}
+
+func (m *toolsRecordingModel) Generate(_ context.Context, _ []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	if tools := model.GetCommonOptions(&model.Options{}, opts...).Tools; tools != nil {
+		m.optTools = append(m.optTools, toolNames(tools))
+	}
+	return schema.AssistantMessage("ok", nil), nil
+}
+
+type catalogInput struct {
+	City string `json:"city" jsonschema:"description=the city to query"`
+}
+
+func newCatalog(t *testing.T) []tool.BaseTool {
+	var tools []tool.BaseTool
+	for name, desc := range map[string]string{
+		"weather":  "query the weather forecast",
+		"flight":   "book a flight ticket",
+		"hotel":    "book a hotel room",
+		"stock":    "query the stock price",
+		"calendar": "create a calendar event",
+	} {
+		tl, err := utils.InferTool(name, desc, func(ctx context.Context, in *catalogInput) (string, error) {
+			return in.City, nil
+		})
+		assert.NoError(t, err)
+		tools = append(tools, tl)
+	}
+	return tools
+}
+
+func toolNames(infos []*schema.ToolInfo) []string {
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name
+	}
+	return names
+}
+
+func TestSelector(t *testing.T) {
+	ctx := context.Background()
+	embedder := &mockEmbedding.KeywordEmbedder{Keywords: []string{"weather", "flight", "hotel", "stock", "calendar", "book", "city"}}
+
+	_, err := NewSelector(ctx, &Config{Tools: newCatalog(t)})
+	assert.ErrorContains(t, err, "embedder is required")
+
+	s, err := NewSelector(ctx, &Config{
+		Embedder:         embedder,
+		Tools:            newCatalog(t),
+		TopK:             2,
+		MinScore:         0.1,
+		BatchSize:        2,
+		EnableSearchTool: true,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, embedder.Calls(), 3)
+	assert.Contains(t, strings.Join(embedder.Calls()[0], "\n"), "- city: the city to query")
+	assert.Len(t, s.Tools(), 6)
+
+	t.Run("select", func(t *testing.T) {
+		infos, err := s.Select(ctx, []*schema.Message{
+			schema.UserMessage("what's the weather in Paris"),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"weather", SearchToolName}, toolNames(infos))
+
+		infos, err = s.Select(ctx, []*schema.Message{
+			schema.UserMessage("book a flight and a hotel"),
+		})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"flight", "hotel", SearchToolName}, toolNames(infos))
+
+		// the tools called and found by search_tools are kept
+		searchResult, err := s.searchTool.(tool.InvokableTool).InvokableRun(ctx, `{"query": "stock price", "top_k": 1}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"tools":[{"name":"stock","description":"query the stock price"}],"message":"the tools found can be called now"}`, searchResult)
+		infos, err = s.Select(ctx, []*schema.Message{
+			schema.UserMessage("what's the weather"),
+			schema.AssistantMessage("", []schema.ToolCall{
+				{ID: "1", Function: schema.FunctionCall{Name: "calendar", Arguments: "{}"}},
+				{ID: "2", Function: schema.FunctionCall{Name: SearchToolName, Arguments: `{"query": "stock price"}`}},
+			}),
+			schema.ToolMessage("done", "1"),
+			schema.ToolMessage(searchResult, "2"),
+		})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"weather", "calendar", "stock", SearchToolName}, toolNames(infos))
+	})
+
+	t.Run("model", func(t *testing.T) {
+		cm := &toolsRecordingModel{}
+		always := &schema.ToolInfo{Name: "always"}
+		wrapped, err := s.Model(cm).WithTools([]*schema.ToolInfo{always})
+		assert.NoError(t, err)
+
+		_, err = wrapped.Generate(ctx, []*schema.Message{schema.UserMessage("weather")})
+		assert.NoError(t, err)
+		_, err = wrapped.Generate(ctx, []*schema.Message{schema.UserMessage("stock")})
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"always", "weather", SearchToolName}, {"always", "stock", SearchToolName}}, cm.tools)
+		assert.Empty(t, cm.optTools)
+
+		// the tools of the option are bound along with the selected tools, instead of overriding them
+		_, err = wrapped.Generate(ctx, []*schema.Message{schema.UserMessage("weather")},
+			model.WithTools([]*schema.ToolInfo{{Name: "listed"}}))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"listed", "weather", SearchToolName}, cm.tools[2])
+		assert.Equal(t, [][]string{{"listed", "weather", SearchToolName}}, cm.optTools)
+	})
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package embedding
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/components/embedding"
+)
+
+// KeywordEmbedder is a fake embedder for tests, embedding each text as the counts of the keywords in the lowercased text,
+// so that the texts sharing keywords are similar.
+type KeywordEmbedder struct {
+	Keywords []string
+
+	mu    sync.Mutex
+	calls [][]string
+}
+
+// EmbedStrings embeds the texts by counting the keywords, and records the texts.
+func (e *KeywordEmbedder) EmbedStrings(_ context.Context, texts []string, _ ...embedding.Option) ([][]float64, error) {
+	e.mu.Lock()
+	e.calls = append(e.calls, texts)
+	e.mu.Unlock()
+
+	ret := make([][]float64, len(texts))
+	for i, text := range texts {
+		ret[i] = make([]float64, len(e.Keywords))
+		for j, k := range e.Keywords {
+			ret[i][j] = float64(strings.Count(strings.ToLower(text), k))
+		}
+	}
+	return ret, nil
+}
+
+// Calls returns the texts of each EmbedStrings call.
+func (e *KeywordEmbedder) Calls() [][]string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.calls
+}
@@ -938,6 +938,7 @@ func ConcatMessages(msgs []*Message) (*Message, error) {
 		toolCalls                     []ToolCall
 		multiContentParts             []ChatMessagePart
 		assistantGenMultiContentParts []MessageOutputPart
+		userInputMultiContentParts    []MessageInputPart
 		ret                           = Message{}
 		extraList                     = make([]map[string]any, 0, len(msgs))
 	)
@@ -1008,6 +1009,10 @@ func ConcatMessages(msgs []*Message) (*Message, error) {
 			assistantGenMultiContentParts = append(assistantGenMultiContentParts, msg.AssistantGenMultiContent...)
 		}
 
+		if len(msg.UserInputMultiContent) > 0 {
+			userInputMultiContentParts = append(userInputMultiContentParts, msg.UserInputMultiContent...)
+		}
+
 		if msg.ResponseMeta != nil && ret.ResponseMeta == nil {
 			ret.ResponseMeta = &ResponseMeta{}
 		}
@@ -1107,6 +1112,10 @@ func ConcatMessages(msgs []*Message) (*Message, error) {
 		ret.AssistantGenMultiContent = merged
 	}
 
+	if len(userInputMultiContentParts) > 0 {
+		ret.UserInputMultiContent = userInputMultiContentParts
+	}
+
 	return &ret, nil
 }
 
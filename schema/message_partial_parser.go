@@ -0,0 +1,478 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/bytedance/sonic"
+)
+
+// PartialResult is a value parsed from incomplete data, e.g. the structured output being streamed.
+type PartialResult[T any] struct {
+	Value T
+	// Final is true if Value is parsed from the complete data.
+	Final bool
+	// FinalPaths are the paths of the values that are complete and won't change anymore, in the order of completion,
+	// e.g. "name", "tags[0]", "steps[1].title". Incomplete values, e.g. a truncated string, are included in Value but not in FinalPaths.
+	FinalPaths []string
+}
+
+// MessageStreamParser parses a stream of message chunks into progressively completed values.
+type MessageStreamParser[T any] interface {
+	ParseStream(ctx context.Context, sr *StreamReader[*Message]) (*StreamReader[*PartialResult[T]], error)
+}
+
+// NewMessageJSONStreamParser creates a new MessageJSONStreamParser.
+func NewMessageJSONStreamParser[T any](config *MessageJSONParseConfig) MessageStreamParser[T] {
+	if config == nil {
+		config = &MessageJSONParseConfig{}
+	}
+
+	if config.ParseFrom == "" {
+		config.ParseFrom = MessageParseFromContent
+	}
+
+	return &MessageJSONStreamParser[T]{
+		ParseFrom:    config.ParseFrom,
+		ParseKeyPath: config.ParseKeyPath,
+	}
+}
+
+// MessageJSONStreamParser parses the json streamed in message chunks, from the content or the arguments of the first tool call,
+// and emits a PartialResult each time the parsed value grows, so that structured output can be shown before the stream ends.
+// Truncated strings, arrays and objects are closed, while truncated keys and literals are omitted.
+// The last result is parsed from the complete json with Final set, the same as MessageJSONParser.
+// e.g.
+//
+//	parser := schema.NewMessageJSONStreamParser[Plan](&schema.MessageJSONParseConfig{
+//		ParseFrom: schema.MessageParseFromToolCall,
+//	})
+//	results, err := parser.ParseStream(ctx, modelStream)
+//	defer results.Close()
+//	for {
+//		result, err := results.Recv()
+//		...
+//		render(result.Value) // e.g. Plan{Steps: []Step{{Title: "Sea"}}} -> Plan{Steps: []Step{{Title: "Search docs"}}}
+//	}
+type MessageJSONStreamParser[T any] struct {
+	ParseFrom    MessageParseFrom
+	ParseKeyPath string
+}
+
+// ParseStream parses the message stream, errors of the stream are forwarded, and sr is closed at the end.
+func (p *MessageJSONStreamParser[T]) ParseStream(ctx context.Context, sr *StreamReader[*Message]) (*StreamReader[*PartialResult[T]], error) {
+	if p.ParseFrom != MessageParseFromContent && p.ParseFrom != MessageParseFromToolCall {
+		return nil, fmt.Errorf("invalid parse from type: %s", p.ParseFrom)
+	}
+
+	return runOperator(ctx, func(o *operator[*PartialResult[T]]) {
+		in := newUpstream(o, sr)
+
+		var data strings.Builder
+		var toolCallIndex *int
+		toolCallFound := false
+		var last string
+
+		for {
+			chunk, err, ok := recvUpstream(o, in)
+			if !ok {
+				return
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				if !o.send(nil, err) {
+					return
+				}
+				continue
+			}
+			if chunk == nil {
+				continue
+			}
+
+			if p.ParseFrom == MessageParseFromContent {
+				data.WriteString(chunk.Content)
+			} else {
+				for _, tc := range chunk.ToolCalls {
+					if !toolCallFound {
+						toolCallFound, toolCallIndex = true, tc.Index
+					}
+					if sameToolCallIndex(toolCallIndex, tc.Index) {
+						data.WriteString(tc.Function.Arguments)
+					}
+				}
+			}
+
+			result, repaired, pErr := parsePartialJSON[T](data.String(), p.ParseKeyPath)
+			if pErr != nil || result == nil || repaired == last {
+				// invalid json is reported when the stream ends
+				continue
+			}
+			// only the result parsed after the stream ends is final, as trailing data may still arrive
+			last, result.Final = repaired, false
+			if !o.send(result, nil) {
+				return
+			}
+		}
+
+		if p.ParseFrom == MessageParseFromToolCall && !toolCallFound {
+			o.send(nil, fmt.Errorf("no tool call found"))
+			return
+		}
+
+		full := data.String()
+		parsed, err := (&MessageJSONParser[T]{ParseKeyPath: p.ParseKeyPath}).parse(full)
+		if err != nil {
+			o.send(nil, err)
+			return
+		}
+		result := &PartialResult[T]{Value: parsed, Final: true}
+		if partial, _, pErr := parsePartialJSON[T](full, p.ParseKeyPath); pErr == nil && partial != nil {
+			result.FinalPaths = partial.FinalPaths
+		}
+		o.send(result, nil)
+	}), nil
+}
+
+func sameToolCallIndex(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// ParsePartialJSON parses the possibly incomplete json into T, see MessageJSONStreamParser for the tolerance rules.
+// It returns nil if data doesn't contain any value yet.
+func ParsePartialJSON[T any](data string) (*PartialResult[T], error) {
+	result, _, err := parsePartialJSON[T](data, "")
+	return result, err
+}
+
+// parsePartialJSON also returns the repaired json, which can be used to check whether the value has changed.
+func parsePartialJSON[T any](data string, keyPath string) (*PartialResult[T], string, error) {
+	pp := &partialJSONParser{s: data}
+	value, complete, ok, err := pp.parseValue("")
+	if err != nil {
+		return nil, "", err
+	}
+	if !ok {
+		return nil, "", nil
+	}
+
+	finalPaths := pp.finalPaths
+	if keyPath != "" {
+		for _, key := range strings.Split(keyPath, ".") {
+			obj, isObj := value.(map[string]any)
+			if !isObj {
+				return nil, "", nil
+			}
+			if value, ok = obj[key]; !ok {
+				return nil, "", nil
+			}
+		}
+		finalPaths = nil
+		complete = false
+		for _, path := range pp.finalPaths {
+			if path == keyPath {
+				complete = true
+			} else if strings.HasPrefix(path, keyPath+".") || strings.HasPrefix(path, keyPath+"[") {
+				finalPaths = append(finalPaths, strings.TrimPrefix(path[len(keyPath):], "."))
+			}
+		}
+	}
+
+	repaired, err := sonic.ConfigStd.MarshalToString(value) // sorted keys, so that the same value gets the same json
+	if err != nil {
+		return nil, "", err
+	}
+	result := &PartialResult[T]{Final: complete, FinalPaths: finalPaths}
+	if err = sonic.UnmarshalString(repaired, &result.Value); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal partial json: %w", err)
+	}
+	return result, repaired, nil
+}
+
+// partialJSONParser parses a prefix of a json document.
+type partialJSONParser struct {
+	s          string
+	pos        int
+	finalPaths []string
+}
+
+// parseValue returns ok=false if the data ends before any usable part of the value,
+// and complete=false if the value is truncated.
+func (p *partialJSONParser) parseValue(path string) (value any, complete bool, ok bool, err error) {
+	p.skipWhitespace()
+	if p.pos >= len(p.s) {
+		return nil, false, false, nil
+	}
+
+	switch c := p.s[p.pos]; {
+	case c == '{':
+		return p.parseObject(path)
+	case c == '[':
+		return p.parseArray(path)
+	case c == '"':
+		str, complete := p.parseString()
+		if complete {
+			p.final(path)
+		}
+		return str, complete, true, nil
+	case c == 't':
+		return p.parseLiteral(path, "true", true)
+	case c == 'f':
+		return p.parseLiteral(path, "false", false)
+	case c == 'n':
+		return p.parseLiteral(path, "null", nil)
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumber(path)
+	default:
+		return nil, false, false, fmt.Errorf("invalid character %q at offset %d", c, p.pos)
+	}
+}
+
+func (p *partialJSONParser) parseObject(path string) (any, bool, bool, error) {
+	obj := map[string]any{}
+	p.pos++ // {
+	for first := true; ; first = false {
+		p.skipWhitespace()
+		if p.pos >= len(p.s) {
+			return obj, false, true, nil
+		}
+		if p.s[p.pos] == '}' {
+			p.pos++
+			p.final(path)
+			return obj, true, true, nil
+		}
+		if !first {
+			if p.s[p.pos] != ',' {
+				return nil, false, false, fmt.Errorf("expected ',' or '}' at offset %d", p.pos)
+			}
+			p.pos++
+			p.skipWhitespace()
+			if p.pos >= len(p.s) {
+				return obj, false, true, nil
+			}
+		}
+
+		if p.s[p.pos] != '"' {
+			return nil, false, false, fmt.Errorf("expected object key at offset %d", p.pos)
+		}
+		key, keyComplete := p.parseString()
+		if !keyComplete {
+			return obj, false, true, nil
+		}
+		p.skipWhitespace()
+		if p.pos >= len(p.s) {
+			return obj, false, true, nil
+		}
+		if p.s[p.pos] != ':' {
+			return nil, false, false, fmt.Errorf("expected ':' at offset %d", p.pos)
+		}
+		p.pos++
+
+		childPath := key
+		if path != "" {
+			childPath = path + "." + key
+		}
+		value, complete, ok, err := p.parseValue(childPath)
+		if err != nil {
+			return nil, false, false, err
+		}
+		if ok {
+			obj[key] = value
+		}
+		if !complete {
+			return obj, false, true, nil
+		}
+	}
+}
+
+func (p *partialJSONParser) parseArray(path string) (any, bool, bool, error) {
+	arr := []any{}
+	p.pos++ // [
+	for i := 0; ; i++ {
+		p.skipWhitespace()
+		if p.pos >= len(p.s) {
+			return arr, false, true, nil
+		}
+		if p.s[p.pos] == ']' {
+			p.pos++
+			p.final(path)
+			return arr, true, true, nil
+		}
+		if i > 0 {
+			if p.s[p.pos] != ',' {
+				return nil, false, false, fmt.Errorf("expected ',' or ']' at offset %d", p.pos)
+			}
+			p.pos++
+		}
+
+		value, complete, ok, err := p.parseValue(path + "[" + strconv.Itoa(i) + "]")
+		if err != nil {
+			return nil, false, false, err
+		}
+		if ok {
+			arr = append(arr, value)
+		}
+		if !complete {
+			return arr, false, true, nil
+		}
+	}
+}
+
+// parseString returns the decoded string, and complete=false if the closing quote is missing.
+// A truncated escape sequence is dropped.
+func (p *partialJSONParser) parseString() (string, bool) {
+	var sb strings.Builder
+	p.pos++ // "
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return sb.String(), true
+		case '\\':
+			if p.pos+1 >= len(p.s) {
+				p.pos = len(p.s)
+				return sb.String(), false
+			}
+			esc := p.s[p.pos+1]
+			switch esc {
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'u':
+				r, n, ok := p.parseUnicodeEscape(p.pos)
+				if !ok {
+					p.pos = len(p.s)
+					return sb.String(), false
+				}
+				sb.WriteRune(r)
+				p.pos += n
+				continue
+			default:
+				sb.WriteByte(esc)
+			}
+			p.pos += 2
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+	return sb.String(), false
+}
+
+// parseUnicodeEscape parses \uXXXX at pos, including a following low surrogate, ok=false if truncated.
+func (p *partialJSONParser) parseUnicodeEscape(pos int) (r rune, n int, ok bool) {
+	hex := func(at int) (rune, bool) {
+		if at+6 > len(p.s) || p.s[at] != '\\' || p.s[at+1] != 'u' {
+			return 0, false
+		}
+		v, err := strconv.ParseUint(p.s[at+2:at+6], 16, 32)
+		if err != nil {
+			return unicode.ReplacementChar, true
+		}
+		return rune(v), true
+	}
+
+	r, ok = hex(pos)
+	if !ok {
+		return 0, 0, false
+	}
+	if !utf16.IsSurrogate(r) {
+		return r, 6, true
+	}
+	if pos+12 > len(p.s) {
+		if pos+6 < len(p.s) && p.s[pos+6] != '\\' {
+			return unicode.ReplacementChar, 6, true
+		}
+		return 0, 0, false
+	}
+	low, _ := hex(pos + 6)
+	return utf16.DecodeRune(r, low), 12, true
+}
+
+func (p *partialJSONParser) parseLiteral(path, literal string, value any) (any, bool, bool, error) {
+	rest := p.s[p.pos:]
+	if strings.HasPrefix(rest, literal) {
+		p.pos += len(literal)
+		p.final(path)
+		return value, true, true, nil
+	}
+	if strings.HasPrefix(literal, rest) {
+		// truncated literal
+		p.pos = len(p.s)
+		return nil, false, false, nil
+	}
+	return nil, false, false, fmt.Errorf("invalid literal at offset %d", p.pos)
+}
+
+func (p *partialJSONParser) parseNumber(path string) (any, bool, bool, error) {
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte("+-0123456789.eE", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+	num := p.s[start:p.pos]
+	valid := json.Valid([]byte(num))
+
+	if p.pos >= len(p.s) {
+		// the number may continue
+		if !valid {
+			return nil, false, false, nil
+		}
+		return json.Number(num), false, true, nil
+	}
+	if !valid {
+		return nil, false, false, fmt.Errorf("invalid number %q at offset %d", num, start)
+	}
+	p.final(path)
+	return json.Number(num), true, true, nil
+}
+
+func (p *partialJSONParser) skipWhitespace() {
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *partialJSONParser) final(path string) {
+	if path != "" {
+		p.finalPaths = append(p.finalPaths, path)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type partialStep struct {
+	Title string `json:"title"`
+	Done  bool   `json:"done"`
+}
+
+type partialPlan struct {
+	Name  string        `json:"name"`
+	Steps []partialStep `json:"steps"`
+	Score float64       `json:"score"`
+}
+
+func TestParsePartialJSON(t *testing.T) {
+	t.Run("truncated", func(t *testing.T) {
+		cases := []struct {
+			data       string
+			expected   *partialPlan
+			finalPaths []string
+		}{
+			{data: ``, expected: nil},
+			{data: `{`, expected: &partialPlan{}},
+			{data: `{"na`, expected: &partialPlan{}},
+			{data: `{"name"`, expected: &partialPlan{}},
+			{data: `{"name": "pl`, expected: &partialPlan{Name: "pl"}},
+			{data: `{"name": "a\"b\`, expected: &partialPlan{Name: `a"b`}},
+			{data: `{"name": "é\u00`, expected: &partialPlan{Name: "é"}},
+			{data: `{"name": "x", "steps": [{"title": "s1", "done": tr`,
+				expected:   &partialPlan{Name: "x", Steps: []partialStep{{Title: "s1"}}},
+				finalPaths: []string{"name", "steps[0].title"}},
+			{data: `{"name": "x", "steps": [{"title": "s1", "done": true}, {"ti`,
+				expected:   &partialPlan{Name: "x", Steps: []partialStep{{Title: "s1", Done: true}, {}}},
+				finalPaths: []string{"name", "steps[0].title", "steps[0].done", "steps[0]"}},
+			{data: `{"score": 1.`, expected: &partialPlan{}},
+			{data: `{"score": 1.5`, expected: &partialPlan{Score: 1.5}},
+			{data: `{"score": 1.5,`, expected: &partialPlan{Score: 1.5}, finalPaths: []string{"score"}},
+		}
+		for _, c := range cases {
+			result, err := ParsePartialJSON[partialPlan](c.data)
+			assert.NoError(t, err, c.data)
+			if c.expected == nil {
+				assert.Nil(t, result, c.data)
+				continue
+			}
+			assert.Equal(t, *c.expected, result.Value, c.data)
+			assert.Equal(t, c.finalPaths, result.FinalPaths, c.data)
+			assert.False(t, result.Final, c.data)
+		}
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		result, err := ParsePartialJSON[[]int](` [1, 2] `)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, result.Value)
+		assert.True(t, result.Final)
+		assert.Equal(t, []string{"[0]", "[1]"}, result.FinalPaths)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := ParsePartialJSON[partialPlan](`{"name" 1`)
+		assert.Error(t, err)
+		_, err = ParsePartialJSON[partialPlan](`{"name": tx`)
+		assert.Error(t, err)
+	})
+}
+
+func TestMessageJSONStreamParser(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("parse from content", func(t *testing.T) {
+		parser := NewMessageJSONStreamParser[partialPlan](nil)
+		sr, err := parser.ParseStream(ctx, StreamReaderFromArray([]*Message{
+			{Content: `{"name": "tr`},
+			{Content: `ip", "st`},
+			{Content: `eps": [{"title": "fly"}`},
+			{Content: ``},
+			{Content: `]}`},
+		}))
+		assert.NoError(t, err)
+
+		results, errs := recvAllWithErrors(sr)
+		assert.Empty(t, errs)
+		assert.Len(t, results, 4)
+		assert.Equal(t, partialPlan{Name: "tr"}, results[0].Value)
+		assert.Equal(t, partialPlan{Name: "trip"}, results[1].Value)
+		assert.Equal(t, []string{"name"}, results[1].FinalPaths)
+		assert.Equal(t, partialPlan{Name: "trip", Steps: []partialStep{{Title: "fly"}}}, results[2].Value)
+		assert.False(t, results[2].Final)
+		assert.Equal(t, partialPlan{Name: "trip", Steps: []partialStep{{Title: "fly"}}}, results[3].Value)
+		assert.True(t, results[3].Final)
+		assert.Equal(t, []string{"name", "steps[0].title", "steps[0]", "steps"}, results[3].FinalPaths)
+	})
+
+	t.Run("parse from tool call with key path", func(t *testing.T) {
+		idx0, idx1 := 0, 1
+		parser := NewMessageJSONStreamParser[[]partialStep](&MessageJSONParseConfig{
+			ParseFrom:    MessageParseFromToolCall,
+			ParseKeyPath: "plan.steps",
+		})
+		sr, err := parser.ParseStream(ctx, StreamReaderFromArray([]*Message{
+			{ToolCalls: []ToolCall{{Index: &idx0, Function: FunctionCall{Arguments: `{"plan": {"steps": [{"title": "a"}, `}}}},
+			{ToolCalls: []ToolCall{{Index: &idx1, Function: FunctionCall{Arguments: `{"other": 1}`}}}},
+			{ToolCalls: []ToolCall{{Index: &idx0, Function: FunctionCall{Arguments: `{"title": "b`}}}},
+			{ToolCalls: []ToolCall{{Index: &idx0, Function: FunctionCall{Arguments: `"}]}}`}}}},
+		}))
+		assert.NoError(t, err)
+
+		results, errs := recvAllWithErrors(sr)
+		assert.Empty(t, errs)
+		assert.Len(t, results, 3)
+		assert.Equal(t, []partialStep{{Title: "a"}}, results[0].Value)
+		assert.Equal(t, []string{"[0].title", "[0]"}, results[0].FinalPaths)
+		assert.Equal(t, []partialStep{{Title: "a"}, {Title: "b"}}, results[1].Value)
+		assert.False(t, results[1].Final)
+		assert.Equal(t, []partialStep{{Title: "a"}, {Title: "b"}}, results[2].Value)
+		assert.True(t, results[2].Final)
+		assert.Equal(t, []string{"[0].title", "[0]", "[1].title", "[1]"}, results[2].FinalPaths)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		sr, sw := Pipe[*Message](3)
+		sw.Send(&Message{Content: `{"name": "a`}, nil)
+		sw.Send(nil, errors.New("chunk error"))
+		sw.Send(&Message{Content: `"} trailing`}, nil)
+		sw.Close()
+
+		results, err := NewMessageJSONStreamParser[partialPlan](nil).ParseStream(ctx, sr)
+		assert.NoError(t, err)
+		values, errs := recvAllWithErrors(results)
+		assert.Len(t, values, 1)
+		assert.Len(t, errs, 2)
+		assert.EqualError(t, errs[0], "chunk error")
+
+		results, err = NewMessageJSONStreamParser[partialPlan](&MessageJSONParseConfig{ParseFrom: MessageParseFromToolCall}).
+			ParseStream(ctx, StreamReaderFromArray([]*Message{{Content: "{}"}}))
+		assert.NoError(t, err)
+		_, errs = recvAllWithErrors(results)
+		assert.Equal(t, []error{errors.New("no tool call found")}, errs)
+
+		_, err = (&MessageJSONStreamParser[partialPlan]{ParseFrom: "unknown"}).ParseStream(ctx, StreamReaderFromArray([]*Message{}))
+		assert.Error(t, err)
+	})
+}
@@ -588,6 +588,28 @@ func TestConcatMessage(t *testing.T) {
 
 		assert.Equal(t, expectedMultiContent, mergedMsg.MultiContent)
 	})
+
+	t.Run("concat user input multi content", func(t *testing.T) {
+		msgs := []*Message{
+			{
+				Role:                  Tool,
+				Content:               "chart",
+				UserInputMultiContent: []MessageInputPart{{Type: ChatMessagePartTypeText, Text: "chart"}},
+			},
+			{
+				Role:                  Tool,
+				UserInputMultiContent: []MessageInputPart{{Type: ChatMessagePartTypeImageURL, Image: &MessageInputImage{MessagePartCommon: MessagePartCommon{URL: generic.PtrOf("chart.png")}}}},
+			},
+		}
+
+		mergedMsg, err := ConcatMessages(msgs)
+		assert.NoError(t, err)
+		assert.Equal(t, "chart", mergedMsg.Content)
+		assert.Equal(t, []MessageInputPart{
+			{Type: ChatMessagePartTypeText, Text: "chart"},
+			{Type: ChatMessagePartTypeImageURL, Image: &MessageInputImage{MessagePartCommon: MessagePartCommon{URL: generic.PtrOf("chart.png")}}},
+		}, mergedMsg.UserInputMultiContent)
+	})
 }
 
 func TestConcatToolCalls(t *testing.T) {
@@ -0,0 +1,780 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MessageParseError is the error returned by the text parsers, with the raw text that fails to be parsed,
+// so that it can be logged or sent back to the model for correction.
+type MessageParseError struct {
+	// Parser is the kind of the parser, e.g. "xml_tag", "yaml", "regex", "enum", "list", "code_fence".
+	Parser string
+	// Raw is the text that fails to be parsed.
+	Raw string
+	Err error
+}
+
+func (e *MessageParseError) Error() string {
+	raw := e.Raw
+	if len(raw) > maxParseErrorRawLen {
+		raw = raw[:maxParseErrorRawLen] + "...(truncated)"
+	}
+	return fmt.Sprintf("failed to parse message with %s parser: %v, raw text: %q", e.Parser, e.Err, raw)
+}
+
+func (e *MessageParseError) Unwrap() error {
+	return e.Err
+}
+
+const maxParseErrorRawLen = 1024
+
+func newMessageParseError(parser, raw string, err error) error {
+	return &MessageParseError{Parser: parser, Raw: raw, Err: err}
+}
+
+// messageParseSource returns the text to be parsed, the content or the arguments of the first tool call.
+func messageParseSource(m *Message, from MessageParseFrom) (string, error) {
+	if m == nil {
+		return "", fmt.Errorf("message is nil")
+	}
+
+	switch from {
+	case "", MessageParseFromContent:
+		return m.Content, nil
+	case MessageParseFromToolCall:
+		if len(m.ToolCalls) == 0 {
+			return "", fmt.Errorf("no tool call found")
+		}
+		return m.ToolCalls[0].Function.Arguments, nil
+	default:
+		return "", fmt.Errorf("invalid parse from type: %s", from)
+	}
+}
+
+// MessageXMLTagParseConfig is the config for MessageXMLTagParser.
+type MessageXMLTagParseConfig struct {
+	// parse from content or tool call, default is content.
+	ParseFrom MessageParseFrom
+	// Tag is the tag whose inner text is parsed into T, e.g. "answer" for <answer>...</answer>.
+	// If empty, the whole text is parsed, which requires T to be a struct.
+	Tag string
+}
+
+// NewMessageXMLTagParser creates a new MessageXMLTagParser.
+func NewMessageXMLTagParser[T any](config *MessageXMLTagParseConfig) MessageParser[T] {
+	if config == nil {
+		config = &MessageXMLTagParseConfig{}
+	}
+
+	return &MessageXMLTagParser[T]{
+		ParseFrom: config.ParseFrom,
+		Tag:       config.Tag,
+	}
+}
+
+// MessageXMLTagParser extracts the text in xml-like tags, which models are often asked to use, e.g. <answer>42</answer>.
+// The text doesn't need to be valid xml, only the matched open and close tags are looked up.
+// Fields of struct T are filled from the tags named by the `xml` struct tag, or the field name if absent,
+// slice fields are filled from all the occurrences, struct fields are parsed from the inner text recursively,
+// and the others are converted from the trimmed inner text, e.g. numbers, bools and encoding.TextUnmarshaler.
+// Missing tags leave the fields unchanged, but it's an error if none of the fields is found.
+// e.g.
+//
+//	type Answer struct {
+//		Thinking string   `xml:"thinking"`
+//		Answer   int      `xml:"answer"`
+//		Sources  []string `xml:"source"`
+//	}
+//	parser := schema.NewMessageXMLTagParser[Answer](nil)
+//	answer, err := parser.Parse(ctx, message) // "<thinking>...</thinking><answer>42</answer><source>a</source><source>b</source>"
+type MessageXMLTagParser[T any] struct {
+	ParseFrom MessageParseFrom
+	Tag       string
+}
+
+// Parse parses a message into an object T.
+func (p *MessageXMLTagParser[T]) Parse(_ context.Context, m *Message) (parsed T, err error) {
+	text, err := messageParseSource(m, p.ParseFrom)
+	if err != nil {
+		return parsed, err
+	}
+
+	inner := text
+	if p.Tag != "" {
+		contents, err := findXMLTags(text, p.Tag)
+		if err != nil {
+			return parsed, newMessageParseError("xml_tag", text, err)
+		}
+		if len(contents) == 0 {
+			return parsed, newMessageParseError("xml_tag", text, fmt.Errorf("tag <%s> not found", p.Tag))
+		}
+		inner = contents[0]
+	}
+
+	rv := reflect.ValueOf(&parsed).Elem()
+	if p.Tag == "" && indirectType(rv.Type()).Kind() != reflect.Struct {
+		return parsed, fmt.Errorf("tag must be set to parse into %s", rv.Type())
+	}
+	if err = setValueFromXML(rv, inner); err != nil {
+		return parsed, newMessageParseError("xml_tag", text, err)
+	}
+	return parsed, nil
+}
+
+func setValueFromXML(v reflect.Value, text string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValueFromXML(v.Elem(), text)
+	}
+	if v.Kind() != reflect.Struct || isTextUnmarshaler(v) {
+		return setValueFromText(v, strings.TrimSpace(text))
+	}
+
+	found := false
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("xml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		contents, err := findXMLTags(text, name)
+		if err != nil {
+			return err
+		}
+		if len(contents) == 0 {
+			continue
+		}
+		found = true
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+			elems := reflect.MakeSlice(fv.Type(), len(contents), len(contents))
+			for j, content := range contents {
+				if err = setValueFromXML(elems.Index(j), content); err != nil {
+					return fmt.Errorf("field %s: %w", field.Name, err)
+				}
+			}
+			fv.Set(elems)
+			continue
+		}
+		if err = setValueFromXML(fv, contents[0]); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("none of the tags of %s is found", t)
+	}
+	return nil
+}
+
+// findXMLTags returns the inner text of all the top level <name ...>...</name> in text.
+func findXMLTags(text, name string) ([]string, error) {
+	var contents []string
+	open, closing := "<"+name, "</"+name+">"
+	for {
+		start := strings.Index(text, open)
+		if start < 0 {
+			return contents, nil
+		}
+		rest := text[start+len(open):]
+		if rest == "" || (rest[0] != '>' && !unicode.IsSpace(rune(rest[0]))) {
+			// e.g. <answers> when looking for <answer>
+			text = rest
+			continue
+		}
+		gt := strings.IndexByte(rest, '>')
+		if gt < 0 {
+			return nil, fmt.Errorf("tag <%s> is not closed", name)
+		}
+		rest = rest[gt+1:]
+		end := strings.Index(rest, closing)
+		if end < 0 {
+			return nil, fmt.Errorf("closing tag %s not found", closing)
+		}
+		contents = append(contents, rest[:end])
+		text = rest[end+len(closing):]
+	}
+}
+
+// MessageYAMLParseConfig is the config for MessageYAMLParser.
+type MessageYAMLParseConfig struct {
+	// parse from content or tool call, default is content.
+	ParseFrom MessageParseFrom
+	// parse key path, default is empty, eg: field.sub_field
+	ParseKeyPath string
+}
+
+// NewMessageYAMLParser creates a new MessageYAMLParser.
+func NewMessageYAMLParser[T any](config *MessageYAMLParseConfig) MessageParser[T] {
+	if config == nil {
+		config = &MessageYAMLParseConfig{}
+	}
+
+	return &MessageYAMLParser[T]{
+		ParseFrom:    config.ParseFrom,
+		ParseKeyPath: config.ParseKeyPath,
+	}
+}
+
+// MessageYAMLParser parses a message into an object T using yaml unmarshal, with the `yaml` struct tags.
+// If the text contains a markdown code block, e.g. ```yaml ... ```, only the code block is parsed.
+type MessageYAMLParser[T any] struct {
+	ParseFrom    MessageParseFrom
+	ParseKeyPath string
+}
+
+// Parse parses a message into an object T.
+func (p *MessageYAMLParser[T]) Parse(_ context.Context, m *Message) (parsed T, err error) {
+	text, err := messageParseSource(m, p.ParseFrom)
+	if err != nil {
+		return parsed, err
+	}
+
+	data := text
+	if block, ok := findCodeFence(text, "yaml", "yml"); ok {
+		data = block
+	}
+
+	node := &yaml.Node{}
+	if err = yaml.Unmarshal([]byte(data), node); err != nil {
+		return parsed, newMessageParseError("yaml", text, err)
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	if p.ParseKeyPath != "" {
+		for _, key := range strings.Split(p.ParseKeyPath, ".") {
+			if node = yamlMappingValue(node, key); node == nil {
+				return parsed, newMessageParseError("yaml", text, fmt.Errorf("failed to get parse key path: %s", p.ParseKeyPath))
+			}
+		}
+	}
+
+	if err = node.Decode(&parsed); err != nil {
+		return parsed, newMessageParseError("yaml", text, err)
+	}
+	return parsed, nil
+}
+
+func yamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// MessageRegexParseConfig is the config for MessageRegexParser.
+type MessageRegexParseConfig struct {
+	// parse from content or tool call, default is content.
+	ParseFrom MessageParseFrom
+	// Pattern is the regular expression, whose named groups are mapped to the fields of T, e.g. `Score:\s*(?P<score>\d+)`.
+	Pattern string
+}
+
+// NewMessageRegexParser creates a new MessageRegexParser, it returns an error if the pattern is invalid.
+func NewMessageRegexParser[T any](config *MessageRegexParseConfig) (MessageParser[T], error) {
+	if config == nil || config.Pattern == "" {
+		return nil, fmt.Errorf("regex pattern is required")
+	}
+
+	re, err := regexp.Compile(config.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex pattern: %w", err)
+	}
+
+	return &MessageRegexParser[T]{
+		ParseFrom: config.ParseFrom,
+		Regexp:    re,
+	}, nil
+}
+
+// MessageRegexParser extracts values with a regular expression.
+// If T is a struct, its fields are filled from the named groups, by the `regex` struct tag, or the field name case-insensitively if absent,
+// converted like MessageXMLTagParser. If T is a slice, each match is parsed into an element.
+// Otherwise, T is converted from the first group, or the whole match if there is no group.
+// It's an error if the regular expression doesn't match.
+// e.g.
+//
+//	type Review struct {
+//		Score  int    `regex:"score"`
+//		Reason string `regex:"reason"`
+//	}
+//	parser, err := schema.NewMessageRegexParser[Review](&schema.MessageRegexParseConfig{
+//		Pattern: `(?s)Score:\s*(?P<score>\d+).*Reason:\s*(?P<reason>.+)`,
+//	})
+type MessageRegexParser[T any] struct {
+	ParseFrom MessageParseFrom
+	Regexp    *regexp.Regexp
+}
+
+// Parse parses a message into an object T.
+func (p *MessageRegexParser[T]) Parse(_ context.Context, m *Message) (parsed T, err error) {
+	text, err := messageParseSource(m, p.ParseFrom)
+	if err != nil {
+		return parsed, err
+	}
+
+	rv := reflect.ValueOf(&parsed).Elem()
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+		matches := p.Regexp.FindAllStringSubmatch(text, -1)
+		if len(matches) == 0 {
+			return parsed, newMessageParseError("regex", text, fmt.Errorf("pattern %q doesn't match", p.Regexp))
+		}
+		elems := reflect.MakeSlice(rv.Type(), len(matches), len(matches))
+		for i, match := range matches {
+			if err = p.setValueFromMatch(elems.Index(i), match); err != nil {
+				return parsed, newMessageParseError("regex", text, err)
+			}
+		}
+		rv.Set(elems)
+		return parsed, nil
+	}
+
+	match := p.Regexp.FindStringSubmatch(text)
+	if match == nil {
+		return parsed, newMessageParseError("regex", text, fmt.Errorf("pattern %q doesn't match", p.Regexp))
+	}
+	if err = p.setValueFromMatch(rv, match); err != nil {
+		return parsed, newMessageParseError("regex", text, err)
+	}
+	return parsed, nil
+}
+
+func (p *MessageRegexParser[T]) setValueFromMatch(v reflect.Value, match []string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return p.setValueFromMatch(v.Elem(), match)
+	}
+
+	if v.Kind() != reflect.Struct || isTextUnmarshaler(v) {
+		text := match[0]
+		if len(match) > 1 {
+			text = match[1]
+		}
+		return setValueFromText(v, strings.TrimSpace(text))
+	}
+
+	names := p.Regexp.SubexpNames()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Tag.Get("regex")
+		if name == "-" {
+			continue
+		}
+
+		for j, group := range names {
+			if group == "" || j >= len(match) {
+				continue
+			}
+			if group == name || (name == "" && strings.EqualFold(group, field.Name)) {
+				if err := setValueFromText(v.Field(i), strings.TrimSpace(match[j])); err != nil {
+					return fmt.Errorf("field %s: %w", field.Name, err)
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// MessageEnumParseConfig is the config for MessageEnumParser.
+type MessageEnumParseConfig[T ~string] struct {
+	// parse from content or tool call, default is content.
+	ParseFrom MessageParseFrom
+	// Values are the allowed labels.
+	Values []T
+	// MaxDistance is the max edit distance for fuzzy matching, 0 means no fuzzy matching.
+	MaxDistance int
+}
+
+// NewMessageEnumParser creates a new MessageEnumParser.
+func NewMessageEnumParser[T ~string](config *MessageEnumParseConfig[T]) (MessageParser[T], error) {
+	if config == nil || len(config.Values) == 0 {
+		return nil, fmt.Errorf("enum values are required")
+	}
+
+	return &MessageEnumParser[T]{
+		ParseFrom:   config.ParseFrom,
+		Values:      config.Values,
+		MaxDistance: config.MaxDistance,
+	}, nil
+}
+
+// MessageEnumParser parses a message into one of the allowed labels, e.g. for classification.
+// Labels are compared case-insensitively, ignoring the surrounding spaces, quotes and punctuation, e.g. "**Positive**." matches "positive".
+// If the text doesn't equal any label, the only label that appears in the text as a whole word is chosen, e.g. "The answer is positive".
+// Otherwise, if MaxDistance > 0, the label with the smallest edit distance within MaxDistance is chosen, e.g. "postive".
+// It's an error if no label or more than one label matches.
+type MessageEnumParser[T ~string] struct {
+	ParseFrom   MessageParseFrom
+	Values      []T
+	MaxDistance int
+}
+
+// Parse parses a message into one of the Values.
+func (p *MessageEnumParser[T]) Parse(_ context.Context, m *Message) (parsed T, err error) {
+	text, err := messageParseSource(m, p.ParseFrom)
+	if err != nil {
+		return parsed, err
+	}
+
+	normalized := normalizeLabel(text)
+	for _, v := range p.Values {
+		if normalizeLabel(string(v)) == normalized {
+			return v, nil
+		}
+	}
+
+	var found []T
+	lower := strings.ToLower(text)
+	for _, v := range p.Values {
+		if label := normalizeLabel(string(v)); label != "" && containsWord(lower, label) {
+			found = append(found, v)
+		}
+	}
+	if len(found) == 1 {
+		return found[0], nil
+	}
+	if len(found) > 1 {
+		return parsed, newMessageParseError("enum", text, fmt.Errorf("ambiguous labels: %v", found))
+	}
+
+	if p.MaxDistance > 0 {
+		best, bestDistance, tie := -1, p.MaxDistance+1, false
+		for i, v := range p.Values {
+			d := editDistance(normalized, normalizeLabel(string(v)))
+			if d < bestDistance {
+				best, bestDistance, tie = i, d, false
+			} else if d == bestDistance {
+				tie = true
+			}
+		}
+		if best >= 0 && !tie {
+			return p.Values[best], nil
+		}
+	}
+
+	return parsed, newMessageParseError("enum", text, fmt.Errorf("no label of %v matches", p.Values))
+}
+
+func normalizeLabel(s string) string {
+	return strings.ToLower(strings.TrimFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+	}))
+}
+
+// containsWord reports whether word appears in text not adjacent to other letters or digits.
+func containsWord(text, word string) bool {
+	isWordRune := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], word)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(word)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(minInt(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// MessageListParseConfig is the config for MessageListParser.
+type MessageListParseConfig struct {
+	// parse from content or tool call, default is content.
+	ParseFrom MessageParseFrom
+}
+
+// NewMessageListParser creates a new MessageListParser.
+func NewMessageListParser(config *MessageListParseConfig) MessageParser[[]string] {
+	if config == nil {
+		config = &MessageListParseConfig{}
+	}
+
+	return &MessageListParser{
+		ParseFrom: config.ParseFrom,
+	}
+}
+
+// MessageListParser parses a numbered or bulleted list into the items, e.g. "1. a\n2) b" or "- a\n* b\n• c".
+// Lines not starting with a marker, e.g. the intro sentence, are ignored, and a line that is indented
+// more than the item is appended to the item. If no line has a marker, each non-empty line is an item.
+// It's an error if there is no item.
+type MessageListParser struct {
+	ParseFrom MessageParseFrom
+}
+
+var listMarkerRegexp = regexp.MustCompile(`^(\s*)(?:\d+[.)]|[-*+•])\s+(.*)$`)
+
+// Parse parses a message into the list items.
+func (p *MessageListParser) Parse(_ context.Context, m *Message) ([]string, error) {
+	text, err := messageParseSource(m, p.ParseFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []string
+	indent := -1
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if match := listMarkerRegexp.FindStringSubmatch(line); match != nil && (indent < 0 || len(match[1]) <= indent) {
+			indent = len(match[1])
+			items = append(items, strings.TrimSpace(match[2]))
+			continue
+		}
+		if len(items) > 0 && strings.TrimSpace(line) != "" && len(line)-len(strings.TrimLeft(line, " \t")) > indent {
+			items[len(items)-1] += " " + strings.TrimSpace(line)
+		}
+	}
+
+	if len(items) == 0 {
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				items = append(items, line)
+			}
+		}
+	}
+	if len(items) == 0 {
+		return nil, newMessageParseError("list", text, errors.New("no list item found"))
+	}
+	return items, nil
+}
+
+// MessageCodeFenceParseConfig is the config for MessageCodeFenceParser.
+type MessageCodeFenceParseConfig struct {
+	// parse from content or tool call, default is content.
+	ParseFrom MessageParseFrom
+	// Languages are the accepted info strings of the code block, e.g. "python", "py".
+	// If empty, the first code block is returned regardless of its language.
+	Languages []string
+}
+
+// NewMessageCodeFenceParser creates a new MessageCodeFenceParser.
+func NewMessageCodeFenceParser(config *MessageCodeFenceParseConfig) MessageParser[string] {
+	if config == nil {
+		config = &MessageCodeFenceParseConfig{}
+	}
+
+	return &MessageCodeFenceParser{
+		ParseFrom: config.ParseFrom,
+		Languages: config.Languages,
+	}
+}
+
+// MessageCodeFenceParser extracts the code of the first markdown code block, fenced by ``` or ~~~, of the languages.
+// An unclosed code block, e.g. the output is truncated, is extracted till the end of the text.
+// It's an error if no code block is found.
+type MessageCodeFenceParser struct {
+	ParseFrom MessageParseFrom
+	Languages []string
+}
+
+// Parse parses a message into the code.
+func (p *MessageCodeFenceParser) Parse(_ context.Context, m *Message) (string, error) {
+	text, err := messageParseSource(m, p.ParseFrom)
+	if err != nil {
+		return "", err
+	}
+
+	code, ok := findCodeFence(text, p.Languages...)
+	if !ok {
+		return "", newMessageParseError("code_fence", text, fmt.Errorf("no code block of %v found", p.Languages))
+	}
+	return code, nil
+}
+
+// findCodeFence returns the code of the first fenced code block whose language is one of languages, or any if languages is empty.
+func findCodeFence(text string, languages ...string) (string, bool) {
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(trimmed, "```") && !strings.HasPrefix(trimmed, "~~~") {
+			continue
+		}
+		fenceChar := trimmed[0]
+		fenceLen := len(trimmed) - len(strings.TrimLeft(trimmed, string(fenceChar)))
+		fence := trimmed[:fenceLen]
+		info := strings.Fields(strings.TrimSpace(trimmed[fenceLen:]))
+
+		end := len(lines)
+		for j := i + 1; j < len(lines); j++ {
+			if strings.HasPrefix(strings.TrimSpace(lines[j]), fence) && strings.Trim(strings.TrimSpace(lines[j]), string(fenceChar)) == "" {
+				end = j
+				break
+			}
+		}
+
+		if matchLanguage(info, languages) {
+			if i+1 >= end {
+				return "", true
+			}
+			return strings.TrimRight(strings.Join(lines[i+1:end], "\n"), "\r"), true
+		}
+		i = end
+	}
+	return "", false
+}
+
+func matchLanguage(info []string, languages []string) bool {
+	if len(languages) == 0 {
+		return true
+	}
+	if len(info) == 0 {
+		return false
+	}
+	for _, l := range languages {
+		if strings.EqualFold(info[0], l) {
+			return true
+		}
+	}
+	return false
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+func isTextUnmarshaler(v reflect.Value) bool {
+	return v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType)
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// setValueFromText converts text into v, for strings, numbers, bools, pointers of them and encoding.TextUnmarshaler.
+func setValueFromText(v reflect.Value, text string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValueFromText(v.Elem(), text)
+	}
+	if isTextUnmarshaler(v) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		switch strings.ToLower(text) {
+		case "true", "yes", "y", "1":
+			v.SetBool(true)
+		case "false", "no", "n", "0":
+			v.SetBool(false)
+		default:
+			return fmt.Errorf("invalid bool %q", text)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid int %q: %w", text, err)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid uint %q: %w", text, err)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(text, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid float %q: %w", text, err)
+		}
+		v.SetFloat(n)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		v.Set(reflect.ValueOf(text))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
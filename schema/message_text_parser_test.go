@@ -0,0 +1,226 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageXMLTagParser(t *testing.T) {
+	ctx := context.Background()
+
+	type source struct {
+		URL   string  `xml:"url"`
+		Score float64 `xml:"score"`
+	}
+	type answer struct {
+		Thinking string   `xml:"thinking"`
+		Answer   int      `xml:"answer"`
+		Sure     *bool    `xml:"sure"`
+		Tags     []string `xml:"tag"`
+		Sources  []source `xml:"source"`
+		Ignored  string   `xml:"-"`
+		Missing  string
+	}
+
+	t.Run("struct", func(t *testing.T) {
+		parser := NewMessageXMLTagParser[answer](nil)
+		parsed, err := parser.Parse(ctx, &Message{Content: `Let me think.
+<thinking>
+  6 * 7
+</thinking>
+<answers>ignored</answers>
+<answer>42</answer><sure>yes</sure>
+<tag>math</tag><tag>easy</tag>
+<source id="1"><url>a.com</url><score>0.5</score></source>`})
+		assert.NoError(t, err)
+		assert.Equal(t, "6 * 7", parsed.Thinking)
+		assert.Equal(t, 42, parsed.Answer)
+		assert.True(t, *parsed.Sure)
+		assert.Equal(t, []string{"math", "easy"}, parsed.Tags)
+		assert.Equal(t, []source{{URL: "a.com", Score: 0.5}}, parsed.Sources)
+	})
+
+	t.Run("single tag", func(t *testing.T) {
+		parser := NewMessageXMLTagParser[string](&MessageXMLTagParseConfig{Tag: "answer"})
+		parsed, err := parser.Parse(ctx, &Message{Content: "<answer> Paris </answer>"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Paris", parsed)
+
+		_, err = parser.Parse(ctx, &Message{Content: "Paris"})
+		var parseErr *MessageParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "Paris", parseErr.Raw)
+		assert.Contains(t, err.Error(), `raw text: "Paris"`)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		parser := NewMessageXMLTagParser[answer](nil)
+		_, err := parser.Parse(ctx, &Message{Content: "<answer>forty two</answer>"})
+		assert.ErrorContains(t, err, "field Answer")
+		_, err = parser.Parse(ctx, &Message{Content: "<answer>42"})
+		assert.ErrorContains(t, err, "closing tag </answer> not found")
+		_, err = parser.Parse(ctx, &Message{Content: "nothing"})
+		assert.ErrorContains(t, err, "none of the tags")
+
+		_, err = NewMessageXMLTagParser[string](nil).Parse(ctx, &Message{Content: "x"})
+		assert.Error(t, err)
+	})
+}
+
+func TestMessageYAMLParser(t *testing.T) {
+	ctx := context.Background()
+
+	type config struct {
+		Name  string   `yaml:"name"`
+		Items []string `yaml:"items"`
+	}
+
+	parser := NewMessageYAMLParser[config](nil)
+	parsed, err := parser.Parse(ctx, &Message{Content: "Here it is:\n```yaml\nname: a\nitems:\n  - x\n  - y\n```\nDone."})
+	assert.NoError(t, err)
+	assert.Equal(t, config{Name: "a", Items: []string{"x", "y"}}, parsed)
+
+	parsed, err = parser.Parse(ctx, &Message{Content: "name: b"})
+	assert.NoError(t, err)
+	assert.Equal(t, config{Name: "b"}, parsed)
+
+	items, err := NewMessageYAMLParser[[]string](&MessageYAMLParseConfig{
+		ParseFrom:    MessageParseFromToolCall,
+		ParseKeyPath: "result.items",
+	}).Parse(ctx, &Message{ToolCalls: []ToolCall{{Function: FunctionCall{Arguments: `{"result": {"items": ["x"]}}`}}}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x"}, items)
+
+	_, err = parser.Parse(ctx, &Message{Content: "name: [a"})
+	var parseErr *MessageParseError
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, "name: [a", parseErr.Raw)
+
+	_, err = NewMessageYAMLParser[config](&MessageYAMLParseConfig{ParseKeyPath: "x"}).Parse(ctx, &Message{Content: "name: b"})
+	assert.ErrorContains(t, err, "failed to get parse key path")
+}
+
+func TestMessageRegexParser(t *testing.T) {
+	ctx := context.Background()
+
+	type review struct {
+		Score  int `regex:"score"`
+		Reason string
+	}
+
+	parser, err := NewMessageRegexParser[review](&MessageRegexParseConfig{
+		Pattern: `(?s)Score:\s*(?P<score>\d+).*Reason:\s*(?P<reason>.+)`,
+	})
+	assert.NoError(t, err)
+	parsed, err := parser.Parse(ctx, &Message{Content: "Score: 8\nReason: clear and short "})
+	assert.NoError(t, err)
+	assert.Equal(t, review{Score: 8, Reason: "clear and short"}, parsed)
+
+	_, err = parser.Parse(ctx, &Message{Content: "no score"})
+	assert.ErrorContains(t, err, "doesn't match")
+
+	list, err := NewMessageRegexParser[[]int](&MessageRegexParseConfig{Pattern: `#(\d+)`})
+	assert.NoError(t, err)
+	ids, err := list.Parse(ctx, &Message{Content: "see #12 and #34"})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{12, 34}, ids)
+
+	_, err = NewMessageRegexParser[string](&MessageRegexParseConfig{Pattern: `(`})
+	assert.Error(t, err)
+	_, err = NewMessageRegexParser[string](nil)
+	assert.Error(t, err)
+}
+
+func TestMessageEnumParser(t *testing.T) {
+	ctx := context.Background()
+
+	type sentiment string
+	parser, err := NewMessageEnumParser(&MessageEnumParseConfig[sentiment]{
+		Values:      []sentiment{"positive", "negative", "neutral"},
+		MaxDistance: 2,
+	})
+	assert.NoError(t, err)
+
+	cases := map[string]sentiment{
+		"positive":                       "positive",
+		" **Negative**.":                 "negative",
+		"The sentiment is neutral here.": "neutral",
+		"postive":                        "positive",
+	}
+	for text, expected := range cases {
+		parsed, err := parser.Parse(ctx, &Message{Content: text})
+		assert.NoError(t, err, text)
+		assert.Equal(t, expected, parsed, text)
+	}
+
+	_, err = parser.Parse(ctx, &Message{Content: "positive or negative"})
+	assert.ErrorContains(t, err, "ambiguous")
+	_, err = parser.Parse(ctx, &Message{Content: "unknown"})
+	assert.ErrorContains(t, err, "no label")
+	_, err = parser.Parse(ctx, &Message{Content: "nonpositive"})
+	assert.ErrorContains(t, err, "no label")
+
+	_, err = NewMessageEnumParser[sentiment](nil)
+	assert.Error(t, err)
+}
+
+func TestMessageListParser(t *testing.T) {
+	ctx := context.Background()
+	parser := NewMessageListParser(nil)
+
+	items, err := parser.Parse(ctx, &Message{Content: "Steps:\n1. search docs\n   in the repo\n2) write code\n\n- run tests\n"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"search docs in the repo", "write code", "run tests"}, items)
+
+	items, err = parser.Parse(ctx, &Message{Content: "* a\n  - a.1\n* b"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a - a.1", "b"}, items)
+
+	items, err = parser.Parse(ctx, &Message{Content: "apple\n\nbanana"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"apple", "banana"}, items)
+
+	_, err = parser.Parse(ctx, &Message{Content: " \n"})
+	assert.ErrorContains(t, err, "no list item")
+}
+
+func TestMessageCodeFenceParser(t *testing.T) {
+	ctx := context.Background()
+	text := "Try:\n```bash\nls\n```\n\n~~~python\nprint(1)\nprint(2)\n~~~\n```py\nx"
+
+	code, err := NewMessageCodeFenceParser(nil).Parse(ctx, &Message{Content: text})
+	assert.NoError(t, err)
+	assert.Equal(t, "ls", code)
+
+	code, err = NewMessageCodeFenceParser(&MessageCodeFenceParseConfig{Languages: []string{"Python"}}).Parse(ctx, &Message{Content: text})
+	assert.NoError(t, err)
+	assert.Equal(t, "print(1)\nprint(2)", code)
+
+	code, err = NewMessageCodeFenceParser(&MessageCodeFenceParseConfig{Languages: []string{"py"}}).Parse(ctx, &Message{Content: text})
+	assert.NoError(t, err)
+	assert.Equal(t, "x", code)
+
+	_, err = NewMessageCodeFenceParser(&MessageCodeFenceParseConfig{Languages: []string{"go"}}).Parse(ctx, &Message{Content: text})
+	var parseErr *MessageParseError
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, text, parseErr.Raw)
+}
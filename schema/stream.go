@@ -168,6 +168,9 @@ type StreamReader[T any] struct {
 	srw *streamReaderWithConvert[T]
 
 	csr *childStreamReader[T]
+
+	// pending is the result of the Recv left by a canceled RecvContext.
+	pending chan streamItem[T]
 }
 
 // Recv receives a value from the stream.
@@ -181,20 +184,11 @@ type StreamReader[T any] struct {
 //		fmt.Println(chunk)
 //	}
 func (sr *StreamReader[T]) Recv() (T, error) {
-	switch sr.typ {
-	case readerTypeStream:
-		return sr.st.recv()
-	case readerTypeArray:
-		return sr.ar.recv()
-	case readerTypeMultiStream:
-		return sr.msr.recv()
-	case readerTypeWithConvert:
-		return sr.srw.recv()
-	case readerTypeChild:
-		return sr.csr.recv()
-	default:
-		panic("impossible")
+	if sr.pending != nil {
+		return sr.recvPending()
 	}
+
+	return sr.recvByType(sr.typ)
 }
 
 // Close safely closes the StreamReader.
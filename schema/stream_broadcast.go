@@ -0,0 +1,330 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"errors"
+	"io"
+	"runtime/debug"
+	"sync"
+
+	"github.com/cloudwego/eino/internal/safe"
+)
+
+// ErrBroadcastOverflow is received by a subscriber of a Broadcaster with BroadcastPolicyError,
+// when the subscriber falls behind the live stream by more than the buffer size. The subscriber ends after this error.
+var ErrBroadcastOverflow = errors.New("broadcast subscriber overflowed")
+
+// BroadcastPolicy decides what happens when a subscriber falls behind the live stream by more than the buffer size.
+type BroadcastPolicy uint8
+
+const (
+	// BroadcastPolicyBlock makes the Broadcaster stop receiving from the source until the slowest subscriber catches up.
+	BroadcastPolicyBlock BroadcastPolicy = iota
+	// BroadcastPolicyDropOldest makes the slow subscriber skip the oldest chunks it has not received.
+	BroadcastPolicyDropOldest
+	// BroadcastPolicyError makes the slow subscriber receive ErrBroadcastOverflow and end.
+	BroadcastPolicyError
+)
+
+type broadcastOptions struct {
+	bufferSize  int
+	historySize int
+	policy      BroadcastPolicy
+}
+
+// BroadcastOption is the option for NewBroadcaster.
+type BroadcastOption func(o *broadcastOptions)
+
+// WithBroadcastBufferSize sets how many chunks a subscriber can fall behind the live stream, 64 by default.
+func WithBroadcastBufferSize(size int) BroadcastOption {
+	return func(o *broadcastOptions) {
+		o.bufferSize = size
+	}
+}
+
+// WithBroadcastHistorySize sets how many latest chunks are kept for replaying to new subscribers, 0 by default.
+// The chunks still buffered for the live subscribers, at most the buffer size, are always kept and replayed,
+// so the history only takes effect when it's larger than the buffer size.
+// A negative size keeps all chunks, whose memory grows without bound for long streams.
+func WithBroadcastHistorySize(size int) BroadcastOption {
+	return func(o *broadcastOptions) {
+		o.historySize = size
+	}
+}
+
+// WithBroadcastPolicy sets the policy for the slow subscribers, BroadcastPolicyBlock by default.
+func WithBroadcastPolicy(policy BroadcastPolicy) BroadcastOption {
+	return func(o *broadcastOptions) {
+		o.policy = policy
+	}
+}
+
+// Broadcaster fans a live stream out to any number of subscribers, which can subscribe at any time.
+// Unlike StreamReader.Copy, the number of consumers is not required up front.
+// e.g.
+//
+//	b := schema.NewBroadcaster(agentOutput)
+//	defer b.Close()
+//
+//	ui := b.Subscribe(false)     // starts at the live tail
+//	// ... later
+//	audit := b.Subscribe(true)   // replays the chunks it missed first
+//
+// Every subscriber is a StreamReader, which should be closed or received until io.EOF.
+// Errors of the source, including SourceEOF, are broadcast as chunks.
+type Broadcaster[T any] struct {
+	sr   *StreamReader[T]
+	opts broadcastOptions
+
+	mu   sync.Mutex
+	cond *sync.Cond // signaled when chunks are published, the source ends, or subscribers make progress
+
+	log   []streamItem[T]
+	base  int // absolute index of log[0]
+	ended bool
+
+	subs map[*broadcastSubscriber[T]]struct{}
+
+	closeOnce sync.Once
+}
+
+// NewBroadcaster starts receiving from sr in a new goroutine and broadcasting the chunks.
+// sr is closed when it reaches io.EOF or the Broadcaster is closed.
+func NewBroadcaster[T any](sr *StreamReader[T], opts ...BroadcastOption) *Broadcaster[T] {
+	o := broadcastOptions{
+		bufferSize: 64,
+		policy:     BroadcastPolicyBlock,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.bufferSize < 1 {
+		o.bufferSize = 1
+	}
+
+	b := &Broadcaster[T]{
+		sr:   sr,
+		opts: o,
+		subs: make(map[*broadcastSubscriber[T]]struct{}),
+	}
+	b.cond = sync.NewCond(&b.mu)
+
+	go b.run()
+
+	return b
+}
+
+// Subscribe returns a StreamReader receiving the chunks of the Broadcaster.
+// If replay is true, the subscriber receives the kept history chunks first, otherwise it starts at the live tail.
+// Subscribing after the source ended or the Broadcaster is closed is allowed, the subscriber receives the history if replay is true, and then io.EOF.
+func (b *Broadcaster[T]) Subscribe(replay bool) *StreamReader[T] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	head := b.base + len(b.log)
+	s := &broadcastSubscriber[T]{
+		b:     b,
+		next:  head,
+		start: head,
+	}
+	if replay {
+		s.next = b.base
+	}
+	b.subs[s] = struct{}{}
+
+	return s.asReader()
+}
+
+// Close stops broadcasting and closes the source.
+// Subscribers receive the chunks already broadcast and then io.EOF.
+func (b *Broadcaster[T]) Close() {
+	b.mu.Lock()
+	b.ended = true
+	b.cond.Broadcast()
+	b.mu.Unlock()
+
+	if b.sr.closableConcurrently() {
+		b.closeSource()
+	}
+}
+
+func (b *Broadcaster[T]) closeSource() {
+	b.closeOnce.Do(b.sr.Close)
+}
+
+func (b *Broadcaster[T]) run() {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			b.publish(streamItem[T]{err: safe.NewPanicErr(panicErr, debug.Stack())})
+		}
+
+		b.mu.Lock()
+		b.ended = true
+		b.cond.Broadcast()
+		b.mu.Unlock()
+
+		b.closeSource()
+	}()
+
+	for {
+		chunk, err := b.sr.Recv()
+		if err == io.EOF {
+			return
+		}
+		if !b.publish(streamItem[T]{chunk: chunk, err: err}) {
+			return
+		}
+	}
+}
+
+// publish returns false if the Broadcaster has been closed.
+func (b *Broadcaster[T]) publish(item streamItem[T]) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.opts.policy == BroadcastPolicyBlock {
+		for !b.ended && b.hasFullSubscriber() {
+			b.cond.Wait()
+		}
+	}
+	if b.ended {
+		return false
+	}
+
+	b.log = append(b.log, item)
+
+	keep := b.opts.bufferSize
+	if b.opts.historySize < 0 {
+		keep = len(b.log)
+	} else if b.opts.historySize > keep {
+		keep = b.opts.historySize
+	}
+	if trim := len(b.log) - keep; trim > 0 {
+		var zero streamItem[T]
+		for i := 0; i < trim; i++ {
+			b.log[i] = zero
+		}
+		b.log = b.log[trim:]
+		b.base += trim
+	}
+
+	b.cond.Broadcast()
+	return true
+}
+
+func (b *Broadcaster[T]) hasFullSubscriber() bool {
+	head := b.base + len(b.log)
+	for s := range b.subs {
+		if s.lag(head) >= b.opts.bufferSize {
+			return true
+		}
+	}
+	return false
+}
+
+type broadcastSubscriber[T any] struct {
+	b *Broadcaster[T]
+
+	next  int // absolute index of the next chunk to receive
+	start int // absolute index of the live tail when subscribing
+
+	overflowed bool
+	closed     bool
+}
+
+func (s *broadcastSubscriber[T]) asReader() *StreamReader[T] {
+	return newStreamReaderWithConvert[T](s, func(a any) (T, error) {
+		t, _ := a.(T)
+		return t, nil
+	})
+}
+
+// lag is the number of live chunks the subscriber has not received, the replayed history is not counted.
+func (s *broadcastSubscriber[T]) lag(head int) int {
+	if s.next > s.start {
+		return head - s.next
+	}
+	return head - s.start
+}
+
+func (s *broadcastSubscriber[T]) recvAny() (any, error) {
+	b := s.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for {
+		if s.closed {
+			return nil, ErrRecvAfterClosed
+		}
+		if s.overflowed {
+			return nil, io.EOF
+		}
+
+		// check the lag before skipping the dropped chunks, which count as lagging if they are live ones
+		head := b.base + len(b.log)
+		if lag := s.lag(head); lag > b.opts.bufferSize {
+			switch b.opts.policy {
+			case BroadcastPolicyDropOldest:
+				s.next = head - b.opts.bufferSize
+			case BroadcastPolicyError:
+				s.overflowed = true
+				delete(b.subs, s)
+				return nil, ErrBroadcastOverflow
+			default:
+			}
+		}
+		if s.next < b.base {
+			// the history has been dropped
+			s.next = b.base
+		}
+
+		if s.next < head {
+			item := b.log[s.next-b.base]
+			s.next++
+			if b.opts.policy == BroadcastPolicyBlock {
+				b.cond.Broadcast()
+			}
+			return item.chunk, item.err
+		}
+		if b.ended {
+			return nil, io.EOF
+		}
+
+		b.cond.Wait()
+	}
+}
+
+func (s *broadcastSubscriber[T]) copyAny(n int) []iStreamReader {
+	return s.asReader().copyAny(n)
+}
+
+func (s *broadcastSubscriber[T]) Close() {
+	b := s.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s.closed = true
+	delete(b.subs, s)
+	b.cond.Broadcast()
+}
+
+func (s *broadcastSubscriber[T]) SetAutomaticClose() {}
+
+func (s *broadcastSubscriber[T]) closableConcurrently() bool {
+	return true
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroadcaster(t *testing.T) {
+	t.Run("late subscribers", func(t *testing.T) {
+		sr, sw := Pipe[int](0)
+		b := NewBroadcaster(sr)
+		defer b.Close()
+
+		first := b.Subscribe(false)
+		sw.Send(1, nil)
+		sw.Send(2, nil)
+		chunk, err := first.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, chunk)
+		chunk, err = first.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, chunk)
+
+		live := b.Subscribe(false)
+		replay := b.Subscribe(true)
+		sw.Send(3, nil)
+		sw.Send(0, &SourceEOF{sourceName: "s"})
+		sw.Close()
+
+		chunks, errs := recvAllWithErrors(first)
+		assert.Equal(t, []int{3}, chunks)
+		assert.Len(t, errs, 1)
+		chunks, errs = recvAllWithErrors(live)
+		assert.Equal(t, []int{3}, chunks)
+		assert.Len(t, errs, 1)
+		chunks, errs = recvAllWithErrors(replay)
+		assert.Equal(t, []int{1, 2, 3}, chunks)
+		name, ok := GetSourceName(errs[0])
+		assert.True(t, ok)
+		assert.Equal(t, "s", name)
+
+		// subscribe after the source ended
+		chunks, _ = recvAllWithErrors(b.Subscribe(true))
+		assert.Equal(t, []int{1, 2, 3}, chunks)
+		chunks, _ = recvAllWithErrors(b.Subscribe(false))
+		assert.Empty(t, chunks)
+	})
+
+	t.Run("block", func(t *testing.T) {
+		sr, sw := Pipe[int](0)
+		b := NewBroadcaster(sr, WithBroadcastBufferSize(2))
+		defer b.Close()
+		fast := b.Subscribe(false)
+		slow := b.Subscribe(false)
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		var fastChunks []int
+		go func() {
+			defer wg.Done()
+			fastChunks, _ = recvAllWithErrors(fast)
+		}()
+		go func() {
+			for i := 0; i < 10; i++ {
+				sw.Send(i, nil)
+			}
+			sw.Close()
+		}()
+
+		slowChunks, _ := recvAllWithErrors(slow)
+		wg.Wait()
+		assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, slowChunks)
+		assert.Equal(t, slowChunks, fastChunks)
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		b := NewBroadcaster(StreamReaderFromArray([]int{0, 1, 2, 3, 4, 5}), WithBroadcastBufferSize(2),
+			WithBroadcastPolicy(BroadcastPolicyDropOldest), WithBroadcastHistorySize(0))
+		defer b.Close()
+		slow := b.Subscribe(true)
+		waitBroadcastEnd(b)
+
+		chunks, _ := recvAllWithErrors(slow)
+		assert.Equal(t, []int{4, 5}, chunks)
+	})
+
+	t.Run("bounded history by default", func(t *testing.T) {
+		b := NewBroadcaster(StreamReaderFromArray([]int{0, 1, 2, 3, 4}), WithBroadcastBufferSize(2),
+			WithBroadcastPolicy(BroadcastPolicyDropOldest))
+		defer b.Close()
+		waitBroadcastEnd(b)
+
+		chunks, _ := recvAllWithErrors(b.Subscribe(true))
+		assert.Equal(t, []int{3, 4}, chunks)
+		assert.Len(t, b.log, 2)
+	})
+
+	t.Run("unbounded history", func(t *testing.T) {
+		b := NewBroadcaster(StreamReaderFromArray([]int{0, 1, 2, 3, 4}), WithBroadcastBufferSize(2),
+			WithBroadcastPolicy(BroadcastPolicyDropOldest), WithBroadcastHistorySize(-1))
+		defer b.Close()
+		waitBroadcastEnd(b)
+
+		chunks, _ := recvAllWithErrors(b.Subscribe(true))
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, chunks)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		sr, sw := Pipe[int](0)
+		b := NewBroadcaster(sr, WithBroadcastBufferSize(2), WithBroadcastPolicy(BroadcastPolicyError),
+			WithBroadcastHistorySize(5))
+		defer b.Close()
+		slow := b.Subscribe(false)
+		for i := 0; i < 5; i++ {
+			sw.Send(i, nil)
+		}
+		sw.Close()
+		waitBroadcastEnd(b)
+
+		_, err := slow.Recv()
+		assert.True(t, errors.Is(err, ErrBroadcastOverflow))
+		_, err = slow.Recv()
+		assert.Equal(t, io.EOF, err)
+
+		// replayed history doesn't count as lagging
+		chunks, _ := recvAllWithErrors(b.Subscribe(true))
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, chunks)
+	})
+
+	t.Run("error with default history", func(t *testing.T) {
+		sr, sw := Pipe[int](0)
+		b := NewBroadcaster(sr, WithBroadcastBufferSize(2), WithBroadcastPolicy(BroadcastPolicyError))
+		defer b.Close()
+		slow := b.Subscribe(false)
+		for i := 0; i < 5; i++ {
+			sw.Send(i, nil)
+		}
+		sw.Close()
+		waitBroadcastEnd(b)
+
+		_, err := slow.Recv()
+		assert.True(t, errors.Is(err, ErrBroadcastOverflow))
+		_, err = slow.Recv()
+		assert.Equal(t, io.EOF, err)
+
+		chunks, _ := recvAllWithErrors(b.Subscribe(true))
+		assert.Equal(t, []int{3, 4}, chunks)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		sr, sw := Pipe[int](0)
+		b := NewBroadcaster(sr)
+		sub := b.Subscribe(false)
+		sw.Send(1, nil)
+		chunk, err := sub.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, chunk)
+		b.Close()
+
+		_, err = sub.Recv()
+		assert.Equal(t, io.EOF, err)
+		// the source is closed
+		assert.True(t, sw.Send(2, nil))
+	})
+}
+
+func waitBroadcastEnd[T any](b *Broadcaster[T]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for !b.ended {
+		b.cond.Wait()
+	}
+}
@@ -0,0 +1,354 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// Frame types of the encoded streams.
+// In SSE, data frames are sent without the event field, so that they are received by EventSource.onmessage,
+// other frames are sent with the event field set to the frame type, and heartbeats are sent as comments.
+// In NDJSON, each line is an object like {"type":"data","data":{...}}.
+const (
+	StreamFrameData      = "data"
+	StreamFrameError     = "error"
+	StreamFrameSourceEOF = "source_eof"
+	StreamFrameHeartbeat = "heartbeat"
+	StreamFrameDone      = "done"
+)
+
+// RemoteStreamError is the error received by the decoded StreamReader for an error frame.
+type RemoteStreamError struct {
+	Message string
+}
+
+func (e *RemoteStreamError) Error() string {
+	return e.Message
+}
+
+type streamEncodeOptions struct {
+	heartbeat    time.Duration
+	errorMessage func(error) string
+}
+
+// StreamEncodeOption is the option for EncodeSSE and EncodeNDJSON.
+type StreamEncodeOption func(o *streamEncodeOptions)
+
+// WithStreamHeartbeat writes a heartbeat frame whenever no frame has been written for the interval,
+// which keeps the connection alive through proxies while the model is thinking.
+// For the StreamReaders other than the ones created by Pipe, the encoding may end, e.g. by ctx, with a receive still pending,
+// then sr is closed once the pending receive returns, see StreamReader.RecvContext.
+func WithStreamHeartbeat(interval time.Duration) StreamEncodeOption {
+	return func(o *streamEncodeOptions) {
+		o.heartbeat = interval
+	}
+}
+
+// WithStreamErrorMessage customizes the message of the error frames, e.g. to hide internal details from clients.
+// err.Error() is used by default.
+func WithStreamErrorMessage(fn func(err error) string) StreamEncodeOption {
+	return func(o *streamEncodeOptions) {
+		o.errorMessage = fn
+	}
+}
+
+// EncodeSSE writes the stream to w as Server-Sent Events, until sr reaches io.EOF or ctx is done. sr is closed at the end.
+// Each chunk is written as a json data frame, errors of sr are written as error frames without ending the stream,
+// SourceEOF is written as a source_eof frame, and a done frame is written at the end.
+// w is flushed after each frame if it implements Flush() or Flush() error, e.g. http.ResponseWriter.
+// The response headers, e.g. Content-Type: text/event-stream, should be set by the caller.
+// e.g.
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		w.Header().Set("Content-Type", "text/event-stream")
+//		sr, err := chatModel.Stream(r.Context(), messages)
+//		...
+//		err = schema.EncodeSSE(r.Context(), w, sr, schema.WithStreamHeartbeat(15*time.Second))
+//	}
+func EncodeSSE[T any](ctx context.Context, w io.Writer, sr *StreamReader[T], opts ...StreamEncodeOption) error {
+	return encodeStream(ctx, w, sr, writeSSEFrame, opts...)
+}
+
+// EncodeNDJSON writes the stream to w as newline-delimited json, one frame per line, the same as EncodeSSE otherwise.
+// The frames look like:
+//
+//	{"type":"data","data":{"role":"assistant","content":"hello"}}
+//	{"type":"error","error":"something wrong"}
+//	{"type":"source_eof","source":"name"}
+//	{"type":"heartbeat"}
+//	{"type":"done"}
+func EncodeNDJSON[T any](ctx context.Context, w io.Writer, sr *StreamReader[T], opts ...StreamEncodeOption) error {
+	return encodeStream(ctx, w, sr, writeNDJSONFrame, opts...)
+}
+
+// DecodeSSE reads the Server-Sent Events written by EncodeSSE back into a StreamReader, e.g. from an http response body.
+// Error frames are received as *RemoteStreamError, source_eof frames as SourceEOF.
+// If r ends without a done frame, e.g. the connection is broken, io.ErrUnexpectedEOF is received before io.EOF.
+// r is closed at the end if it implements io.Closer.
+func DecodeSSE[T any](r io.Reader) *StreamReader[T] {
+	return decodeStream[T](r, readSSEFrame)
+}
+
+// DecodeNDJSON reads the newline-delimited json written by EncodeNDJSON back into a StreamReader, the same as DecodeSSE otherwise.
+func DecodeNDJSON[T any](r io.Reader) *StreamReader[T] {
+	return decodeStream[T](r, readNDJSONFrame)
+}
+
+type streamFrame struct {
+	Type   string          `json:"type"`
+	Data   json.RawMessage `json:"data,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Source string          `json:"source,omitempty"`
+}
+
+func encodeStream[T any](ctx context.Context, w io.Writer, sr *StreamReader[T],
+	write func(w io.Writer, frame *streamFrame) error, opts ...StreamEncodeOption) error {
+	// with heartbeats, the receive may be still pending when returning, which should finish before sr is closed
+	defer sr.closeAfterPending()
+
+	o := &streamEncodeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	writeFrame := func(frame *streamFrame) error {
+		if err := write(w, frame); err != nil {
+			return err
+		}
+		return flushWriter(w)
+	}
+
+	for {
+		recvCtx, cancel := ctx, context.CancelFunc(func() {})
+		if o.heartbeat > 0 {
+			recvCtx, cancel = context.WithTimeout(ctx, o.heartbeat)
+		}
+		chunk, err := sr.RecvContext(recvCtx)
+		cancel()
+
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		var frame *streamFrame
+		switch {
+		case err == io.EOF:
+			return writeFrame(&streamFrame{Type: StreamFrameDone})
+		case err != nil && recvCtx.Err() != nil && errors.Is(err, context.DeadlineExceeded):
+			frame = &streamFrame{Type: StreamFrameHeartbeat}
+		case err != nil:
+			if source, ok := GetSourceName(err); ok {
+				frame = &streamFrame{Type: StreamFrameSourceEOF, Source: source}
+			} else if o.errorMessage != nil {
+				frame = &streamFrame{Type: StreamFrameError, Error: o.errorMessage(err)}
+			} else {
+				frame = &streamFrame{Type: StreamFrameError, Error: err.Error()}
+			}
+		default:
+			data, mErr := sonic.Marshal(chunk)
+			if mErr != nil {
+				return fmt.Errorf("marshal stream chunk fail: %w", mErr)
+			}
+			frame = &streamFrame{Type: StreamFrameData, Data: data}
+		}
+
+		if err = writeFrame(frame); err != nil {
+			return err
+		}
+	}
+}
+
+func flushWriter(w io.Writer) error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		f.Flush()
+	}
+	return nil
+}
+
+func writeSSEFrame(w io.Writer, frame *streamFrame) error {
+	var err error
+	switch frame.Type {
+	case StreamFrameData:
+		_, err = fmt.Fprintf(w, "data: %s\n\n", frame.Data)
+	case StreamFrameHeartbeat:
+		_, err = io.WriteString(w, ": heartbeat\n\n")
+	default:
+		var data []byte
+		data, err = sonic.Marshal(frame)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", frame.Type, data)
+	}
+	return err
+}
+
+func writeNDJSONFrame(w io.Writer, frame *streamFrame) error {
+	data, err := sonic.Marshal(frame)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = w.Write(data)
+	return err
+}
+
+func decodeStream[T any](r io.Reader, read func(br *bufio.Reader) (*streamFrame, error)) *StreamReader[T] {
+	sr, sw := Pipe[T](0)
+
+	go func() {
+		defer func() {
+			sw.Close()
+			if c, ok := r.(io.Closer); ok {
+				_ = c.Close()
+			}
+		}()
+
+		var t T
+		br := bufio.NewReader(r)
+		for {
+			frame, err := read(br)
+			if err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				sw.Send(t, err)
+				return
+			}
+
+			var closed bool
+			switch frame.Type {
+			case StreamFrameDone:
+				return
+			case StreamFrameHeartbeat:
+				continue
+			case StreamFrameError:
+				closed = sw.Send(t, &RemoteStreamError{Message: frame.Error})
+			case StreamFrameSourceEOF:
+				closed = sw.Send(t, &SourceEOF{sourceName: frame.Source})
+			case StreamFrameData:
+				var chunk T
+				if err = sonic.Unmarshal(frame.Data, &chunk); err != nil {
+					closed = sw.Send(t, fmt.Errorf("unmarshal stream chunk fail: %w", err))
+				} else {
+					closed = sw.Send(chunk, nil)
+				}
+			default:
+				// unknown frames are ignored for compatibility
+			}
+			if closed {
+				return
+			}
+		}
+	}()
+
+	return sr
+}
+
+// readSSEFrame returns the next frame, io.EOF if r ends before a complete frame.
+func readSSEFrame(br *bufio.Reader) (*streamFrame, error) {
+	var event string
+	var data []string
+	hasData := false
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			if !hasData {
+				if event == "" {
+					// heartbeat comments or empty lines
+					continue
+				}
+				return &streamFrame{Type: event}, nil
+			}
+			return parseSSEFrame(event, strings.Join(data, "\n"))
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+			hasData = true
+		default:
+			// id, retry and unknown fields are ignored
+		}
+
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+	}
+}
+
+func parseSSEFrame(event, data string) (*streamFrame, error) {
+	if event == "" || event == "message" || event == StreamFrameData {
+		return &streamFrame{Type: StreamFrameData, Data: json.RawMessage(data)}, nil
+	}
+	frame := &streamFrame{}
+	if err := sonic.UnmarshalString(data, frame); err != nil {
+		return nil, fmt.Errorf("invalid sse %s frame %q: %w", event, data, err)
+	}
+	frame.Type = event
+	return frame, nil
+}
+
+func readNDJSONFrame(br *bufio.Reader) (*streamFrame, error) {
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			continue
+		}
+
+		frame := &streamFrame{}
+		if uErr := sonic.UnmarshalString(line, frame); uErr != nil {
+			return nil, fmt.Errorf("invalid ndjson frame %q: %w", line, uErr)
+		}
+		return frame, nil
+	}
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamCodec(t *testing.T) {
+	ctx := context.Background()
+	idx0, idx1 := 0, 1
+	chunks := []*Message{
+		{Role: Assistant, ReasoningContent: "thinking"},
+		{Role: Assistant, ToolCalls: []ToolCall{{Index: &idx0, ID: "call_1", Function: FunctionCall{Name: "search", Arguments: `{"q":`}}}},
+		{Role: Assistant, ToolCalls: []ToolCall{{Index: &idx0, Function: FunctionCall{Arguments: `"eino"}`}}, {Index: &idx1, ID: "call_2"}}},
+		{Role: Assistant, ResponseMeta: &ResponseMeta{FinishReason: "tool_calls", Usage: &TokenUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}}},
+	}
+
+	codecs := map[string]struct {
+		encode func(ctx context.Context, w io.Writer, sr *StreamReader[*Message], opts ...StreamEncodeOption) error
+		decode func(r io.Reader) *StreamReader[*Message]
+	}{
+		"sse":    {encode: EncodeSSE[*Message], decode: DecodeSSE[*Message]},
+		"ndjson": {encode: EncodeNDJSON[*Message], decode: DecodeNDJSON[*Message]},
+	}
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			sr, sw := Pipe[*Message](0)
+			go func() {
+				defer sw.Close()
+				sw.Send(chunks[0], nil)
+				sw.Send(chunks[1], nil)
+				sw.Send(nil, errors.New("rate limited"))
+				sw.Send(nil, &SourceEOF{sourceName: "model"})
+				time.Sleep(30 * time.Millisecond)
+				sw.Send(chunks[2], nil)
+				sw.Send(chunks[3], nil)
+			}()
+
+			buf := &bytes.Buffer{}
+			err := codec.encode(ctx, buf, sr, WithStreamHeartbeat(10*time.Millisecond))
+			assert.NoError(t, err)
+			if name == "sse" {
+				assert.Contains(t, buf.String(), ": heartbeat\n\n")
+			} else {
+				assert.Contains(t, buf.String(), `{"type":"heartbeat"}`)
+			}
+
+			decoded, errs := recvAllWithErrors(codec.decode(io.NopCloser(buf)))
+			assert.Equal(t, chunks, decoded)
+			assert.Len(t, errs, 2)
+			var remoteErr *RemoteStreamError
+			assert.True(t, errors.As(errs[0], &remoteErr))
+			assert.Equal(t, "rate limited", remoteErr.Message)
+			source, ok := GetSourceName(errs[1])
+			assert.True(t, ok)
+			assert.Equal(t, "model", source)
+
+			msg, err := ConcatMessages(decoded)
+			assert.NoError(t, err)
+			assert.Len(t, msg.ToolCalls, 2)
+			assert.Equal(t, `{"q":"eino"}`, msg.ToolCalls[0].Function.Arguments)
+			assert.Equal(t, "thinking", msg.ReasoningContent)
+			assert.Equal(t, 15, msg.ResponseMeta.Usage.TotalTokens)
+		})
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		decoded, errs := recvAllWithErrors(DecodeSSE[*Message](strings.NewReader("data: {\"content\":\"a\"}\n\ndata: {\"conte")))
+		assert.Equal(t, []*Message{{Content: "a"}}, decoded)
+		assert.Equal(t, []error{io.ErrUnexpectedEOF}, errs)
+
+		decoded, errs = recvAllWithErrors(DecodeNDJSON[*Message](strings.NewReader(`{"type":"data","data":{"content":"a"}}` + "\n")))
+		assert.Equal(t, []*Message{{Content: "a"}}, decoded)
+		assert.Equal(t, []error{io.ErrUnexpectedEOF}, errs)
+	})
+
+	t.Run("sse compatibility", func(t *testing.T) {
+		body := ": comment\r\nid: 1\r\nevent: message\r\ndata: {\"content\":\r\ndata: \"a\"}\r\n\r\nevent: done\r\ndata: {}\r\n\r\n"
+		decoded, errs := recvAllWithErrors(DecodeSSE[*Message](strings.NewReader(body)))
+		assert.Equal(t, []*Message{{Content: "a"}}, decoded)
+		assert.Empty(t, errs)
+	})
+
+	t.Run("close after pending receive", func(t *testing.T) {
+		sr1, sw1 := Pipe[*Message](0)
+		sr2, sw2 := Pipe[*Message](0)
+		defer sw2.Close()
+		merged := MergeStreamReaders([]*StreamReader[*Message]{sr1, sr2})
+
+		cancelCtx, cancel := context.WithCancel(ctx)
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			cancel()
+		}()
+		err := EncodeNDJSON(cancelCtx, &bytes.Buffer{}, merged, WithStreamHeartbeat(10*time.Millisecond))
+		assert.ErrorIs(t, err, context.Canceled)
+
+		// the pending receive gets the chunk, and then merged is closed
+		assert.False(t, sw1.Send(&Message{Content: "a"}, nil))
+		assert.True(t, sw1.Send(&Message{Content: "b"}, nil))
+	})
+
+	t.Run("error message", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		sr, sw := Pipe[*Message](1)
+		sw.Send(nil, errors.New("internal detail"))
+		sw.Close()
+		err := EncodeNDJSON(ctx, buf, sr, WithStreamErrorMessage(func(err error) string {
+			return "internal error"
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, "{\"type\":\"error\",\"error\":\"internal error\"}\n{\"type\":\"done\"}\n", buf.String())
+	})
+}
@@ -0,0 +1,576 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"context"
+	"io"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/internal/safe"
+)
+
+// Stream operators transform StreamReaders in a separate goroutine, and share the following behaviors:
+//   - errors from the upstream, including SourceEOF, are forwarded to the downstream in order and don't end the stream,
+//     errors returned by the user functions are forwarded in the same way.
+//   - when ctx is done, the downstream receives ctx.Err() and then io.EOF.
+//   - the upstream is always closed, when it reaches io.EOF, when ctx is done, when the downstream is closed,
+//     or when the operator finishes early(e.g. TakeStreamReader). If the upstream is blocked in Recv at that moment,
+//     it's closed immediately if it's created by Pipe(or converted from such one), otherwise once the pending Recv returns.
+//   - panics in the user functions are recovered and sent to the downstream as an error.
+//   - the downstream must be closed or received until io.EOF, the same as other StreamReaders.
+
+// FilterStreamReader returns a StreamReader only containing the chunks satisfying keep.
+// e.g.
+//
+//	sr := schema.FilterStreamReader(ctx, schema.StreamReaderFromArray([]int{1, 2, 3, 4}), func(i int) bool {
+//		return i%2 == 0
+//	})
+//	defer sr.Close() // receives 2, 4
+func FilterStreamReader[T any](ctx context.Context, sr *StreamReader[T], keep func(T) bool) *StreamReader[T] {
+	return runOperator(ctx, func(o *operator[T]) {
+		in := newUpstream(o, sr)
+		for {
+			chunk, err, ok := recvUpstream(o, in)
+			if !ok || err == io.EOF {
+				return
+			}
+			if err != nil {
+				if !o.send(chunk, err) {
+					return
+				}
+				continue
+			}
+			if keep(chunk) && !o.send(chunk, nil) {
+				return
+			}
+		}
+	})
+}
+
+// FlatMapStreamReader maps each chunk to zero or more chunks.
+// e.g.
+//
+//	sr := schema.FlatMapStreamReader(ctx, schema.StreamReaderFromArray([]string{"a b", "c"}), func(s string) ([]string, error) {
+//		return strings.Fields(s), nil
+//	})
+//	defer sr.Close() // receives "a", "b", "c"
+func FlatMapStreamReader[T, D any](ctx context.Context, sr *StreamReader[T], fn func(T) ([]D, error)) *StreamReader[D] {
+	return runOperator(ctx, func(o *operator[D]) {
+		in := newUpstream(o, sr)
+		for {
+			chunk, err, ok := recvUpstream(o, in)
+			if !ok || err == io.EOF {
+				return
+			}
+			var ds []D
+			if err == nil {
+				ds, err = fn(chunk)
+			}
+			if err != nil {
+				var d D
+				if !o.send(d, err) {
+					return
+				}
+				continue
+			}
+			for _, d := range ds {
+				if !o.send(d, nil) {
+					return
+				}
+			}
+		}
+	})
+}
+
+// BatchStreamReader groups the chunks into batches.
+// A batch is emitted when it has maxSize chunks, or maxWait has elapsed since its first chunk, whichever comes first.
+// maxSize <= 0 means no limit of size, and maxWait <= 0 means no limit of time. The last incomplete batch is emitted when the upstream ends.
+// Pending chunks are emitted as a batch before forwarding an upstream error, so that the order is kept.
+// e.g.
+//
+//	sr := schema.BatchStreamReader(ctx, tokenStream, 20, 100*time.Millisecond)
+//	defer sr.Close()
+func BatchStreamReader[T any](ctx context.Context, sr *StreamReader[T], maxSize int, maxWait time.Duration) *StreamReader[[]T] {
+	return runOperator(ctx, func(o *operator[[]T]) {
+		in := newUpstream(o, sr)
+
+		var batch []T
+		timer := newOperatorTimer()
+		defer timer.stop()
+		flush := func() bool {
+			timer.stop()
+			if len(batch) == 0 {
+				return true
+			}
+			b := batch
+			batch = nil
+			return o.send(b, nil)
+		}
+
+		for {
+			chunk, err, fired, ok := recvUpstreamWithTimer(o, in, timer.c)
+			if !ok {
+				return
+			}
+			if fired {
+				if !flush() {
+					return
+				}
+				continue
+			}
+			if err == io.EOF {
+				flush()
+				return
+			}
+			if err != nil {
+				if !flush() || !o.send(nil, err) {
+					return
+				}
+				continue
+			}
+
+			batch = append(batch, chunk)
+			if len(batch) == 1 && maxWait > 0 {
+				timer.reset(maxWait)
+			}
+			if maxSize > 0 && len(batch) >= maxSize {
+				if !flush() {
+					return
+				}
+			}
+		}
+	})
+}
+
+// DebounceStreamReader only emits a chunk after no newer chunk is received for the duration of wait,
+// i.e. the chunks received within wait are replaced by the latest one.
+// The pending chunk is emitted immediately when the upstream ends or returns an error.
+func DebounceStreamReader[T any](ctx context.Context, sr *StreamReader[T], wait time.Duration) *StreamReader[T] {
+	return runOperator(ctx, func(o *operator[T]) {
+		in := newUpstream(o, sr)
+
+		var pending T
+		hasPending := false
+		timer := newOperatorTimer()
+		defer timer.stop()
+		flush := func() bool {
+			timer.stop()
+			if !hasPending {
+				return true
+			}
+			hasPending = false
+			return o.send(pending, nil)
+		}
+
+		for {
+			chunk, err, fired, ok := recvUpstreamWithTimer(o, in, timer.c)
+			if !ok {
+				return
+			}
+			if fired {
+				if !flush() {
+					return
+				}
+				continue
+			}
+			if err == io.EOF {
+				flush()
+				return
+			}
+			if err != nil {
+				if !flush() || !o.send(chunk, err) {
+					return
+				}
+				continue
+			}
+
+			pending, hasPending = chunk, true
+			timer.reset(wait)
+		}
+	})
+}
+
+// ThrottleStreamReader emits at most one chunk in each interval, the chunks received within the interval after an emitted chunk are dropped.
+// Errors are never dropped.
+func ThrottleStreamReader[T any](ctx context.Context, sr *StreamReader[T], interval time.Duration) *StreamReader[T] {
+	return runOperator(ctx, func(o *operator[T]) {
+		in := newUpstream(o, sr)
+
+		var next time.Time
+		for {
+			chunk, err, ok := recvUpstream(o, in)
+			if !ok || err == io.EOF {
+				return
+			}
+			if err != nil {
+				if !o.send(chunk, err) {
+					return
+				}
+				continue
+			}
+
+			now := time.Now()
+			if now.Before(next) {
+				continue
+			}
+			next = now.Add(interval)
+			if !o.send(chunk, nil) {
+				return
+			}
+		}
+	})
+}
+
+// TakeStreamReader returns a StreamReader ending after the first n chunks, the upstream is closed after that.
+// Errors are forwarded and not counted.
+func TakeStreamReader[T any](ctx context.Context, sr *StreamReader[T], n int) *StreamReader[T] {
+	return runOperator(ctx, func(o *operator[T]) {
+		in := newUpstream(o, sr)
+
+		for taken := 0; taken < n; {
+			chunk, err, ok := recvUpstream(o, in)
+			if !ok || err == io.EOF {
+				return
+			}
+			if err == nil {
+				taken++
+			}
+			if !o.send(chunk, err) {
+				return
+			}
+		}
+	})
+}
+
+// SkipStreamReader returns a StreamReader without the first n chunks.
+// Errors are forwarded and not counted.
+func SkipStreamReader[T any](ctx context.Context, sr *StreamReader[T], n int) *StreamReader[T] {
+	return runOperator(ctx, func(o *operator[T]) {
+		in := newUpstream(o, sr)
+
+		skipped := 0
+		for {
+			chunk, err, ok := recvUpstream(o, in)
+			if !ok || err == io.EOF {
+				return
+			}
+			if err == nil && skipped < n {
+				skipped++
+				continue
+			}
+			if !o.send(chunk, err) {
+				return
+			}
+		}
+	})
+}
+
+// ScanStreamReader emits the running accumulation of the chunks, starting from init.
+// If fn returns an error, the error is forwarded and the accumulation stays unchanged.
+// e.g.
+//
+//	sr := schema.ScanStreamReader(ctx, schema.StreamReaderFromArray([]string{"a", "b", "c"}), "",
+//		func(acc string, s string) (string, error) {
+//			return acc + s, nil
+//		})
+//	defer sr.Close() // receives "a", "ab", "abc"
+func ScanStreamReader[T, A any](ctx context.Context, sr *StreamReader[T], init A, fn func(acc A, chunk T) (A, error)) *StreamReader[A] {
+	return runOperator(ctx, func(o *operator[A]) {
+		in := newUpstream(o, sr)
+
+		acc := init
+		for {
+			chunk, err, ok := recvUpstream(o, in)
+			if !ok || err == io.EOF {
+				return
+			}
+			var next A
+			if err == nil {
+				next, err = fn(acc, chunk)
+			}
+			if err != nil {
+				var a A
+				if !o.send(a, err) {
+					return
+				}
+				continue
+			}
+			acc = next
+			if !o.send(acc, nil) {
+				return
+			}
+		}
+	})
+}
+
+// ZipStreamReaders pairs the chunks of the two StreamReaders in order and combines each pair.
+// The result ends when either of the two ends, and the other is closed then.
+// Errors of both are forwarded.
+func ZipStreamReaders[A, B, D any](ctx context.Context, a *StreamReader[A], b *StreamReader[B], combine func(A, B) (D, error)) *StreamReader[D] {
+	return runOperator(ctx, func(o *operator[D]) {
+		inA := newUpstream(o, a)
+		inB := newUpstream(o, b)
+
+		var d D
+		for {
+			var ca A
+			var cb B
+			for gotA := false; !gotA; {
+				chunk, err, ok := recvUpstream(o, inA)
+				if !ok || err == io.EOF {
+					return
+				}
+				if err != nil {
+					if !o.send(d, err) {
+						return
+					}
+					continue
+				}
+				ca, gotA = chunk, true
+			}
+			for gotB := false; !gotB; {
+				chunk, err, ok := recvUpstream(o, inB)
+				if !ok || err == io.EOF {
+					return
+				}
+				if err != nil {
+					if !o.send(d, err) {
+						return
+					}
+					continue
+				}
+				cb, gotB = chunk, true
+			}
+
+			out, err := combine(ca, cb)
+			if !o.send(out, err) {
+				return
+			}
+		}
+	})
+}
+
+// TeeStreamReader passes every chunk and error to consume before forwarding it, e.g. for logging or auditing.
+// consume is called with io.EOF when the upstream ends.
+// consume is called in the operator goroutine sequentially, and blocks the stream while running.
+func TeeStreamReader[T any](ctx context.Context, sr *StreamReader[T], consume func(chunk T, err error)) *StreamReader[T] {
+	return runOperator(ctx, func(o *operator[T]) {
+		in := newUpstream(o, sr)
+
+		for {
+			chunk, err, ok := recvUpstream(o, in)
+			if !ok {
+				return
+			}
+			consume(chunk, err)
+			if err == io.EOF {
+				return
+			}
+			if !o.send(chunk, err) {
+				return
+			}
+		}
+	})
+}
+
+// operator is the downstream side of a stream operator.
+type operator[D any] struct {
+	ctx      context.Context
+	out      *stream[D]
+	inputs   []interface{ close() }
+	canceled bool
+}
+
+func runOperator[D any](ctx context.Context, fn func(o *operator[D])) *StreamReader[D] {
+	o := &operator[D]{
+		ctx: ctx,
+		out: newStream[D](0),
+	}
+
+	go func() {
+		defer func() {
+			for _, in := range o.inputs {
+				in.close()
+			}
+
+			var d D
+			if panicErr := recover(); panicErr != nil {
+				o.deliver(d, safe.NewPanicErr(panicErr, debug.Stack()))
+			} else if o.canceled {
+				o.deliver(d, o.ctx.Err())
+			}
+
+			o.out.closeSend()
+		}()
+
+		fn(o)
+	}()
+
+	return o.out.asReader()
+}
+
+// send returns false if ctx is done or the downstream is closed.
+func (o *operator[D]) send(chunk D, err error) bool {
+	select {
+	case o.out.items <- streamItem[D]{chunk: chunk, err: err}:
+		return true
+	case <-o.ctx.Done():
+		o.canceled = true
+		return false
+	case <-o.out.closed:
+		return false
+	}
+}
+
+// deliver sends the final item regardless of ctx.
+func (o *operator[D]) deliver(chunk D, err error) {
+	select {
+	case o.out.items <- streamItem[D]{chunk: chunk, err: err}:
+	case <-o.out.closed:
+	}
+}
+
+// upstream receives from a StreamReader in its own goroutine, so that operators can select on it together with ctx and timers.
+// The goroutine is the only one calling Recv and Close of the StreamReader.
+type upstream[T any] struct {
+	sr    *StreamReader[T]
+	items chan streamItem[T]
+	stop  chan struct{}
+
+	stopOnce  sync.Once
+	closeOnce sync.Once
+}
+
+func newUpstream[T, D any](o *operator[D], sr *StreamReader[T]) *upstream[T] {
+	u := &upstream[T]{
+		sr:    sr,
+		items: make(chan streamItem[T]),
+		stop:  make(chan struct{}),
+	}
+	o.inputs = append(o.inputs, u)
+
+	go func() {
+		defer func() {
+			if panicErr := recover(); panicErr != nil {
+				select {
+				case u.items <- streamItem[T]{err: safe.NewPanicErr(panicErr, debug.Stack())}:
+				case <-u.stop:
+				}
+			}
+			close(u.items)
+			u.closeReader()
+		}()
+
+		for {
+			select {
+			case <-u.stop:
+				return
+			default:
+			}
+
+			chunk, err := sr.Recv()
+			if err == io.EOF {
+				return
+			}
+
+			select {
+			case u.items <- streamItem[T]{chunk: chunk, err: err}:
+			case <-u.stop:
+				return
+			}
+		}
+	}()
+
+	return u
+}
+
+// close stops receiving, and closes the StreamReader at once if it's safe to close while the goroutine may be blocked in Recv.
+func (u *upstream[T]) close() {
+	u.stopOnce.Do(func() {
+		close(u.stop)
+	})
+	if u.sr.closableConcurrently() {
+		u.closeReader()
+	}
+}
+
+func (u *upstream[T]) closeReader() {
+	u.closeOnce.Do(u.sr.Close)
+}
+
+// closableConcurrently reports whether Close can be called while another goroutine is blocked in Recv.
+func (sr *StreamReader[T]) closableConcurrently() bool {
+	switch sr.typ {
+	case readerTypeStream, readerTypeArray:
+		return true
+	case readerTypeWithConvert:
+		c, ok := sr.srw.sr.(interface{ closableConcurrently() bool })
+		return ok && c.closableConcurrently()
+	default:
+		return false
+	}
+}
+
+// recvUpstream returns io.EOF when the upstream ends, ok is false if ctx is done or the downstream is closed.
+func recvUpstream[T, D any](o *operator[D], in *upstream[T]) (chunk T, err error, ok bool) {
+	chunk, err, _, ok = recvUpstreamWithTimer(o, in, nil)
+	return chunk, err, ok
+}
+
+func recvUpstreamWithTimer[T, D any](o *operator[D], in *upstream[T], timer <-chan time.Time) (chunk T, err error, fired bool, ok bool) {
+	select {
+	case item, open := <-in.items:
+		if !open {
+			return chunk, io.EOF, false, true
+		}
+		return item.chunk, item.err, false, true
+	case <-timer:
+		return chunk, nil, true, true
+	case <-o.ctx.Done():
+		o.canceled = true
+		return chunk, nil, false, false
+	case <-o.out.closed:
+		return chunk, nil, false, false
+	}
+}
+
+// operatorTimer is a resettable timer, whose channel is nil when it's stopped.
+type operatorTimer struct {
+	t *time.Timer
+	c <-chan time.Time
+}
+
+func newOperatorTimer() *operatorTimer {
+	return &operatorTimer{}
+}
+
+func (t *operatorTimer) reset(d time.Duration) {
+	t.stop()
+	t.t = time.NewTimer(d)
+	t.c = t.t.C
+}
+
+func (t *operatorTimer) stop() {
+	if t.t != nil {
+		t.t.Stop()
+		t.t = nil
+	}
+	t.c = nil
+}
@@ -0,0 +1,267 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recvAllWithErrors receives until io.EOF, and returns the chunks and the errors in order.
+func recvAllWithErrors[T any](sr *StreamReader[T]) (chunks []T, errs []error) {
+	for {
+		chunk, err := sr.Recv()
+		if err == io.EOF {
+			return chunks, errs
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		chunks = append(chunks, chunk)
+	}
+}
+
+// waitClosed waits until the writer detects that the reader has been closed.
+func waitClosed[T any](t *testing.T, sw *StreamWriter[T], chunk T) {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if sw.Send(chunk, nil) {
+			return
+		}
+	}
+	t.Fatal("upstream is not closed")
+}
+
+func TestStreamOperators(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("filter", func(t *testing.T) {
+		sr := FilterStreamReader(ctx, StreamReaderFromArray([]int{1, 2, 3, 4}), func(i int) bool {
+			return i%2 == 0
+		})
+		chunks, errs := recvAllWithErrors(sr)
+		assert.Equal(t, []int{2, 4}, chunks)
+		assert.Empty(t, errs)
+	})
+
+	t.Run("flat map", func(t *testing.T) {
+		sr := FlatMapStreamReader(ctx, StreamReaderFromArray([]string{"a b", "", "c", "err"}), func(s string) ([]string, error) {
+			if s == "err" {
+				return nil, errors.New("bad chunk")
+			}
+			return strings.Fields(s), nil
+		})
+		chunks, errs := recvAllWithErrors(sr)
+		assert.Equal(t, []string{"a", "b", "c"}, chunks)
+		assert.Equal(t, []error{errors.New("bad chunk")}, errs)
+	})
+
+	t.Run("batch by size", func(t *testing.T) {
+		sr := BatchStreamReader(ctx, StreamReaderFromArray([]int{1, 2, 3, 4, 5}), 2, 0)
+		chunks, _ := recvAllWithErrors(sr)
+		assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, chunks)
+	})
+
+	t.Run("batch by time and errors", func(t *testing.T) {
+		in, sw := Pipe[int](0)
+		sr := BatchStreamReader(ctx, in, 0, 20*time.Millisecond)
+		go func() {
+			defer sw.Close()
+			sw.Send(1, nil)
+			sw.Send(2, nil)
+			time.Sleep(60 * time.Millisecond)
+			sw.Send(3, nil)
+			sw.Send(0, &SourceEOF{sourceName: "s"})
+			sw.Send(4, nil)
+		}()
+		chunks, errs := recvAllWithErrors(sr)
+		assert.Equal(t, [][]int{{1, 2}, {3}, {4}}, chunks)
+		assert.Len(t, errs, 1)
+		name, ok := GetSourceName(errs[0])
+		assert.True(t, ok)
+		assert.Equal(t, "s", name)
+	})
+
+	t.Run("debounce", func(t *testing.T) {
+		in, sw := Pipe[int](0)
+		sr := DebounceStreamReader(ctx, in, 30*time.Millisecond)
+		go func() {
+			defer sw.Close()
+			sw.Send(1, nil)
+			sw.Send(2, nil)
+			time.Sleep(80 * time.Millisecond)
+			sw.Send(3, nil)
+			sw.Send(4, nil)
+		}()
+		chunks, _ := recvAllWithErrors(sr)
+		assert.Equal(t, []int{2, 4}, chunks)
+	})
+
+	t.Run("throttle", func(t *testing.T) {
+		in, sw := Pipe[int](0)
+		sr := ThrottleStreamReader(ctx, in, 40*time.Millisecond)
+		go func() {
+			defer sw.Close()
+			sw.Send(1, nil)
+			sw.Send(2, nil)
+			sw.Send(0, errors.New("not dropped"))
+			time.Sleep(80 * time.Millisecond)
+			sw.Send(3, nil)
+		}()
+		chunks, errs := recvAllWithErrors(sr)
+		assert.Equal(t, []int{1, 3}, chunks)
+		assert.Len(t, errs, 1)
+	})
+
+	t.Run("take closes upstream", func(t *testing.T) {
+		in, sw := Pipe[int](0)
+		sr := TakeStreamReader(ctx, in, 2)
+		go func() {
+			for i := 0; ; i++ {
+				if sw.Send(i, nil) {
+					return
+				}
+			}
+		}()
+		chunks, _ := recvAllWithErrors(sr)
+		assert.Equal(t, []int{0, 1}, chunks)
+		waitClosed(t, sw, 0)
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		sr := SkipStreamReader(ctx, StreamReaderFromArray([]int{1, 2, 3}), 2)
+		chunks, _ := recvAllWithErrors(sr)
+		assert.Equal(t, []int{3}, chunks)
+	})
+
+	t.Run("scan", func(t *testing.T) {
+		sr := ScanStreamReader(ctx, StreamReaderFromArray([]string{"a", "b", "c"}), "",
+			func(acc string, s string) (string, error) {
+				return acc + s, nil
+			})
+		chunks, _ := recvAllWithErrors(sr)
+		assert.Equal(t, []string{"a", "ab", "abc"}, chunks)
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		b, sw := Pipe[string](0)
+		go func() {
+			for _, s := range []string{"a", "b", "c"} {
+				if sw.Send(s, nil) {
+					return
+				}
+			}
+			sw.Close()
+		}()
+		sr := ZipStreamReaders(ctx, StreamReaderFromArray([]int{1, 2}), b, func(i int, s string) (string, error) {
+			return fmt.Sprintf("%d%s", i, s), nil
+		})
+		chunks, _ := recvAllWithErrors(sr)
+		assert.Equal(t, []string{"1a", "2b"}, chunks)
+		waitClosed(t, sw, "")
+	})
+
+	t.Run("tee", func(t *testing.T) {
+		var side []int
+		var sideEnd bool
+		sr := TeeStreamReader(ctx, StreamReaderFromArray([]int{1, 2}), func(chunk int, err error) {
+			if err == io.EOF {
+				sideEnd = true
+				return
+			}
+			side = append(side, chunk)
+		})
+		chunks, _ := recvAllWithErrors(sr)
+		assert.Equal(t, []int{1, 2}, chunks)
+		assert.Equal(t, []int{1, 2}, side)
+		assert.True(t, sideEnd)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		cctx, cancel := context.WithCancel(ctx)
+		in, sw := Pipe[int](0)
+		sr := FilterStreamReader(cctx, in, func(int) bool { return true })
+		go func() {
+			for i := 0; ; i++ {
+				if sw.Send(i, nil) {
+					return
+				}
+			}
+		}()
+		_, err := sr.Recv()
+		assert.NoError(t, err)
+		cancel()
+		for {
+			_, err = sr.Recv()
+			if errors.Is(err, context.Canceled) {
+				break
+			}
+			assert.NoError(t, err)
+		}
+		_, err = sr.Recv()
+		assert.Equal(t, io.EOF, err)
+		waitClosed(t, sw, 0)
+	})
+
+	t.Run("downstream closed", func(t *testing.T) {
+		in, sw := Pipe[int](0)
+		sr := ScanStreamReader(ctx, in, 0, func(acc int, i int) (int, error) {
+			return acc + i, nil
+		})
+		go func() {
+			for i := 0; ; i++ {
+				if sw.Send(i, nil) {
+					return
+				}
+			}
+		}()
+		_, err := sr.Recv()
+		assert.NoError(t, err)
+		sr.Close()
+		waitClosed(t, sw, 0)
+	})
+
+	t.Run("downstream closed while upstream blocked", func(t *testing.T) {
+		in, sw := Pipe[int](0)
+		defer sw.Close()
+		sr := FilterStreamReader(ctx, in, func(int) bool { return true })
+		// nothing is sent, so the upstream goroutine stays blocked in Recv.
+		sr.Close()
+		select {
+		case <-in.st.closed:
+		case <-time.After(time.Second):
+			t.Fatal("upstream is not closed")
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		sr := FilterStreamReader(ctx, StreamReaderFromArray([]int{1}), func(int) bool {
+			panic("boom")
+		})
+		_, errs := recvAllWithErrors(sr)
+		assert.Len(t, errs, 1)
+		assert.ErrorContains(t, errs[0], "boom")
+	})
+}
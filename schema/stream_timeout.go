@@ -0,0 +1,213 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"time"
+
+	"github.com/cloudwego/eino/internal/safe"
+)
+
+// ErrStreamTimeout is the error wrapped by StreamTimeoutError, use errors.Is(err, schema.ErrStreamTimeout) to check any stream timeout.
+var ErrStreamTimeout = errors.New("stream timeout")
+
+// StreamTimeoutError is returned by the StreamReader created with StreamReaderWithTimeout when it times out.
+type StreamTimeoutError struct {
+	// Idle is true if no chunk is received within the idle timeout, otherwise the total timeout is reached.
+	Idle bool
+	// Timeout is the idle timeout or the total timeout that has been reached.
+	Timeout time.Duration
+}
+
+func (e *StreamTimeoutError) Error() string {
+	if e.Idle {
+		return fmt.Sprintf("stream timeout: no chunk received in %v", e.Timeout)
+	}
+	return fmt.Sprintf("stream timeout: not finished in %v", e.Timeout)
+}
+
+func (e *StreamTimeoutError) Unwrap() error {
+	return ErrStreamTimeout
+}
+
+// RecvContext receives a value from the stream like Recv, but returns ctx.Err() once ctx is done while waiting.
+// The StreamReader is still usable after ctx is done: the value being received then is returned by the next Recv or RecvContext.
+// e.g.
+//
+//	ctx, cancel := context.WithTimeout(ctx, time.Second)
+//	defer cancel()
+//	chunk, err := sr.RecvContext(ctx)
+//	if errors.Is(err, context.DeadlineExceeded) {
+//		// the producer has not sent anything in 1 second
+//	}
+//
+// Notice: for StreamReaders other than the ones created by Pipe or StreamReaderFromArray, the receiving is done in a new goroutine,
+// which stays blocked until the value arrives, so don't Copy or Merge the StreamReader after RecvContext returns ctx.Err().
+func (sr *StreamReader[T]) RecvContext(ctx context.Context) (T, error) {
+	var t T
+	if err := ctx.Err(); err != nil {
+		return t, err
+	}
+	if ctx.Done() == nil || sr.typ == readerTypeArray {
+		return sr.Recv()
+	}
+
+	if sr.typ == readerTypeStream && sr.pending == nil {
+		select {
+		case item, ok := <-sr.st.items:
+			if !ok {
+				return t, io.EOF
+			}
+			return item.chunk, item.err
+		case <-ctx.Done():
+			return t, ctx.Err()
+		}
+	}
+
+	if sr.pending == nil {
+		pending := make(chan streamItem[T], 1)
+		sr.pending = pending
+		typ := sr.typ
+		go func() {
+			var item streamItem[T]
+			defer func() {
+				if panicErr := recover(); panicErr != nil {
+					item = streamItem[T]{err: safe.NewPanicErr(panicErr, debug.Stack())}
+				}
+				pending <- item
+			}()
+			item.chunk, item.err = sr.recvByType(typ)
+		}()
+	}
+
+	select {
+	case item := <-sr.pending:
+		sr.pending = nil
+		return item.chunk, item.err
+	case <-ctx.Done():
+		return t, ctx.Err()
+	}
+}
+
+// closeAfterPending closes sr. If a receive started by RecvContext is still pending in another goroutine,
+// and sr can't be closed concurrently with it, sr is closed in a new goroutine once the receive returns.
+func (sr *StreamReader[T]) closeAfterPending() {
+	if sr.pending == nil || sr.closableConcurrently() {
+		sr.Close()
+		return
+	}
+	go func() {
+		_, _ = sr.recvPending()
+		sr.Close()
+	}()
+}
+
+func (sr *StreamReader[T]) recvPending() (T, error) {
+	item := <-sr.pending
+	sr.pending = nil
+	return item.chunk, item.err
+}
+
+func (sr *StreamReader[T]) recvByType(typ readerType) (T, error) {
+	switch typ {
+	case readerTypeStream:
+		return sr.st.recv()
+	case readerTypeArray:
+		return sr.ar.recv()
+	case readerTypeMultiStream:
+		return sr.msr.recv()
+	case readerTypeWithConvert:
+		return sr.srw.recv()
+	case readerTypeChild:
+		return sr.csr.recv()
+	default:
+		panic("impossible")
+	}
+}
+
+// StreamReaderWithTimeout returns a StreamReader that fails with a *StreamTimeoutError
+// if no chunk is received from sr within idleTimeout, or sr doesn't reach io.EOF within totalTimeout since the wrapping.
+// A zero or negative timeout means no limit. Errors received from sr also count as activity.
+// After timeout, the returned StreamReader receives io.EOF, and sr is closed immediately if it's created by Pipe,
+// so that the producer is notified by StreamWriter.Send returning closed.
+// It's recommended to wrap streams from remote providers, since a hung provider blocks Recv forever.
+// e.g.
+//
+//	sr = schema.StreamReaderWithTimeout(ctx, sr, 30*time.Second, 5*time.Minute)
+//	defer sr.Close()
+//	for {
+//		chunk, err := sr.Recv()
+//		if errors.Is(err, schema.ErrStreamTimeout) {
+//			// the provider hangs
+//		}
+//		...
+//	}
+func StreamReaderWithTimeout[T any](ctx context.Context, sr *StreamReader[T], idleTimeout, totalTimeout time.Duration) *StreamReader[T] {
+	start := time.Now()
+	return runOperator(ctx, func(o *operator[T]) {
+		in := newUpstream(o, sr)
+
+		timer := newOperatorTimer()
+		defer timer.stop()
+
+		var idleDeadline, totalDeadline time.Time
+		if totalTimeout > 0 {
+			totalDeadline = start.Add(totalTimeout)
+		}
+		resetTimer := func() {
+			if idleTimeout > 0 {
+				idleDeadline = time.Now().Add(idleTimeout)
+			}
+			next := idleDeadline
+			if next.IsZero() || (!totalDeadline.IsZero() && totalDeadline.Before(next)) {
+				next = totalDeadline
+			}
+			if !next.IsZero() {
+				timer.reset(time.Until(next))
+			}
+		}
+		resetTimer()
+
+		for {
+			chunk, err, fired, ok := recvUpstreamWithTimer(o, in, timer.c)
+			if !ok {
+				return
+			}
+			if fired {
+				timeoutErr := &StreamTimeoutError{Idle: true, Timeout: idleTimeout}
+				if !totalDeadline.IsZero() && !time.Now().Before(totalDeadline) {
+					timeoutErr = &StreamTimeoutError{Timeout: totalTimeout}
+				}
+				in.close()
+				o.send(chunk, timeoutErr)
+				return
+			}
+			if err == io.EOF {
+				return
+			}
+			if !o.send(chunk, err) {
+				return
+			}
+			resetTimer()
+		}
+	})
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecvContext(t *testing.T) {
+	t.Run("pipe", func(t *testing.T) {
+		sr, sw := Pipe[int](0)
+		defer sr.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := sr.RecvContext(ctx)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		go func() {
+			sw.Send(1, nil)
+			sw.Close()
+		}()
+		chunk, err := sr.RecvContext(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, chunk)
+		_, err = sr.RecvContext(context.Background())
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("convert", func(t *testing.T) {
+		in, sw := Pipe[int](0)
+		sr := StreamReaderWithConvert(in, func(i int) (int, error) {
+			return i * 10, nil
+		})
+		defer sr.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := sr.RecvContext(ctx)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		go func() {
+			sw.Send(1, nil)
+			sw.Send(2, nil)
+			sw.Close()
+		}()
+		// the pending value is kept for the next receiving
+		chunk, err := sr.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, 10, chunk)
+		chunk, err = sr.RecvContext(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 20, chunk)
+		_, err = sr.RecvContext(ctx)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
+
+func TestStreamReaderWithTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("idle", func(t *testing.T) {
+		in, sw := Pipe[int](0)
+		sr := StreamReaderWithTimeout(ctx, in, 30*time.Millisecond, 0)
+		go func() {
+			sw.Send(1, nil)
+			time.Sleep(10 * time.Millisecond)
+			sw.Send(2, nil)
+			// hang
+		}()
+
+		chunks, errs := recvAllWithErrors(sr)
+		assert.Equal(t, []int{1, 2}, chunks)
+		assert.Len(t, errs, 1)
+		assert.True(t, errors.Is(errs[0], ErrStreamTimeout))
+		var timeoutErr *StreamTimeoutError
+		assert.True(t, errors.As(errs[0], &timeoutErr))
+		assert.True(t, timeoutErr.Idle)
+
+		// the hung producer is notified
+		assert.True(t, sw.Send(3, nil))
+	})
+
+	t.Run("total", func(t *testing.T) {
+		in, sw := Pipe[int](0)
+		sr := StreamReaderWithTimeout(ctx, in, 30*time.Millisecond, 50*time.Millisecond)
+		go func() {
+			for i := 0; ; i++ {
+				if sw.Send(i, nil) {
+					return
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+		}()
+
+		chunks, errs := recvAllWithErrors(sr)
+		assert.NotEmpty(t, chunks)
+		assert.Len(t, errs, 1)
+		var timeoutErr *StreamTimeoutError
+		assert.True(t, errors.As(errs[0], &timeoutErr))
+		assert.False(t, timeoutErr.Idle)
+		assert.Equal(t, 50*time.Millisecond, timeoutErr.Timeout)
+	})
+
+	t.Run("no timeout", func(t *testing.T) {
+		sr := StreamReaderWithTimeout(ctx, StreamReaderFromArray([]int{1, 2}), time.Second, time.Second)
+		chunks, errs := recvAllWithErrors(sr)
+		assert.Equal(t, []int{1, 2}, chunks)
+		assert.Empty(t, errs)
+	})
+}